@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// auditEntry запись журнала изменяющих действий администраторов
+type auditEntry struct {
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Cluster string    `json:"cluster"`
+	Action  string    `json:"action"`
+	Target  string    `json:"target"`
+	Result  string    `json:"result"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// auditLogger пишет журнал в stdout и, при заданном пути, дописывает JSON-строки в файл
+type auditLogger struct {
+	mu   sync.Mutex
+	path string
+}
+
+// auditLog журнал действий, задаётся в main
+var auditLog = newAuditLog("")
+
+func newAuditLog(path string) *auditLogger {
+	return &auditLogger{path: path}
+}
+
+// Record записывает действие пользователя над объектом кластера
+func (a *auditLogger) Record(user, cluster, action, target string, err error) {
+	e := auditEntry{Time: time.Now().UTC(), User: user, Cluster: cluster, Action: action, Target: target, Result: "ok"}
+	if err != nil {
+		e.Result = "error"
+		e.Error = err.Error()
+	}
+	log.Printf("[AUDIT] %s [%s] %s %s: %s", e.User, e.Cluster, e.Action, e.Target, e.Result)
+	if a.path == "" {
+		return
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("⚠️ Ошибка сериализации записи аудита: %v", err)
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Printf("⚠️ Не удалось открыть журнал аудита %s: %v", a.path, err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		log.Printf("⚠️ Не удалось записать журнал аудита %s: %v", a.path, err)
+	}
+}
+
+// userLabel имя пользователя Telegram для журнала: @username или id
+func userLabel(u *tgbotapi.User) string {
+	if u == nil {
+		return "-"
+	}
+	if u.UserName != "" {
+		return "@" + u.UserName
+	}
+	return strconv.FormatInt(u.ID, 10)
+}
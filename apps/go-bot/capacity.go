@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// capacityWarnPercent загрузка узла, после которой он помечается в отчёте
+const capacityWarnPercent = 90
+
+// resourceAmounts объёмы ресурсов узла или pod-а
+type resourceAmounts struct {
+	CPU       int64 // milliCPU
+	Memory    int64 // байты
+	Pods      int64
+	Ephemeral int64 // байты ephemeral-storage
+}
+
+func (a *resourceAmounts) add(b resourceAmounts) {
+	a.CPU += b.CPU
+	a.Memory += b.Memory
+	a.Pods += b.Pods
+	a.Ephemeral += b.Ephemeral
+}
+
+// nodeCapacity allocatable, requests, limits и фактическое потребление узла
+type nodeCapacity struct {
+	Name          string
+	Ready         bool
+	Unschedulable bool
+	Allocatable   resourceAmounts
+	Requested     resourceAmounts
+	Limited       resourceAmounts
+	Used          resourceAmounts // только CPU и память, если есть metrics-server
+	HasUsage      bool
+}
+
+// capacityFit оценка, сколько ещё реплик deployment-а поместится в кластер
+type capacityFit struct {
+	Namespace  string
+	Deployment string
+	PerReplica resourceAmounts
+	Nodes      []nodeFit
+	Total      int64
+}
+
+// nodeFit сколько реплик поместится на узел или почему узел не подходит
+type nodeFit struct {
+	Name    string
+	Fit     int64
+	Skipped string
+}
+
+// handleCapacity показывает /capacity и, если указан deployment, оценку свободного места под него
+func handleCapacity(bot *tgbotapi.BotAPI, cluster *Cluster, ctx context.Context, chatID int64, args string) {
+	lang := langFor(chatID)
+	parts := strings.Fields(args)
+	if len(parts) != 0 && len(parts) != 2 {
+		sendText(bot, chatID, T(lang, "usage.capacity"))
+		return
+	}
+
+	nodes, err := cluster.Typed.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	pods, err := cluster.Typed.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	nodeMetrics, err := getNodeMetrics(ctx, cluster)
+	if err != nil {
+		log.Printf("⚠️ [%s] Metrics server не доступен: %v", cluster.Name, err)
+	}
+
+	capacity := collectNodeCapacity(nodes.Items, pods.Items, nodeMetrics)
+	if len(parts) == 0 {
+		sendLongRich(bot, chatID, "capacity", renderCapacity(lang, clusterLabel(cluster.Name), capacity))
+		return
+	}
+
+	dep, err := cluster.Typed.AppsV1().Deployments(parts[0]).Get(ctx, parts[1], metav1.GetOptions{})
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	fit := estimateFit(dep.Spec.Template.Spec, nodes.Items, capacity)
+	fit.Namespace, fit.Deployment = dep.Namespace, dep.Name
+	sendLongRich(bot, chatID, "capacity-"+dep.Name, renderCapacityFit(lang, fit))
+}
+
+// collectNodeCapacity суммирует requests и limits незавершённых pod-ов по узлам
+func collectNodeCapacity(nodes []corev1.Node, pods []corev1.Pod, metrics map[string]struct{ CPU, Memory int64 }) []nodeCapacity {
+	byNode := make(map[string]*nodeCapacity, len(nodes))
+	result := make([]nodeCapacity, len(nodes))
+	for i, node := range nodes {
+		ready, _ := getNodeStatus(node)
+		alloc := node.Status.Allocatable
+		result[i] = nodeCapacity{
+			Name:          node.Name,
+			Ready:         ready,
+			Unschedulable: node.Spec.Unschedulable,
+			Allocatable: resourceAmounts{
+				CPU:       alloc.Cpu().MilliValue(),
+				Memory:    alloc.Memory().Value(),
+				Pods:      alloc.Pods().Value(),
+				Ephemeral: alloc.StorageEphemeral().Value(),
+			},
+		}
+		if m, ok := metrics[node.Name]; ok {
+			result[i].HasUsage = true
+			result[i].Used = resourceAmounts{CPU: m.CPU, Memory: m.Memory}
+		}
+		byNode[node.Name] = &result[i]
+	}
+
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		n, ok := byNode[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+		req, lim := podResources(pod.Spec)
+		n.Requested.add(req)
+		n.Limited.add(lim)
+		n.Requested.Pods++
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// podResources эффективные requests и limits pod-а так, как их считает планировщик:
+// сумма контейнеров, но не меньше самого большого init-контейнера, плюс overhead
+func podResources(spec corev1.PodSpec) (resourceAmounts, resourceAmounts) {
+	var req, lim resourceAmounts
+	for _, c := range spec.Containers {
+		req.add(containerAmounts(c.Resources.Requests))
+		lim.add(containerAmounts(c.Resources.Limits))
+	}
+	for _, c := range spec.InitContainers {
+		req = maxAmounts(req, containerAmounts(c.Resources.Requests))
+		lim = maxAmounts(lim, containerAmounts(c.Resources.Limits))
+	}
+	if spec.Overhead != nil {
+		req.add(containerAmounts(spec.Overhead))
+		lim.add(containerAmounts(spec.Overhead))
+	}
+	return req, lim
+}
+
+func containerAmounts(rl corev1.ResourceList) resourceAmounts {
+	return resourceAmounts{
+		CPU:       rl.Cpu().MilliValue(),
+		Memory:    rl.Memory().Value(),
+		Ephemeral: rl.StorageEphemeral().Value(),
+	}
+}
+
+func maxAmounts(a, b resourceAmounts) resourceAmounts {
+	return resourceAmounts{
+		CPU:       max(a.CPU, b.CPU),
+		Memory:    max(a.Memory, b.Memory),
+		Pods:      max(a.Pods, b.Pods),
+		Ephemeral: max(a.Ephemeral, b.Ephemeral),
+	}
+}
+
+// overcommitFlags отметки узла: limits больше allocatable и высокая фактическая загрузка
+func overcommitFlags(n nodeCapacity) string {
+	var flags []string
+	if n.Allocatable.CPU > 0 && n.Limited.CPU > n.Allocatable.CPU {
+		flags = append(flags, "CPU-LIM>ALLOC")
+	}
+	if n.Allocatable.Memory > 0 && n.Limited.Memory > n.Allocatable.Memory {
+		flags = append(flags, "MEM-LIM>ALLOC")
+	}
+	if n.HasUsage && n.Allocatable.CPU > 0 && n.Used.CPU*100 >= n.Allocatable.CPU*capacityWarnPercent {
+		flags = append(flags, "CPU-HOT")
+	}
+	if n.HasUsage && n.Allocatable.Memory > 0 && n.Used.Memory*100 >= n.Allocatable.Memory*capacityWarnPercent {
+		flags = append(flags, "MEM-HOT")
+	}
+	if n.Allocatable.Pods > 0 && n.Requested.Pods*100 >= n.Allocatable.Pods*capacityWarnPercent {
+		flags = append(flags, "PODS")
+	}
+	return strings.Join(flags, ",")
+}
+
+// estimateFit считает, сколько реплик с шаблоном spec поместится на каждый узел по requests
+func estimateFit(spec corev1.PodSpec, nodes []corev1.Node, capacity []nodeCapacity) capacityFit {
+	req, _ := podResources(spec)
+	req.Pods = 1
+	fit := capacityFit{PerReplica: req}
+
+	byName := make(map[string]nodeCapacity, len(capacity))
+	for _, n := range capacity {
+		byName[n.Name] = n
+	}
+	for _, node := range nodes {
+		n := byName[node.Name]
+		if reason := nodeUnfitReason(spec, node, n); reason != "" {
+			fit.Nodes = append(fit.Nodes, nodeFit{Name: node.Name, Skipped: reason})
+			continue
+		}
+		count := freeSlots(n.Allocatable.Pods, n.Requested.Pods, req.Pods)
+		count = min(count, freeSlots(n.Allocatable.CPU, n.Requested.CPU, req.CPU))
+		count = min(count, freeSlots(n.Allocatable.Memory, n.Requested.Memory, req.Memory))
+		count = min(count, freeSlots(n.Allocatable.Ephemeral, n.Requested.Ephemeral, req.Ephemeral))
+		fit.Nodes = append(fit.Nodes, nodeFit{Name: node.Name, Fit: count})
+		fit.Total += count
+	}
+	sort.Slice(fit.Nodes, func(i, j int) bool { return fit.Nodes[i].Name < fit.Nodes[j].Name })
+	return fit
+}
+
+// freeSlots сколько раз need помещается в свободный остаток; нулевой need не ограничивает
+func freeSlots(alloc, requested, need int64) int64 {
+	if need <= 0 {
+		return 1 << 62
+	}
+	free := alloc - requested
+	if free <= 0 {
+		return 0
+	}
+	return free / need
+}
+
+// nodeUnfitReason причина, по которой планировщик не поставит pod на узел
+func nodeUnfitReason(spec corev1.PodSpec, node corev1.Node, n nodeCapacity) string {
+	if !n.Ready {
+		return "NotReady"
+	}
+	if node.Spec.Unschedulable {
+		return "cordoned"
+	}
+	if len(spec.NodeSelector) > 0 && !labels.SelectorFromSet(spec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return "nodeSelector"
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for _, t := range spec.Tolerations {
+			if t.ToleratesTaint(&taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return "taint " + taint.Key
+		}
+	}
+	return ""
+}
+
+// renderCapacity формирует отчёт /capacity по узлам и по кластеру
+func renderCapacity(lang Lang, cluster string, nodes []nodeCapacity) *Rich {
+	r := NewRich()
+	r.Text("🧮 " + cluster).Bold(T(lang, "capacity.title")).Line()
+	if len(nodes) == 0 {
+		return r.Text(T(lang, "capacity.no_nodes"))
+	}
+
+	var total nodeCapacity
+	total.Name = "TOTAL"
+	total.HasUsage = true
+	for _, n := range nodes {
+		total.Allocatable.add(n.Allocatable)
+		total.Requested.add(n.Requested)
+		total.Limited.add(n.Limited)
+		total.Used.add(n.Used)
+		total.HasUsage = total.HasUsage && n.HasUsage
+	}
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "NODE\tCPU alloc/req/lim/use\tMEM alloc/req/lim/use\tPODS\tEPH alloc/req\tFLAGS")
+	for _, n := range append(nodes, total) {
+		name := n.Name
+		if n.Unschedulable {
+			name += "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d/%d\t%s/%s\t%s\n", name,
+			capacityColumn(n.Allocatable.CPU, n.Requested.CPU, n.Limited.CPU, n.Used.CPU, n.HasUsage, shortCPU),
+			capacityColumn(n.Allocatable.Memory, n.Requested.Memory, n.Limited.Memory, n.Used.Memory, n.HasUsage, shortMem),
+			n.Requested.Pods, n.Allocatable.Pods,
+			shortMem(n.Allocatable.Ephemeral), shortMem(n.Requested.Ephemeral),
+			orDash(overcommitFlags(n)))
+	}
+	w.Flush()
+	r.Pre(strings.TrimRight(sb.String(), "\n")).Line()
+
+	r.Text(T(lang, "capacity.requested",
+		int(calculatePercent(total.Requested.CPU, total.Allocatable.CPU)),
+		int(calculatePercent(total.Requested.Memory, total.Allocatable.Memory)))).Line()
+	r.Text(T(lang, "capacity.limited",
+		int(calculatePercent(total.Limited.CPU, total.Allocatable.CPU)),
+		int(calculatePercent(total.Limited.Memory, total.Allocatable.Memory)))).Line()
+	if total.HasUsage {
+		r.Text(T(lang, "capacity.used",
+			int(calculatePercent(total.Used.CPU, total.Allocatable.CPU)),
+			int(calculatePercent(total.Used.Memory, total.Allocatable.Memory)))).Line()
+	} else {
+		r.Text(T(lang, "capacity.no_metrics")).Line()
+	}
+	r.Italic(T(lang, "capacity.legend", capacityWarnPercent))
+	return r
+}
+
+// capacityColumn ячейка alloc/req/lim/use с процентом requests от allocatable
+func capacityColumn(alloc, req, lim, used int64, hasUsage bool, format func(int64) string) string {
+	use := "-"
+	if hasUsage {
+		use = format(used)
+	}
+	return fmt.Sprintf("%s/%s/%s/%s (%d%%)", format(alloc), format(req), format(lim), use, int(calculatePercent(req, alloc)))
+}
+
+// renderCapacityFit формирует оценку свободного места под реплики deployment-а
+func renderCapacityFit(lang Lang, fit capacityFit) *Rich {
+	r := NewRich()
+	r.Text("🧮 ").Bold(T(lang, "capacity.fit.title", fit.Namespace+"/"+fit.Deployment)).Line()
+	r.Text(T(lang, "capacity.fit.per_replica",
+		shortCPU(fit.PerReplica.CPU), shortMem(fit.PerReplica.Memory), shortMem(fit.PerReplica.Ephemeral))).Line()
+	if fit.PerReplica.CPU == 0 && fit.PerReplica.Memory == 0 {
+		r.Text(T(lang, "capacity.fit.no_requests")).Line()
+	}
+	r.Line()
+	for _, n := range fit.Nodes {
+		if n.Skipped != "" {
+			r.Text("▫️ ").Code(n.Name).Text(" — " + T(lang, "capacity.fit.skipped", n.Skipped)).Line()
+			continue
+		}
+		r.Text("▪️ ").Code(n.Name).Textf(" — %d", n.Fit).Line()
+	}
+	r.Line().Bold(T(lang, "capacity.fit.total", fit.Total))
+	return r
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	certManagerAPI = "cert-manager.io/v1"
+	// certSourceManager и certSourceSecret откуда взят сертификат
+	certSourceManager = "cert-manager"
+	certSourceSecret  = "secret"
+)
+
+var certificateGVR = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+
+// certInfo сертификат cert-manager или TLS-секрет, которым cert-manager не управляет
+type certInfo struct {
+	Namespace string
+	Name      string
+	Source    string
+	Issuer    string
+	NotAfter  time.Time // нулевое, если сертификат ещё не выпущен
+	Ready     bool      // false только при явном Ready=False; у секретов всегда true
+	Reason    string    // сообщение условия Ready, если сертификат не готов
+}
+
+func (c certInfo) key() string { return c.Source + "/" + c.Namespace + "/" + c.Name }
+
+// daysLeft целые сутки до истечения, отрицательные — истёк
+func (c certInfo) daysLeft(now time.Time) int {
+	return int(math.Floor(c.NotAfter.Sub(now).Hours() / 24))
+}
+
+// listCertificates собирает Certificates cert-manager и, если включено, TLS-секреты без владельца-Certificate
+func listCertificates(ctx context.Context, cluster *Cluster, scanSecrets bool) ([]certInfo, error) {
+	var certs []certInfo
+	managed := make(map[string]bool) // ns/secretName, выпущенные cert-manager
+
+	if cluster.HasAPI(certManagerAPI) {
+		list, err := cluster.Dynamic.Resource(certificateGVR).Namespace("").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			certs = append(certs, parseCertificate(item))
+			if secret, _, _ := unstructured.NestedString(item.Object, "spec", "secretName"); secret != "" {
+				managed[item.GetNamespace()+"/"+secret] = true
+			}
+		}
+	}
+
+	if scanSecrets {
+		secrets, err := cluster.Typed.CoreV1().Secrets("").List(ctx, metav1.ListOptions{FieldSelector: "type=" + string(corev1.SecretTypeTLS)})
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range secrets.Items {
+			if s.Type != corev1.SecretTypeTLS || managed[s.Namespace+"/"+s.Name] || s.Annotations["cert-manager.io/certificate-name"] != "" {
+				continue
+			}
+			cert, err := parseTLSSecret(s)
+			if err != nil {
+				log.Printf("⚠️ [%s] TLS-секрет %s/%s не разобран: %v", cluster.Name, s.Namespace, s.Name, err)
+				continue
+			}
+			certs = append(certs, cert)
+		}
+	}
+
+	sort.Slice(certs, func(i, j int) bool {
+		// Ещё не выпущенные — в начале, дальше по сроку
+		if !certs[i].NotAfter.Equal(certs[j].NotAfter) {
+			return certs[i].NotAfter.Before(certs[j].NotAfter)
+		}
+		return certs[i].key() < certs[j].key()
+	})
+	return certs, nil
+}
+
+func parseCertificate(obj unstructured.Unstructured) certInfo {
+	c := certInfo{Namespace: obj.GetNamespace(), Name: obj.GetName(), Source: certSourceManager, Ready: true}
+	kind, _, _ := unstructured.NestedString(obj.Object, "spec", "issuerRef", "kind")
+	name, _, _ := unstructured.NestedString(obj.Object, "spec", "issuerRef", "name")
+	if kind == "" {
+		kind = "Issuer"
+	}
+	c.Issuer = kind + "/" + name
+	if v, _, _ := unstructured.NestedString(obj.Object, "status", "notAfter"); v != "" {
+		c.NotAfter, _ = time.Parse(time.RFC3339, v)
+	}
+	conds, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, raw := range conds {
+		m, ok := raw.(map[string]interface{})
+		if !ok || m["type"] != "Ready" {
+			continue
+		}
+		// Неготовым считается только явный Ready=False: у только что созданного
+		// сертификата условия ещё нет, и это не повод для алерта
+		if m["status"] == "False" {
+			c.Ready = false
+			c.Reason, _ = m["message"].(string)
+		}
+	}
+	return c
+}
+
+// parseTLSSecret читает первый сертификат цепочки из tls.crt
+func parseTLSSecret(s corev1.Secret) (certInfo, error) {
+	block, _ := pem.Decode(s.Data[corev1.TLSCertKey])
+	if block == nil || block.Type != "CERTIFICATE" {
+		return certInfo{}, fmt.Errorf("в %s нет PEM-сертификата", corev1.TLSCertKey)
+	}
+	x, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return certInfo{}, err
+	}
+	issuer := x.Issuer.CommonName
+	if issuer == "" {
+		issuer = x.Issuer.String()
+	}
+	return certInfo{Namespace: s.Namespace, Name: s.Name, Source: certSourceSecret, Issuer: issuer, NotAfter: x.NotAfter, Ready: true}, nil
+}
+
+// CertWatcher сообщает о неготовых и скоро истекающих сертификатах
+type CertWatcher struct {
+	cluster *Cluster
+	cfg     CertConfig
+	bot     *tgbotapi.BotAPI
+	adminID int64
+
+	notReady map[string]bool      // сертификаты, о неготовности которых сообщено
+	expiring map[string]time.Time // сертификат → notAfter, о котором уже предупредили
+}
+
+// NewCertWatcher создаёт наблюдателя за сертификатами кластера
+func NewCertWatcher(cluster *Cluster, cfg CertConfig, bot *tgbotapi.BotAPI, adminID int64) *CertWatcher {
+	return &CertWatcher{
+		cluster:  cluster,
+		cfg:      cfg,
+		bot:      bot,
+		adminID:  adminID,
+		notReady: make(map[string]bool),
+		expiring: make(map[string]time.Time),
+	}
+}
+
+// Start проверяет сертификаты сразу и затем каждые PollInterval
+func (w *CertWatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		w.check(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *CertWatcher) check(ctx context.Context, now time.Time) {
+	certs, err := listCertificates(ctx, w.cluster, w.cfg.ScanSecrets)
+	if err != nil {
+		log.Printf("❌ [%s] Ошибка получения сертификатов: %v", w.cluster.Name, err)
+		return
+	}
+	lang := langFor(w.adminID)
+	present := make(map[string]bool, len(certs))
+	for _, c := range certs {
+		key := c.key()
+		present[key] = true
+
+		switch {
+		case !c.Ready && !w.notReady[key]:
+			w.notReady[key] = true
+			w.notify("cert_not_ready", renderCertNotReadyAlert(lang, w.cluster.Name, c))
+			log.Printf("🔔 [%s] Сертификат %s/%s не готов: %s", w.cluster.Name, c.Namespace, c.Name, c.Reason)
+		case c.Ready && w.notReady[key]:
+			delete(w.notReady, key)
+			w.notify("cert_ready", renderCertReady(lang, w.cluster.Name, c, now))
+		}
+
+		if c.NotAfter.IsZero() || c.NotAfter.Sub(now) > w.cfg.WarnWithin || w.expiring[key].Equal(c.NotAfter) {
+			continue
+		}
+		// После продления notAfter меняется, и о новом сроке предупредим снова
+		w.expiring[key] = c.NotAfter
+		w.notify("cert_expiring", renderCertExpiringAlert(lang, w.cluster.Name, c, now))
+		log.Printf("🔔 [%s] Сертификат %s/%s истекает %s", w.cluster.Name, c.Namespace, c.Name, c.NotAfter.Format(time.RFC3339))
+	}
+	for key := range w.expiring {
+		if !present[key] {
+			delete(w.expiring, key)
+		}
+	}
+	for key := range w.notReady {
+		if !present[key] {
+			delete(w.notReady, key)
+		}
+	}
+}
+
+func (w *CertWatcher) notify(kind string, r *Rich) {
+	result := "sent"
+	if err := sendRich(w.bot, w.adminID, r); err != nil {
+		result = "failed"
+	}
+	telemetry.Notifications.Inc(kind, result)
+}
+
+// renderCertExpiringAlert предупреждение об истекающем или истёкшем сертификате
+func renderCertExpiringAlert(lang Lang, cluster string, c certInfo, now time.Time) *Rich {
+	expired := !c.NotAfter.After(now)
+	title := T(lang, "certs.expiring.title")
+	if expired {
+		title = T(lang, "certs.expired.title")
+	}
+	r := NewRich().
+		Text("🔐 " + alertPrefix(cluster)).Bold(title).Line().Line().
+		Text("📜 ").Bold(T(lang, "certs.cert")).Text(" ").Code(c.Namespace + "/" + c.Name).Text(" (" + c.Source + ")").Line().
+		Text("🏛 ").Bold(T(lang, "certs.issuer")).Text(" " + c.Issuer).Line().
+		Text("⏰ ").Bold(T(lang, "certs.not_after")).Text(" " + c.NotAfter.UTC().Format("2006-01-02 15:04 UTC"))
+	if !expired {
+		r.Line().Text(T(lang, "certs.days_left", c.daysLeft(now)))
+	}
+	return r
+}
+
+// renderCertNotReadyAlert уведомление о сертификате cert-manager с Ready=False
+func renderCertNotReadyAlert(lang Lang, cluster string, c certInfo) *Rich {
+	r := NewRich().
+		Text("🚨 " + alertPrefix(cluster)).Bold(T(lang, "certs.not_ready.title")).Line().Line().
+		Text("📜 ").Bold(T(lang, "certs.cert")).Text(" ").Code(c.Namespace + "/" + c.Name).Line().
+		Text("🏛 ").Bold(T(lang, "certs.issuer")).Text(" " + c.Issuer).Line()
+	if c.Reason != "" {
+		r.Text(T(lang, "certs.reason", c.Reason)).Line()
+	}
+	return r.Line().Text(T(lang, "certs.not_ready.text", c.Name, c.Namespace))
+}
+
+// renderCertReady уведомление о том, что сертификат снова готов
+func renderCertReady(lang Lang, cluster string, c certInfo, now time.Time) *Rich {
+	r := NewRich().
+		Text("✅ " + alertPrefix(cluster)).Bold(T(lang, "certs.ready.title")).Line().Line().
+		Text("📜 ").Bold(T(lang, "certs.cert")).Text(" ").Code(c.Namespace + "/" + c.Name).Line()
+	if !c.NotAfter.IsZero() {
+		r.Text(T(lang, "certs.days_left", c.daysLeft(now)))
+	}
+	return r
+}
+
+// handleCerts показывает все сертификаты кластера с оставшимися днями и издателем
+func handleCerts(bot *tgbotapi.BotAPI, cluster *Cluster, cfg CertConfig, ctx context.Context, chatID int64) {
+	lang := langFor(chatID)
+	certs, err := listCertificates(ctx, cluster, cfg.ScanSecrets)
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	sendLongRich(bot, chatID, "certs", renderCerts(lang, clusterLabel(cluster.Name), certs, cfg.WarnWithin, cluster.HasAPI(certManagerAPI), time.Now()))
+}
+
+// renderCerts таблица сертификатов, ближайшие к истечению сверху
+func renderCerts(lang Lang, cluster string, certs []certInfo, warn time.Duration, hasManager bool, now time.Time) *Rich {
+	r := NewRich().Text("🔐 " + cluster).Bold(T(lang, "certs.title", len(certs))).Line()
+	if !hasManager {
+		r.Italic(T(lang, "certs.no_manager")).Line()
+	}
+	if len(certs) == 0 {
+		return r.Text(T(lang, "certs.none"))
+	}
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE/NAME\tDAYS\tREADY\tISSUER\tSOURCE")
+	for _, c := range certs {
+		days := "-"
+		if !c.NotAfter.IsZero() {
+			days = fmt.Sprint(c.daysLeft(now))
+			if c.NotAfter.Sub(now) <= warn {
+				days += "!"
+			}
+		}
+		ready := "True"
+		if !c.Ready {
+			ready = "False"
+		}
+		fmt.Fprintf(w, "%s/%s\t%s\t%s\t%s\t%s\n", c.Namespace, c.Name, days, ready, orDash(c.Issuer), c.Source)
+	}
+	w.Flush()
+	r.Pre(strings.TrimRight(sb.String(), "\n"))
+	return r.Line().Italic(T(lang, "certs.legend", formatDuration(lang, warn)))
+}
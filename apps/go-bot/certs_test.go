@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseCertificateReady(t *testing.T) {
+	cert := func(conds ...interface{}) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "web", "namespace": "prod"},
+			"spec":     map[string]interface{}{"issuerRef": map[string]interface{}{"name": "letsencrypt"}},
+			"status":   map[string]interface{}{"conditions": conds},
+		}}
+	}
+	for _, tc := range []struct {
+		name   string
+		obj    unstructured.Unstructured
+		ready  bool
+		reason string
+	}{
+		{"без условий", cert(), true, ""},
+		{"Ready=True", cert(map[string]interface{}{"type": "Ready", "status": "True"}), true, ""},
+		{"Ready=Unknown", cert(map[string]interface{}{"type": "Ready", "status": "Unknown"}), true, ""},
+		{"Ready=False", cert(map[string]interface{}{"type": "Ready", "status": "False", "message": "Issuing"}), false, "Issuing"},
+	} {
+		c := parseCertificate(tc.obj)
+		if c.Ready != tc.ready || c.Reason != tc.reason {
+			t.Errorf("%s: Ready=%v Reason=%q, ожидалось %v %q", tc.name, c.Ready, c.Reason, tc.ready, tc.reason)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	chartWidth  = 800
+	chartHeight = 400
+	// chartDefaultPeriod период /chart без явного указания
+	chartDefaultPeriod = 24 * time.Hour
+)
+
+var (
+	chartBackground = color.RGBA{255, 255, 255, 255}
+	chartGrid       = color.RGBA{225, 225, 225, 255}
+	chartAxis       = color.RGBA{90, 90, 90, 255}
+	chartFill       = color.RGBA{200, 222, 245, 255}
+	// chartPalette цвета рядов; chartPaletteNames — те же цвета эмодзи для подписи
+	chartPalette = []color.RGBA{
+		{33, 118, 210, 255},
+		{220, 50, 47, 255},
+		{46, 160, 67, 255},
+		{240, 140, 0, 255},
+		{140, 70, 180, 255},
+		{120, 80, 50, 255},
+		{30, 30, 30, 255},
+		{220, 190, 0, 255},
+	}
+	chartPaletteNames = []string{"🟦", "🟥", "🟩", "🟧", "🟪", "🟫", "⬛", "🟨"}
+)
+
+// chartRequest разобранные аргументы /chart
+type chartRequest struct {
+	Target   string
+	Resource string // cpu или mem
+	Period   time.Duration
+}
+
+// parseChartArgs разбирает /chart <node|ns> <cpu|mem> [24h|7d]
+func parseChartArgs(args string) (chartRequest, error) {
+	parts := strings.Fields(args)
+	if len(parts) < 2 || len(parts) > 3 {
+		return chartRequest{}, fmt.Errorf("ожидается 2 или 3 аргумента")
+	}
+	req := chartRequest{Target: parts[0], Resource: parts[1], Period: chartDefaultPeriod}
+	switch req.Resource {
+	case "cpu":
+	case "mem", "memory":
+		req.Resource = "mem"
+	default:
+		return req, fmt.Errorf("ресурс только cpu или mem")
+	}
+	if len(parts) == 3 {
+		d, err := parsePeriod(parts[2])
+		if err != nil {
+			return req, err
+		}
+		req.Period = d
+	}
+	return req, nil
+}
+
+// parsePeriod понимает time.ParseDuration и дни: 7d
+func parsePeriod(v string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("некорректный период: %s", v)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("некорректный период: %s", v)
+	}
+	return d, nil
+}
+
+// handleChart отправляет график потребления узла или namespace картинкой
+func handleChart(bot *tgbotapi.BotAPI, cluster *Cluster, ctx context.Context, chatID int64, req chartRequest) {
+	lang := langFor(chatID)
+	key, ok := metricsStore.resolveSeries(cluster.Name, req.Target, req.Resource)
+	if !ok {
+		sendText(bot, chatID, T(lang, "chart.no_series", req.Target))
+		return
+	}
+	samples := metricsStore.Range(key, time.Now().Add(-req.Period))
+	if len(samples) < 2 {
+		sendText(bot, chatID, T(lang, "chart.not_enough", req.Target))
+		return
+	}
+
+	scale, unit := 1.0, T(lang, "chart.unit_cores")
+	if req.Resource == "mem" {
+		scale, unit = 1.0/(1024*1024*1024), "GiB"
+	}
+	img, err := renderLineChart([][]Sample{samples}, scale, chartWidth, chartHeight)
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "chart.png", Bytes: img})
+	photo.Caption = chartCaption(lang, req, samples, scale, unit)
+	if _, err := bot.Send(photo); err != nil {
+		sendError(bot, chatID, err)
+	}
+}
+
+// chartCaption подпись графика: объект, период и min/avg/max/последнее значение
+func chartCaption(lang Lang, req chartRequest, samples []Sample, scale float64, unit string) string {
+	lo, hi, sum := math.Inf(1), math.Inf(-1), 0.0
+	for _, s := range samples {
+		lo = math.Min(lo, s.Value)
+		hi = math.Max(hi, s.Value)
+		sum += s.Value
+	}
+	avg := sum / float64(len(samples))
+	last := samples[len(samples)-1].Value
+	return T(lang, "chart.caption", req.Target, req.Resource, formatPeriod(req.Period),
+		lo*scale, avg*scale, hi*scale, last*scale, unit)
+}
+
+// formatPeriod выводит период так же, как его вводят: 24h, 7d, 30m
+func formatPeriod(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
+
+// renderLineChart рисует PNG-график рядов, умноженных на scale, с сеткой и подписями осей.
+// Единственный ряд рисуется с заливкой, несколько — линиями цветов chartPalette
+func renderLineChart(series [][]Sample, scale float64, width, height int) ([]byte, error) {
+	const left, right, top, bottom = 60, 30, 20, 40
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, img.Bounds(), chartBackground)
+
+	plot := image.Rect(left, top, width-right, height-bottom)
+	var t0, t1 int64 = math.MaxInt64, math.MinInt64
+	// Шкала всегда включает ноль, отрицательные значения опускают её нижнюю границу
+	lo, hi := 0.0, 0.0
+	for _, samples := range series {
+		for _, s := range samples {
+			t0 = min(t0, s.Time)
+			t1 = max(t1, s.Time)
+			if v := s.Value * scale; !math.IsNaN(v) && !math.IsInf(v, 0) {
+				lo = math.Min(lo, v)
+				hi = math.Max(hi, v)
+			}
+		}
+	}
+	if t0 > t1 {
+		return nil, fmt.Errorf("нет данных для графика")
+	}
+	if t1 == t0 {
+		t1 = t0 + 1
+	}
+	if hi > 0 || lo == 0 {
+		hi = niceCeil(hi)
+	}
+	if lo < 0 {
+		lo = -niceCeil(-lo)
+	}
+
+	px := func(t int64) int {
+		return plot.Min.X + int(float64(t-t0)/float64(t1-t0)*float64(plot.Dx()-1))
+	}
+	// py переводит значение в строку пикселей; всё, что вне шкалы, прижимается к краю области графика
+	py := func(v float64) int {
+		f := (v*scale - lo) / (hi - lo)
+		if math.IsNaN(f) {
+			f = 0
+		}
+		f = math.Max(0, math.Min(1, f))
+		return plot.Max.Y - 1 - int(f*float64(plot.Dy()-1))
+	}
+
+	// Горизонтальная сетка и подписи значений
+	const gridLines = 4
+	for i := 0; i <= gridLines; i++ {
+		v := lo + (hi-lo)*float64(i)/gridLines
+		y := plot.Max.Y - 1 - int(float64(i)/gridLines*float64(plot.Dy()-1))
+		drawHLine(img, plot.Min.X, plot.Max.X, y, chartGrid)
+		label := strconv.FormatFloat(v, 'f', labelPrecision(hi-lo), 64)
+		drawText(img, plot.Min.X-8-textWidth(label), y-glyphHeight*glyphScale/2, label, chartAxis)
+	}
+	// Подписи времени
+	for i := 0; i <= gridLines; i++ {
+		t := t0 + (t1-t0)*int64(i)/gridLines
+		x := px(t)
+		drawVLine(img, x, plot.Min.Y, plot.Max.Y, chartGrid)
+		label := time.Unix(t, 0).Format("15:04")
+		if t1-t0 > 2*24*3600 {
+			label = time.Unix(t, 0).Format("02.01")
+		}
+		drawText(img, x-textWidth(label)/2, plot.Max.Y+10, label, chartAxis)
+	}
+
+	// Заливка между единственной линией и нулём и сами линии
+	if len(series) == 1 {
+		samples := series[0]
+		base := py(0)
+		for i := 1; i < len(samples); i++ {
+			x0, y0 := px(samples[i-1].Time), py(samples[i-1].Value)
+			x1, y1 := px(samples[i].Time), py(samples[i].Value)
+			for x := x0; x <= x1; x++ {
+				y := y0
+				if x1 != x0 {
+					y = y0 + (y1-y0)*(x-x0)/(x1-x0)
+				}
+				if y < base {
+					drawVLine(img, x, y+1, base, chartFill)
+				} else if y > base {
+					drawVLine(img, x, base, y-1, chartFill)
+				}
+			}
+		}
+	}
+	for n, samples := range series {
+		c := chartPalette[n%len(chartPalette)]
+		for i := 1; i < len(samples); i++ {
+			drawLine(img, px(samples[i-1].Time), py(samples[i-1].Value), px(samples[i].Time), py(samples[i].Value), c)
+		}
+	}
+
+	drawHLine(img, plot.Min.X, plot.Max.X, plot.Max.Y, chartAxis)
+	drawVLine(img, plot.Min.X, plot.Min.Y, plot.Max.Y, chartAxis)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// niceCeil округляет границу шкалы вверх до 1, 2, 2.5 или 5 на порядок
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	exp := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, m := range []float64{1, 2, 2.5, 5, 10} {
+		if v <= m*exp {
+			return m * exp
+		}
+	}
+	return 10 * exp
+}
+
+func labelPrecision(hi float64) int {
+	switch {
+	case hi >= 10:
+		return 0
+	case hi >= 1:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+func drawHLine(img *image.RGBA, x0, x1, y int, c color.RGBA) {
+	for x := x0; x <= x1; x++ {
+		img.SetRGBA(x, y, c)
+	}
+}
+
+func drawVLine(img *image.RGBA, x, y0, y1 int, c color.RGBA) {
+	for y := y0; y <= y1; y++ {
+		img.SetRGBA(x, y, c)
+	}
+}
+
+// drawLine рисует отрезок толщиной 2 пикселя алгоритмом Брезенхэма
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.SetRGBA(x0, y0, c)
+		img.SetRGBA(x0, y0+1, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+const (
+	glyphWidth  = 3
+	glyphHeight = 5
+	glyphScale  = 2
+)
+
+// glyphs растровый шрифт 3x5 для подписей осей: цифры, точка, двоеточие и минус
+var glyphs = map[rune][glyphHeight]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'.': {"...", "...", "...", "...", ".#."},
+	':': {"...", ".#.", "...", ".#.", "..."},
+	'-': {"...", "...", "###", "...", "..."},
+}
+
+func textWidth(s string) int {
+	return len(s) * (glyphWidth + 1) * glyphScale
+}
+
+func drawText(img *image.RGBA, x, y int, s string, c color.RGBA) {
+	for _, ch := range s {
+		g, ok := glyphs[ch]
+		if ok {
+			for row, line := range g {
+				for col, px := range line {
+					if px != '#' {
+						continue
+					}
+					fillRect(img, image.Rect(x+col*glyphScale, y+row*glyphScale,
+						x+(col+1)*glyphScale, y+(row+1)*glyphScale), c)
+				}
+			}
+		}
+		x += (glyphWidth + 1) * glyphScale
+	}
+}
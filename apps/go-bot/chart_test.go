@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRenderLineChartNegativeValues(t *testing.T) {
+	cases := map[string][][]Sample{
+		// -node_memory_MemTotal_bytes: раньше точка уходила на ~1e12 пикселей вниз
+		"negative":    {{{Time: 0, Value: -8e12}, {Time: 60, Value: -7.9e12}, {Time: 120, Value: -8.1e12}}},
+		"mixed":       {{{Time: 0, Value: -5}, {Time: 60, Value: 3}}, {{Time: 0, Value: 10}, {Time: 60, Value: -20}}},
+		"zero":        {{{Time: 0, Value: 0}, {Time: 60, Value: 0}}},
+		"single":      {{{Time: 0, Value: 42}}},
+		"nan and inf": {{{Time: 0, Value: math.NaN()}, {Time: 60, Value: math.Inf(-1)}, {Time: 120, Value: 1}}},
+	}
+	for name, series := range cases {
+		t.Run(name, func(t *testing.T) {
+			done := make(chan struct{})
+			var data []byte
+			var err error
+			go func() {
+				data, err = renderLineChart(series, 1, chartWidth, chartHeight)
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("график рисуется слишком долго")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := img.Bounds(); b.Dx() != chartWidth || b.Dy() != chartHeight {
+				t.Errorf("размер %v", b)
+			}
+		})
+	}
+}
+
+func TestRenderLineChartEmpty(t *testing.T) {
+	if _, err := renderLineChart([][]Sample{nil}, 1, chartWidth, chartHeight); err == nil {
+		t.Error("пустой ряд должен давать ошибку")
+	}
+}
+
+func TestNiceCeil(t *testing.T) {
+	for v, want := range map[float64]float64{0: 1, 0.3: 0.5, 1: 1, 1.2: 2, 2.2: 2.5, 7: 10, 8e12: 1e13} {
+		if got := niceCeil(v); math.Abs(got-want) > want*1e-9 {
+			t.Errorf("niceCeil(%v) = %v, ожидалось %v", v, got, want)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// deploymentRevisionAnnotation номер ревизии Deployment-а на его ReplicaSet-ах
+const deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// cleanupListLimit сколько объектов каждого вида показывать в отчёте
+const cleanupListLimit = 15
+
+// cleanupItem объект, который /cleanup предлагает удалить; UID и ResourceVersion
+// запоминаются из отчёта, чтобы не удалить объект, изменившийся до подтверждения
+type cleanupItem struct {
+	Namespace       string
+	Name            string
+	UID             types.UID
+	ResourceVersion string
+	Reason          string
+	Age             time.Duration
+}
+
+// newCleanupItem элемент отчёта для объекта
+func newCleanupItem(meta metav1.ObjectMeta, reason string, age time.Duration) cleanupItem {
+	return cleanupItem{
+		Namespace:       meta.Namespace,
+		Name:            meta.Name,
+		UID:             meta.UID,
+		ResourceVersion: meta.ResourceVersion,
+		Reason:          reason,
+		Age:             age,
+	}
+}
+
+// cleanupReport найденный мусор по видам объектов
+type cleanupReport struct {
+	Jobs        []cleanupItem
+	Pods        []cleanupItem
+	ReplicaSets []cleanupItem
+}
+
+// Total сколько объектов всего
+func (r *cleanupReport) Total() int {
+	return len(r.Jobs) + len(r.Pods) + len(r.ReplicaSets)
+}
+
+// collectCleanup ищет то же, что чистит ansible-плейбук, и старые ReplicaSet-ы:
+// успешные Job-ы старше cfg.JobAge, поды в фазе Failed (в том числе Evicted) и
+// ReplicaSet-ы без реплик, которые не нужны для отката
+func collectCleanup(ctx context.Context, clientset kubernetes.Interface, cfg CleanupConfig, now time.Time) (*cleanupReport, error) {
+	report := &cleanupReport{}
+
+	jobs, err := clientset.BatchV1().Jobs("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if finished, failed := jobFinished(job); !finished || failed || job.Status.CompletionTime == nil {
+			continue
+		}
+		if age := now.Sub(job.Status.CompletionTime.Time); age >= cfg.JobAge {
+			report.Jobs = append(report.Jobs, newCleanupItem(job.ObjectMeta, "Complete", age))
+		}
+	}
+
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{FieldSelector: "status.phase=" + string(corev1.PodFailed)})
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodFailed || pod.DeletionTimestamp != nil {
+			continue
+		}
+		// Поды Job-ов удаляются вместе с Job и нужны ему для подсчёта попыток
+		if owner := metav1.GetControllerOf(&pod); owner != nil && owner.Kind == "Job" {
+			continue
+		}
+		report.Pods = append(report.Pods, newCleanupItem(pod.ObjectMeta, orDash(pod.Status.Reason), cleanupAge(now, pod.CreationTimestamp.Time)))
+	}
+
+	deployments, err := clientset.AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	replicaSets, err := clientset.AppsV1().ReplicaSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	report.ReplicaSets = staleReplicaSets(deployments.Items, replicaSets.Items, now)
+
+	for _, items := range [][]cleanupItem{report.Jobs, report.Pods, report.ReplicaSets} {
+		sort.Slice(items, func(i, j int) bool { return items[i].Age > items[j].Age })
+	}
+	return report, nil
+}
+
+// staleReplicaSets ReplicaSet-ы без реплик: без Deployment-а (orphaned) или старше
+// revisionHistoryLimit своего Deployment-а. Контроллер обычно подчищает историю сам,
+// но не после ручного уменьшения лимита или удаления Deployment-а с --cascade=orphan.
+// ReplicaSet текущей ревизии не трогаем, даже если Deployment масштабирован в ноль
+func staleReplicaSets(deployments []appsv1.Deployment, replicaSets []appsv1.ReplicaSet, now time.Time) []cleanupItem {
+	limits := make(map[types.UID]int, len(deployments))
+	current := make(map[types.UID]string, len(deployments))
+	for _, d := range deployments {
+		limit := 10
+		if d.Spec.RevisionHistoryLimit != nil {
+			limit = int(*d.Spec.RevisionHistoryLimit)
+		}
+		limits[d.UID] = limit
+		current[d.UID] = d.Annotations[deploymentRevisionAnnotation]
+	}
+
+	var items []cleanupItem
+	history := make(map[types.UID][]appsv1.ReplicaSet)
+	for _, rs := range replicaSets {
+		if rs.DeletionTimestamp != nil || rs.Status.Replicas != 0 || (rs.Spec.Replicas != nil && *rs.Spec.Replicas != 0) {
+			continue
+		}
+		owner := metav1.GetControllerOf(&rs)
+		if owner == nil || owner.Kind != "Deployment" {
+			// У ReplicaSet-а без владельца нет и истории ревизий
+			if owner == nil {
+				items = append(items, newCleanupItem(rs.ObjectMeta, "orphaned", cleanupAge(now, rs.CreationTimestamp.Time)))
+			}
+			continue
+		}
+		if _, ok := limits[owner.UID]; !ok {
+			items = append(items, newCleanupItem(rs.ObjectMeta, "orphaned", cleanupAge(now, rs.CreationTimestamp.Time)))
+			continue
+		}
+		if rev := rs.Annotations[deploymentRevisionAnnotation]; rev != "" && rev == current[owner.UID] {
+			continue
+		}
+		history[owner.UID] = append(history[owner.UID], rs)
+	}
+
+	for uid, old := range history {
+		limit := limits[uid]
+		if len(old) <= limit {
+			continue
+		}
+		// Новые ревизии нужны для отката, удаляем самые старые
+		sort.Slice(old, func(i, j int) bool { return replicaSetRevision(old[i]) > replicaSetRevision(old[j]) })
+		for _, rs := range old[limit:] {
+			reason := "revision " + orDash(rs.Annotations[deploymentRevisionAnnotation])
+			items = append(items, newCleanupItem(rs.ObjectMeta, reason, cleanupAge(now, rs.CreationTimestamp.Time)))
+		}
+	}
+	return items
+}
+
+// cleanupAge возраст объекта; без времени создания — ноль, в отчёте это "-"
+func cleanupAge(now, created time.Time) time.Duration {
+	if created.IsZero() {
+		return 0
+	}
+	return now.Sub(created)
+}
+
+func replicaSetRevision(rs appsv1.ReplicaSet) int64 {
+	v, _ := strconv.ParseInt(rs.Annotations[deploymentRevisionAnnotation], 10, 64)
+	return v
+}
+
+// executeCleanup удаляет найденное; уже удалённые объекты не считаются ошибкой.
+// Удаление идёт с предусловием на UID и ResourceVersion из отчёта: объект, который
+// пересоздали или изменили после отчёта (например, ReplicaSet снова получил реплики),
+// API-сервер не удалит
+func executeCleanup(ctx context.Context, clientset kubernetes.Interface, report *cleanupReport) (deleted int, errs []error) {
+	background := metav1.DeletePropagationBackground
+	remove := func(kind string, items []cleanupItem, del func(ns, name string, opts metav1.DeleteOptions) error) {
+		for _, it := range items {
+			opts := metav1.DeleteOptions{PropagationPolicy: &background}
+			if it.UID != "" || it.ResourceVersion != "" {
+				opts.Preconditions = &metav1.Preconditions{}
+				if it.UID != "" {
+					opts.Preconditions.UID = &it.UID
+				}
+				if it.ResourceVersion != "" {
+					opts.Preconditions.ResourceVersion = &it.ResourceVersion
+				}
+			}
+			err := del(it.Namespace, it.Name, opts)
+			switch {
+			case err == nil:
+				deleted++
+			case apierrors.IsNotFound(err):
+			case apierrors.IsConflict(err):
+				errs = append(errs, fmt.Errorf("%s %s/%s: изменился после отчёта, пропущен", kind, it.Namespace, it.Name))
+			default:
+				errs = append(errs, fmt.Errorf("%s %s/%s: %w", kind, it.Namespace, it.Name, err))
+			}
+		}
+	}
+	remove("Job", report.Jobs, func(ns, name string, opts metav1.DeleteOptions) error {
+		return clientset.BatchV1().Jobs(ns).Delete(ctx, name, opts)
+	})
+	remove("Pod", report.Pods, func(ns, name string, opts metav1.DeleteOptions) error {
+		return clientset.CoreV1().Pods(ns).Delete(ctx, name, opts)
+	})
+	remove("ReplicaSet", report.ReplicaSets, func(ns, name string, opts metav1.DeleteOptions) error {
+		return clientset.AppsV1().ReplicaSets(ns).Delete(ctx, name, opts)
+	})
+	return deleted, errs
+}
+
+// cleanupError сводная ошибка очистки для журнала аудита
+func cleanupError(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("ошибок: %d, первая: %w", len(errs), errs[0])
+}
+
+// cleanupSummary краткая сводка для журнала аудита
+func cleanupSummary(r *cleanupReport) string {
+	return fmt.Sprintf("jobs=%d pods=%d replicasets=%d", len(r.Jobs), len(r.Pods), len(r.ReplicaSets))
+}
+
+// handleCleanup показывает, что можно удалить, и удаляет после подтверждения автором: /cleanup [--dry-run]
+func handleCleanup(bot *tgbotapi.BotAPI, cluster *Cluster, cfg CleanupConfig, ctx context.Context, chatID int64, user *tgbotapi.User, args string) {
+	lang := langFor(chatID)
+	dryRun := false
+	switch strings.TrimSpace(args) {
+	case "":
+	case "--dry-run":
+		dryRun = true
+	default:
+		sendText(bot, chatID, T(lang, "usage.cleanup"))
+		return
+	}
+
+	report, err := collectCleanup(ctx, cluster.Typed, cfg, time.Now())
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	label := clusterLabel(cluster.Name)
+	if report.Total() == 0 {
+		sendText(bot, chatID, "✨ "+label+T(lang, "cleanup.nothing"))
+		return
+	}
+	prompt := renderCleanupReport(lang, label, report, cfg)
+	if dryRun {
+		prompt.Line().Line().Italic(T(lang, "cleanup.dry_run"))
+		sendRich(bot, chatID, prompt)
+		return
+	}
+	owner := userLabel(user)
+	askUserConfirmation(bot, chatID, user.ID, prompt, func(ctx context.Context) (*Rich, error) {
+		deleted, errs := executeCleanup(ctx, cluster.Typed, report)
+		auditLog.Record(owner, cluster.Name, "cleanup", cleanupSummary(report), cleanupError(errs))
+		return renderCleanupResult(lang, label, deleted, errs), nil
+	})
+}
+
+// renderCleanupReport отчёт о найденном: по каждому виду объектов самые старые
+func renderCleanupReport(lang Lang, cluster string, r *cleanupReport, cfg CleanupConfig) *Rich {
+	out := NewRich().Text("🧹 " + cluster).Bold(T(lang, "cleanup.title", r.Total()))
+	section := func(title string, items []cleanupItem) {
+		if len(items) == 0 {
+			return
+		}
+		out.Line().Line().Bold(fmt.Sprintf("%s: %d", title, len(items)))
+		for i, it := range items {
+			if i == cleanupListLimit {
+				out.Line().Italic(T(lang, "cleanup.more", len(items)-i))
+				break
+			}
+			age := "-"
+			if it.Age > 0 {
+				age = formatDuration(lang, it.Age)
+			}
+			out.Line().Text("• ").Code(it.Namespace + "/" + it.Name).Text(" — " + it.Reason + ", " + age)
+		}
+	}
+	section(T(lang, "cleanup.jobs", formatDuration(lang, cfg.JobAge)), r.Jobs)
+	section(T(lang, "cleanup.pods"), r.Pods)
+	section(T(lang, "cleanup.replicasets"), r.ReplicaSets)
+	return out
+}
+
+// renderCleanupResult итог удаления
+func renderCleanupResult(lang Lang, cluster string, deleted int, errs []error) *Rich {
+	r := NewRich().Text("🧹 " + cluster).Bold(T(lang, "cleanup.done", deleted))
+	for i, err := range errs {
+		if i == cleanupListLimit {
+			r.Line().Italic(T(lang, "cleanup.more", len(errs)-i))
+			break
+		}
+		r.Line().Text("❌ " + err.Error())
+	}
+	return r
+}
+
+// Cleaner очищает все кластеры по расписанию CLEANUP_SCHEDULE без подтверждения
+type Cleaner struct {
+	cfg     CleanupConfig
+	bot     *tgbotapi.BotAPI
+	adminID int64
+}
+
+// NewCleaner создаёт плановую очистку
+func NewCleaner(cfg CleanupConfig, bot *tgbotapi.BotAPI, adminID int64) *Cleaner {
+	return &Cleaner{cfg: cfg, bot: bot, adminID: adminID}
+}
+
+// Start ждёт срабатывания расписания и чистит кластеры; без расписания сразу завершается
+func (c *Cleaner) Start(ctx context.Context) {
+	if c.cfg.cron == nil {
+		return
+	}
+	for {
+		next := c.cfg.cron.Next(time.Now().In(c.cfg.Location))
+		if next.IsZero() {
+			log.Println("⚠️ Расписание очистки больше не сработает")
+			return
+		}
+		log.Printf("🧹 Следующая очистка: %s", next.Format("2006-01-02 15:04 MST"))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		for _, cluster := range clusters.All() {
+			c.run(ctx, cluster)
+		}
+	}
+}
+
+// run чистит один кластер и сообщает администратору, если что-то было удалено
+func (c *Cleaner) run(ctx context.Context, cluster *Cluster) {
+	report, err := collectCleanup(ctx, cluster.Typed, c.cfg, time.Now())
+	if err != nil {
+		log.Printf("❌ [%s] Ошибка плановой очистки: %v", cluster.Name, err)
+		return
+	}
+	if report.Total() == 0 {
+		return
+	}
+	deleted, errs := executeCleanup(ctx, cluster.Typed, report)
+	auditLog.Record("schedule", cluster.Name, "cleanup", cleanupSummary(report), cleanupError(errs))
+	log.Printf("🧹 [%s] Плановая очистка: удалено %d из %d", cluster.Name, deleted, report.Total())
+
+	lang := langFor(c.adminID)
+	r := renderCleanupResult(lang, alertPrefix(cluster.Name), deleted, errs)
+	r.Line().Line().Text(T(lang, "cleanup.scheduled", cleanupSummary(report)))
+	result := "sent"
+	if err := sendRich(c.bot, c.adminID, r); err != nil {
+		result = "failed"
+	}
+	telemetry.Notifications.Inc("cleanup", result)
+}
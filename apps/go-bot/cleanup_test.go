@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func int32Ptr(n int32) *int32 { return &n }
+
+func cleanupDeployment(name, revision string, historyLimit int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        name,
+			UID:         types.UID(name + "-uid"),
+			Annotations: map[string]string{deploymentRevisionAnnotation: revision},
+		},
+		Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(0), RevisionHistoryLimit: int32Ptr(historyLimit)},
+	}
+}
+
+func cleanupReplicaSet(name string, owner *appsv1.Deployment, revision string, replicas int32, created time.Time) *appsv1.ReplicaSet {
+	rs := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "default",
+			Name:              name,
+			UID:               types.UID(name + "-uid"),
+			ResourceVersion:   "1",
+			CreationTimestamp: metav1.NewTime(created),
+			Annotations:       map[string]string{deploymentRevisionAnnotation: revision},
+		},
+		Spec:   appsv1.ReplicaSetSpec{Replicas: int32Ptr(replicas)},
+		Status: appsv1.ReplicaSetStatus{Replicas: replicas},
+	}
+	if owner != nil {
+		rs.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(owner, appsv1.SchemeGroupVersion.WithKind("Deployment"))}
+	}
+	return rs
+}
+
+func itemNames(items []cleanupItem) string {
+	names := make([]string, 0, len(items))
+	for _, it := range items {
+		names = append(names, it.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+func TestStaleReplicaSets(t *testing.T) {
+	now := goldenNow
+	// Deployment масштабирован в ноль: у ReplicaSet-а текущей ревизии тоже нет реплик
+	api := cleanupDeployment("api", "5", 1)
+	web := cleanupDeployment("web", "3", 10)
+	gone := cleanupDeployment("gone", "1", 10)
+	var replicaSets []appsv1.ReplicaSet
+	for _, rs := range []*appsv1.ReplicaSet{
+		cleanupReplicaSet("api-5", api, "5", 0, now.Add(-time.Hour)),
+		cleanupReplicaSet("api-4", api, "4", 0, now.Add(-2*time.Hour)),
+		cleanupReplicaSet("api-3", api, "3", 0, now.Add(-3*time.Hour)),
+		cleanupReplicaSet("api-2", api, "2", 0, now.Add(-4*time.Hour)),
+		cleanupReplicaSet("web-3", web, "3", 2, now.Add(-time.Hour)),
+		cleanupReplicaSet("web-2", web, "2", 0, now.Add(-2*time.Hour)),
+		cleanupReplicaSet("gone-1", gone, "1", 0, now.Add(-time.Hour)),
+		cleanupReplicaSet("manual", nil, "", 0, now.Add(-time.Hour)),
+		cleanupReplicaSet("manual-live", nil, "", 1, now.Add(-time.Hour)),
+	} {
+		replicaSets = append(replicaSets, *rs)
+	}
+
+	items := staleReplicaSets([]appsv1.Deployment{*api, *web}, replicaSets, now)
+	if got := itemNames(items); got != "api-2,api-3,gone-1,manual" {
+		t.Fatalf("к удалению: %s", got)
+	}
+	for _, it := range items {
+		if it.UID != types.UID(it.Name+"-uid") || it.ResourceVersion != "1" {
+			t.Errorf("%s: в отчёте нет UID/ResourceVersion: %+v", it.Name, it)
+		}
+	}
+}
+
+// enforcePreconditions проверяет предусловия удаления, как API-сервер: fake-клиент их игнорирует
+func enforcePreconditions(clientset *fake.Clientset) {
+	clientset.PrependReactor("delete", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		del := action.(k8stesting.DeleteAction)
+		pre := del.GetDeleteOptions().Preconditions
+		if pre == nil {
+			return false, nil, nil
+		}
+		obj, err := clientset.Tracker().Get(action.GetResource(), action.GetNamespace(), del.GetName())
+		if err != nil {
+			return false, nil, nil
+		}
+		m, _ := meta.Accessor(obj)
+		if (pre.UID != nil && *pre.UID != m.GetUID()) || (pre.ResourceVersion != nil && *pre.ResourceVersion != m.GetResourceVersion()) {
+			gr := action.GetResource().GroupResource()
+			return true, nil, apierrors.NewConflict(gr, del.GetName(), errors.New("precondition failed"))
+		}
+		return false, nil, nil
+	})
+}
+
+func cleanupObjects(now time.Time) []runtime.Object {
+	api := cleanupDeployment("api", "3", 0)
+	done := metav1.NewTime(now.Add(-48 * time.Hour))
+	return []runtime.Object{
+		api,
+		cleanupReplicaSet("api-3", api, "3", 0, now.Add(-time.Hour)),
+		cleanupReplicaSet("api-2", api, "2", 0, now.Add(-2*time.Hour)),
+		cleanupReplicaSet("api-1", api, "1", 0, now.Add(-3*time.Hour)),
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "migrate", UID: "migrate-uid", ResourceVersion: "1"},
+			Status: batchv1.JobStatus{
+				CompletionTime: &done,
+				Conditions:     []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "evicted", UID: "evicted-uid", ResourceVersion: "1"},
+			Status:     corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "running", UID: "running-uid", ResourceVersion: "1"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	}
+}
+
+func TestExecuteCleanupSkipsChangedObjects(t *testing.T) {
+	now := time.Now()
+	clientset := fake.NewSimpleClientset(cleanupObjects(now)...)
+	enforcePreconditions(clientset)
+	ctx := context.Background()
+
+	report, err := collectCleanup(ctx, clientset, DefaultCleanupConfig(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if itemNames(report.Jobs) != "migrate" || itemNames(report.Pods) != "evicted" || itemNames(report.ReplicaSets) != "api-1,api-2" {
+		t.Fatalf("отчёт: jobs=%s pods=%s rs=%s", itemNames(report.Jobs), itemNames(report.Pods), itemNames(report.ReplicaSets))
+	}
+
+	// До подтверждения откатились на ревизию 2: её ReplicaSet снова получил реплики
+	rollback := cleanupReplicaSet("api-2", cleanupDeployment("api", "3", 0), "2", 2, now.Add(-2*time.Hour))
+	rollback.ResourceVersion = "2"
+	if _, err := clientset.AppsV1().ReplicaSets("default").Update(ctx, rollback, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	// а evicted удалили вручную
+	if err := clientset.CoreV1().Pods("default").Delete(ctx, "evicted", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, errs := executeCleanup(ctx, clientset, report)
+	if deleted != 2 || len(errs) != 1 || !strings.Contains(errs[0].Error(), "ReplicaSet default/api-2: изменился после отчёта") {
+		t.Fatalf("удалено %d, ошибки %v", deleted, errs)
+	}
+	if _, err := clientset.AppsV1().ReplicaSets("default").Get(ctx, "api-2", metav1.GetOptions{}); err != nil {
+		t.Errorf("изменившийся ReplicaSet удалён: %v", err)
+	}
+	for _, name := range []string{"api-1", "api-3"} {
+		_, err := clientset.AppsV1().ReplicaSets("default").Get(ctx, name, metav1.GetOptions{})
+		if want := name == "api-1"; apierrors.IsNotFound(err) != want {
+			t.Errorf("%s: удалён=%v", name, apierrors.IsNotFound(err))
+		}
+	}
+}
+
+func TestCleanupConfirmationOnlyByAuthor(t *testing.T) {
+	cluster := newFakeCluster(t, nil, cleanupObjects(time.Now()))
+	bot, ft := newFakeTelegram(t, nil)
+	ctx := context.Background()
+	author := &tgbotapi.User{ID: 100}
+
+	handleCleanup(bot, cluster, DefaultCleanupConfig(), ctx, 42, author, "")
+	id := pendingActionFor(t, 42)
+
+	handleConfirm(bot, ctx, 42, 200, 7, id, true)
+	if sent := sentSince(ft, 1); len(sent) != 1 || sent[0] != T(langFor(42), "confirm.not_yours") {
+		t.Fatalf("чужое подтверждение: %q", sent)
+	}
+	if _, err := cluster.Typed.BatchV1().Jobs("default").Get(ctx, "migrate", metav1.GetOptions{}); err != nil {
+		t.Fatalf("Job удалён по чужому подтверждению: %v", err)
+	}
+
+	handleConfirm(bot, ctx, 42, author.ID, 7, id, true)
+	if _, err := cluster.Typed.BatchV1().Jobs("default").Get(ctx, "migrate", metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("Job не удалён после подтверждения автором: %v", err)
+	}
+	if _, err := cluster.Typed.CoreV1().Pods("default").Get(ctx, "running", metav1.GetOptions{}); err != nil {
+		t.Errorf("удалён работающий под: %v", err)
+	}
+}
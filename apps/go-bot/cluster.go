@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+const (
+	// metricsAPI группа/версия metrics-server
+	metricsAPI = "metrics.k8s.io/v1beta1"
+	// inClusterContext контекст, означающий конфигурацию сервисного аккаунта
+	inClusterContext = "in-cluster"
+)
+
+// Cluster общие клиенты одного кластера, создаются один раз при старте
+type Cluster struct {
+	Name    string
+	Config  *rest.Config
+	Typed   kubernetes.Interface
+	Metrics metricsv.Interface
+	Dynamic dynamic.Interface
+
+	mu        sync.RWMutex
+	apis      map[string]bool // доступные group/version
+	refreshed time.Time
+}
+
+// NewCluster создаёт клиенты кластера по настройкам подключения
+func NewCluster(name string, cfg ClusterConfig) (*Cluster, error) {
+	restCfg, err := buildRestConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	restCfg.QPS = cfg.QPS
+	restCfg.Burst = cfg.Burst
+	restCfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return instrumentTransport(rt, "kubernetes", name)
+	})
+
+	typed, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return nil, fmt.Errorf("клиент Kubernetes: %w", err)
+	}
+	metricsClient, err := metricsv.NewForConfig(restCfg)
+	if err != nil {
+		return nil, fmt.Errorf("клиент metrics: %w", err)
+	}
+	dynamicClient, err := dynamic.NewForConfig(restCfg)
+	if err != nil {
+		return nil, fmt.Errorf("dynamic клиент: %w", err)
+	}
+
+	return &Cluster{
+		Name:    name,
+		Config:  restCfg,
+		Typed:   typed,
+		Metrics: metricsClient,
+		Dynamic: dynamicClient,
+		apis:    make(map[string]bool),
+	}, nil
+}
+
+// buildRestConfig выбирает источник конфигурации: явный kubeconfig/контекст,
+// затем in-cluster, затем KUBECONFIG или ~/.kube/config
+func buildRestConfig(cfg ClusterConfig) (*rest.Config, error) {
+	if cfg.Context == inClusterContext {
+		return rest.InClusterConfig()
+	}
+	if cfg.Kubeconfig == "" && cfg.Context == "" {
+		if restCfg, err := rest.InClusterConfig(); err == nil {
+			return restCfg, nil
+		}
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if cfg.Kubeconfig != "" {
+		rules.ExplicitPath = cfg.Kubeconfig
+	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: cfg.Context}
+	restCfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось найти конфигурацию Kubernetes: %w", err)
+	}
+	return restCfg, nil
+}
+
+// NewClusters создаёт клиенты всех кластеров из KUBE_CLUSTERS или один кластер по умолчанию
+func NewClusters(cfg ClusterConfig) (*ClusterRegistry, error) {
+	specs := cfg.Clusters
+	if len(specs) == 0 {
+		name := cfg.Context
+		if name == "" {
+			name = "default"
+		}
+		specs = []ClusterSpec{{Name: name, Context: cfg.Context}}
+	}
+
+	reg := newClusterRegistry()
+	for _, spec := range specs {
+		if _, exists := reg.Get(spec.Name); exists {
+			return nil, fmt.Errorf("кластер %s указан дважды", spec.Name)
+		}
+		clusterCfg := cfg
+		clusterCfg.Context = spec.Context
+		c, err := NewCluster(spec.Name, clusterCfg)
+		if err != nil {
+			return nil, fmt.Errorf("кластер %s: %w", spec.Name, err)
+		}
+		reg.Add(c)
+	}
+	return reg, nil
+}
+
+// RefreshCapabilities перечитывает список API-групп сервера
+func (c *Cluster) RefreshCapabilities() error {
+	groups, err := c.Typed.Discovery().ServerGroups()
+	if err != nil {
+		return err
+	}
+	apis := make(map[string]bool)
+	for _, g := range groups.Groups {
+		for _, v := range g.Versions {
+			apis[v.GroupVersion] = true
+		}
+	}
+
+	c.mu.Lock()
+	c.apis = apis
+	c.refreshed = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+// WatchCapabilities периодически обновляет сведения об API-группах
+func (c *Cluster) WatchCapabilities(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.RefreshCapabilities(); err != nil {
+				log.Printf("⚠️ [%s] Ошибка discovery: %v", c.Name, err)
+			}
+		}
+	}
+}
+
+// HasAPI сообщает, обслуживает ли сервер указанную group/version
+func (c *Cluster) HasAPI(groupVersion string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.apis[groupVersion]
+}
+
+// HasMetrics сообщает, установлен ли metrics-server
+func (c *Cluster) HasMetrics() bool {
+	return c.HasAPI(metricsAPI)
+}
+
+// ClusterRegistry кластеры бота и активный кластер каждого чата
+type ClusterRegistry struct {
+	mu       sync.RWMutex
+	clusters map[string]*Cluster
+	names    []string // порядок из конфигурации, первый — кластер по умолчанию
+	chats    map[int64]string
+}
+
+// clusters кластеры бота, задаются в main
+var clusters = newClusterRegistry()
+
+func newClusterRegistry() *ClusterRegistry {
+	return &ClusterRegistry{
+		clusters: make(map[string]*Cluster),
+		chats:    make(map[int64]string),
+	}
+}
+
+// Add регистрирует кластер
+func (r *ClusterRegistry) Add(c *Cluster) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.clusters[c.Name]; !exists {
+		r.names = append(r.names, c.Name)
+	}
+	r.clusters[c.Name] = c
+}
+
+// Get возвращает кластер по имени
+func (r *ClusterRegistry) Get(name string) (*Cluster, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.clusters[name]
+	return c, ok
+}
+
+// All возвращает кластеры в порядке конфигурации
+func (r *ClusterRegistry) All() []*Cluster {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]*Cluster, 0, len(r.names))
+	for _, name := range r.names {
+		out = append(out, r.clusters[name])
+	}
+	return out
+}
+
+// Names возвращает имена кластеров в порядке конфигурации
+func (r *ClusterRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.names...)
+}
+
+// For возвращает активный кластер чата или кластер по умолчанию
+func (r *ClusterRegistry) For(chatID int64) *Cluster {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if c, ok := r.clusters[r.chats[chatID]]; ok {
+		return c
+	}
+	if len(r.names) == 0 {
+		return nil
+	}
+	return r.clusters[r.names[0]]
+}
+
+// Select делает кластер активным для чата
+func (r *ClusterRegistry) Select(chatID int64, name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.clusters[name]; !ok {
+		return false
+	}
+	r.chats[chatID] = name
+	return true
+}
+
+// handleCluster показывает кластеры или переключает активный кластер чата
+func handleCluster(bot *tgbotapi.BotAPI, chatID int64, args string) {
+	lang := langFor(chatID)
+	name := strings.TrimSpace(args)
+	if name == "" {
+		names := clusters.Names()
+		var rows [][]tgbotapi.InlineKeyboardButton
+		for _, n := range names {
+			if data := "cluster " + n; len(data) <= maxCallbackData {
+				rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(n, data)))
+			}
+		}
+		r := renderClusterList(lang, names, clusters.For(chatID).Name)
+		if len(rows) < 2 {
+			sendRich(bot, chatID, r)
+			return
+		}
+		sendRichMarkup(bot, chatID, r, tgbotapi.NewInlineKeyboardMarkup(rows...))
+		return
+	}
+	if !clusters.Select(chatID, name) {
+		sendText(bot, chatID, T(lang, "cluster.unknown", name, strings.Join(clusters.Names(), ", ")))
+		return
+	}
+	sendText(bot, chatID, T(lang, "cluster.set", name))
+}
+
+// renderClusterList формирует список кластеров с отметкой активного
+func renderClusterList(lang Lang, names []string, active string) *Rich {
+	r := NewRich()
+	r.Text("☸️ ").Bold(T(lang, "cluster.title")).Line()
+	for _, n := range names {
+		if n == active {
+			r.Text("✅ ").Code(n).Text(" " + T(lang, "cluster.active")).Line()
+			continue
+		}
+		r.Text("▫️ ").Code(n).Line()
+	}
+	if len(names) > 1 {
+		r.Line().Text(T(lang, "usage.cluster"))
+	}
+	return r
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// fakeListKinds списки CRD, которые бот читает через dynamic-клиент
+var fakeListKinds = map[schema.GroupVersionResource]string{
+	veleroBackupGVR:   "BackupList",
+	veleroScheduleGVR: "ScheduleList",
+	veleroRestoreGVR:  "RestoreList",
+}
+
+// newFakeCluster кластер на поддельных клиентах: typed — встроенные объекты,
+// dynamic — unstructured CRD, apis — group/version, которые «обслуживает» сервер
+func newFakeCluster(t *testing.T, apis []string, typed []runtime.Object, dynamic ...runtime.Object) *Cluster {
+	t.Helper()
+	c := &Cluster{
+		Name:    "test",
+		Typed:   fake.NewSimpleClientset(typed...),
+		Dynamic: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), fakeListKinds, dynamic...),
+		apis:    make(map[string]bool),
+	}
+	for _, api := range apis {
+		c.apis[api] = true
+	}
+	return c
+}
+
+func TestRefreshCapabilities(t *testing.T) {
+	c := newFakeCluster(t, nil, nil)
+	if c.HasMetrics() {
+		t.Fatal("metrics-server есть до discovery")
+	}
+	discovery := c.Typed.Discovery().(*fakediscovery.FakeDiscovery)
+	discovery.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1"},
+		{GroupVersion: metricsAPI},
+		{GroupVersion: "velero.io/v1"},
+	}
+	if err := c.RefreshCapabilities(); err != nil {
+		t.Fatal(err)
+	}
+	if !c.HasMetrics() || !c.HasAPI("velero.io/v1") || c.HasAPI("kilo.squat.ai/v1alpha1") {
+		t.Errorf("apis после discovery: %v", c.apis)
+	}
+
+	// metrics-server удалили — следующее обновление это замечает
+	discovery.Resources = discovery.Resources[:1]
+	if err := c.RefreshCapabilities(); err != nil {
+		t.Fatal(err)
+	}
+	if c.HasMetrics() {
+		t.Error("metrics-server остался после удаления")
+	}
+}
+
+func TestBuildRestConfigContext(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	err := os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster: {server: "https://10.0.0.1:6443"}
+- name: test
+  cluster: {server: "https://10.0.1.1:6443"}
+users:
+- name: admin
+  user: {token: secret}
+contexts:
+- name: prod
+  context: {cluster: prod, user: admin}
+- name: test
+  context: {cluster: test, user: admin}
+current-context: prod
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for ctx, host := range map[string]string{"": "https://10.0.0.1:6443", "test": "https://10.0.1.1:6443"} {
+		cfg, err := buildRestConfig(ClusterConfig{Kubeconfig: kubeconfig, Context: ctx})
+		if err != nil {
+			t.Fatalf("%q: %v", ctx, err)
+		}
+		if cfg.Host != host || cfg.BearerToken != "secret" {
+			t.Errorf("%q: host %s", ctx, cfg.Host)
+		}
+	}
+	if _, err := buildRestConfig(ClusterConfig{Kubeconfig: kubeconfig, Context: "missing"}); err == nil {
+		t.Error("несуществующий контекст принят")
+	}
+}
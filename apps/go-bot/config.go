@@ -1,6 +1,14 @@
 package main
 
-import "time"
+import (
+	"encoding/json"
+	"log"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Config содержит конфигурацию мониторинга
 type Config struct {
@@ -17,3 +25,715 @@ func DefaultConfig() Config {
 		EnableMonitoring: true,
 	}
 }
+
+// OutputConfig содержит настройки отправки длинного вывода
+type OutputConfig struct {
+	MaxMessageLen int // максимальная длина одного сообщения
+	FileThreshold int // вывод длиннее порога отправляется файлом, короче — несколькими сообщениями
+	GzipThreshold int // файл длиннее порога сжимается gzip
+}
+
+// DefaultOutputConfig возвращает настройки вывода по умолчанию
+func DefaultOutputConfig() OutputConfig {
+	return OutputConfig{
+		MaxMessageLen: MaxMsgLen,
+		FileThreshold: 3 * MaxMsgLen,   // До трёх сообщений подряд
+		GzipThreshold: 5 * 1024 * 1024, // Сжимаем файлы больше 5MB
+	}
+}
+
+// LoadOutputConfig читает настройки вывода из переменных окружения
+func LoadOutputConfig() OutputConfig {
+	cfg := DefaultOutputConfig()
+	if v, err := strconv.Atoi(os.Getenv("OUTPUT_FILE_THRESHOLD")); err == nil && v >= 0 {
+		cfg.FileThreshold = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("OUTPUT_GZIP_THRESHOLD")); err == nil && v > 0 {
+		cfg.GzipThreshold = v
+	}
+	return cfg
+}
+
+// ClusterConfig содержит настройки подключения к Kubernetes
+type ClusterConfig struct {
+	Kubeconfig        string        // явный путь к kubeconfig, иначе in-cluster или KUBECONFIG
+	Context           string        // контекст kubeconfig
+	Clusters          []ClusterSpec // несколько кластеров по контекстам kubeconfig, пусто — один кластер
+	QPS               float32
+	Burst             int
+	DiscoveryInterval time.Duration // период обновления списка API (metrics-server и CRD)
+}
+
+// ClusterSpec имя кластера в боте и контекст kubeconfig, "in-cluster" — сервисный аккаунт pod-а
+type ClusterSpec struct {
+	Name    string
+	Context string
+}
+
+// DefaultClusterConfig возвращает настройки подключения по умолчанию
+func DefaultClusterConfig() ClusterConfig {
+	return ClusterConfig{
+		QPS:               20,
+		Burst:             40,
+		DiscoveryInterval: 5 * time.Minute,
+	}
+}
+
+// LoadClusterConfig читает настройки подключения из переменных окружения
+func LoadClusterConfig() ClusterConfig {
+	cfg := DefaultClusterConfig()
+	cfg.Kubeconfig = os.Getenv("KUBECONFIG_PATH")
+	cfg.Context = os.Getenv("KUBE_CONTEXT")
+	cfg.Clusters = parseClusterSpecs(os.Getenv("KUBE_CLUSTERS"))
+	if v, err := strconv.ParseFloat(os.Getenv("KUBE_QPS"), 32); err == nil && v > 0 {
+		cfg.QPS = float32(v)
+	}
+	if v, err := strconv.Atoi(os.Getenv("KUBE_BURST")); err == nil && v > 0 {
+		cfg.Burst = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("KUBE_DISCOVERY_INTERVAL")); err == nil && v > 0 {
+		cfg.DiscoveryInterval = v
+	}
+	return cfg
+}
+
+// parseClusterSpecs разбирает KUBE_CLUSTERS: "prod=in-cluster,test=k3s-test" или "k3s-prod,k3s-test"
+func parseClusterSpecs(v string) []ClusterSpec {
+	var specs []ClusterSpec
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, context, ok := strings.Cut(item, "=")
+		if !ok {
+			context = name
+		}
+		specs = append(specs, ClusterSpec{Name: strings.TrimSpace(name), Context: strings.TrimSpace(context)})
+	}
+	return specs
+}
+
+// MetricsConfig содержит настройки хранения истории потребления для /chart
+type MetricsConfig struct {
+	SampleInterval time.Duration // период снятия метрик
+	Retention      time.Duration // глубина истории, задаёт размер кольцевого буфера
+	FlushInterval  time.Duration // период сохранения на диск
+	Path           string        // файл истории, пусто — только в памяти
+}
+
+// DefaultMetricsConfig возвращает настройки истории по умолчанию
+func DefaultMetricsConfig() MetricsConfig {
+	return MetricsConfig{
+		SampleInterval: time.Minute,
+		Retention:      24 * time.Hour,
+		FlushInterval:  10 * time.Minute,
+	}
+}
+
+// LoadMetricsConfig читает настройки истории из переменных окружения
+func LoadMetricsConfig() MetricsConfig {
+	cfg := DefaultMetricsConfig()
+	cfg.Path = os.Getenv("METRICS_STORE_PATH")
+	if v, err := time.ParseDuration(os.Getenv("METRICS_SAMPLE_INTERVAL")); err == nil && v > 0 {
+		cfg.SampleInterval = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("METRICS_RETENTION")); err == nil && v > 0 {
+		cfg.Retention = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("METRICS_FLUSH_INTERVAL")); err == nil && v > 0 {
+		cfg.FlushInterval = v
+	}
+	return cfg
+}
+
+// SavedQuery именованный PromQL-запрос для /q; Range задаёт график вместо таблицы
+type SavedQuery struct {
+	Expr  string `json:"expr"`
+	Range string `json:"range,omitempty"`
+}
+
+// PrometheusConfig содержит настройки доступа к Prometheus для /promql и /q
+type PrometheusConfig struct {
+	URL              string        // адрес Prometheus, пусто — команды отключены
+	Timeout          time.Duration // таймаут одного запроса
+	MaxSeries        int           // сколько рядов показывать в таблице
+	MaxChartSeries   int           // сколько рядов рисовать на графике
+	MaxResponseBytes int64         // ответ больше лимита отбрасывается
+	DefaultRange     time.Duration
+	Queries          map[string]SavedQuery
+}
+
+// DefaultPrometheusConfig возвращает настройки Prometheus по умолчанию со встроенными запросами
+func DefaultPrometheusConfig() PrometheusConfig {
+	return PrometheusConfig{
+		Timeout:          15 * time.Second,
+		MaxSeries:        30,
+		MaxChartSeries:   8,
+		MaxResponseBytes: 4 * 1024 * 1024,
+		DefaultRange:     time.Hour,
+		Queries: map[string]SavedQuery{
+			"disk_free":    {Expr: `100 * node_filesystem_avail_bytes{fstype!~"tmpfs|overlay|squashfs"} / node_filesystem_size_bytes{fstype!~"tmpfs|overlay|squashfs"}`},
+			"mem_free":     {Expr: `100 * node_memory_MemAvailable_bytes / node_memory_MemTotal_bytes`},
+			"load":         {Expr: `node_load5`},
+			"cpu":          {Expr: `100 - avg by (instance) (rate(node_cpu_seconds_total{mode="idle"}[5m])) * 100`, Range: "6h"},
+			"restarts":     {Expr: `topk(10, increase(kube_pod_container_status_restarts_total[1h])) > 0`},
+			"targets_down": {Expr: `up == 0`},
+		},
+	}
+}
+
+// LoadPrometheusConfig читает настройки Prometheus из переменных окружения;
+// PROMETHEUS_QUERIES_FILE (JSON name -> {expr, range}) дополняет и переопределяет встроенные запросы
+func LoadPrometheusConfig() PrometheusConfig {
+	cfg := DefaultPrometheusConfig()
+	cfg.URL = strings.TrimRight(os.Getenv("PROMETHEUS_URL"), "/")
+	if v, err := time.ParseDuration(os.Getenv("PROMETHEUS_TIMEOUT")); err == nil && v > 0 {
+		cfg.Timeout = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("PROMETHEUS_MAX_SERIES")); err == nil && v > 0 {
+		cfg.MaxSeries = v
+	}
+	if path := os.Getenv("PROMETHEUS_QUERIES_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("⚠️ Не удалось прочитать сохранённые запросы %s: %v", path, err)
+			return cfg
+		}
+		var queries map[string]SavedQuery
+		if err := json.Unmarshal(data, &queries); err != nil {
+			log.Printf("⚠️ Некорректный файл сохранённых запросов %s: %v", path, err)
+			return cfg
+		}
+		for name, q := range queries {
+			cfg.Queries[name] = q
+		}
+	}
+	return cfg
+}
+
+// LokiConfig содержит настройки доступа к Loki для /loki и /logs-history
+type LokiConfig struct {
+	URL              string        // адрес Loki, пусто — команды отключены
+	Timeout          time.Duration // таймаут одного запроса
+	Limit            int           // максимум строк в ответе
+	MaxResponseBytes int64         // ответ больше лимита отбрасывается
+	DefaultSince     time.Duration
+	AppLabel         string // метка приложения в потоках promtail для /logs-history
+}
+
+// DefaultLokiConfig возвращает настройки Loki по умолчанию
+func DefaultLokiConfig() LokiConfig {
+	return LokiConfig{
+		Timeout:          20 * time.Second,
+		Limit:            2000,
+		MaxResponseBytes: 16 * 1024 * 1024,
+		DefaultSince:     time.Hour,
+		AppLabel:         "app",
+	}
+}
+
+// LoadLokiConfig читает настройки Loki из переменных окружения
+func LoadLokiConfig() LokiConfig {
+	cfg := DefaultLokiConfig()
+	cfg.URL = strings.TrimRight(os.Getenv("LOKI_URL"), "/")
+	if v, err := time.ParseDuration(os.Getenv("LOKI_TIMEOUT")); err == nil && v > 0 {
+		cfg.Timeout = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("LOKI_LIMIT")); err == nil && v > 0 {
+		cfg.Limit = v
+	}
+	if v := os.Getenv("LOKI_APP_LABEL"); v != "" {
+		cfg.AppLabel = v
+	}
+	return cfg
+}
+
+// DigestSchedule именованное расписание дайджеста
+type DigestSchedule struct {
+	Name string
+	Spec string
+	cron *cronSchedule
+}
+
+// DigestConfig настройки плановых сводок в чат администратора
+type DigestConfig struct {
+	Schedules       []DigestSchedule
+	Location        *time.Location // часовой пояс расписаний
+	StatePath       string         // файл с предыдущими сводками для раздела изменений; пусто — только в памяти
+	TopRestarts     int
+	CertWarn        time.Duration // сертификаты, истекающие раньше, попадают в сводку
+	DiskWarnPercent float64       // диски со свободным местом ниже порога помечаются
+	Timeout         time.Duration // сбор данных одного кластера
+}
+
+// DefaultDigestConfig возвращает настройки дайджеста по умолчанию: ежедневно в 09:00
+func DefaultDigestConfig() DigestConfig {
+	return DigestConfig{
+		Schedules:       []DigestSchedule{{Name: "daily", Spec: "0 9 * * *"}},
+		Location:        time.Local,
+		TopRestarts:     5,
+		CertWarn:        14 * 24 * time.Hour,
+		DiskWarnPercent: 15,
+		Timeout:         2 * time.Minute,
+	}
+}
+
+// LoadDigestConfig читает настройки дайджеста из переменных окружения.
+// DIGEST_SCHEDULES: "daily=0 9 * * *;weekly=30 8 * * 1", "off" отключает рассылку
+func LoadDigestConfig() DigestConfig {
+	cfg := DefaultDigestConfig()
+	if v := strings.TrimSpace(os.Getenv("DIGEST_SCHEDULES")); v != "" {
+		cfg.Schedules = parseDigestSchedules(v)
+	}
+	for i := range cfg.Schedules {
+		s := &cfg.Schedules[i]
+		c, err := parseCron(s.Spec)
+		if err != nil {
+			log.Printf("⚠️ Расписание дайджеста %s пропущено: %v", s.Name, err)
+			continue
+		}
+		s.cron = c
+	}
+	if v := os.Getenv("DIGEST_TZ"); v != "" {
+		if loc, err := time.LoadLocation(v); err == nil {
+			cfg.Location = loc
+		} else {
+			log.Printf("⚠️ Некорректный DIGEST_TZ %q: %v", v, err)
+		}
+	}
+	cfg.StatePath = os.Getenv("DIGEST_STATE_FILE")
+	if v, err := strconv.Atoi(os.Getenv("DIGEST_TOP_RESTARTS")); err == nil && v > 0 {
+		cfg.TopRestarts = v
+	}
+	if v, err := parsePeriod(os.Getenv("DIGEST_CERT_WARN")); err == nil {
+		cfg.CertWarn = v
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("DIGEST_DISK_WARN_PERCENT"), 64); err == nil && v > 0 {
+		cfg.DiskWarnPercent = v
+	}
+	return cfg
+}
+
+// parseDigestSchedules разбирает список "имя=cron" через точку с запятой;
+// выражение без имени получает имя digest
+func parseDigestSchedules(v string) []DigestSchedule {
+	if v == "off" {
+		return nil
+	}
+	var out []DigestSchedule
+	for _, item := range strings.Split(v, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, spec, ok := strings.Cut(item, "=")
+		if !ok {
+			name, spec = "digest", item
+		}
+		out = append(out, DigestSchedule{Name: strings.TrimSpace(name), Spec: strings.TrimSpace(spec)})
+	}
+	return out
+}
+
+// VeleroConfig настройки отслеживания бэкапов Velero
+type VeleroConfig struct {
+	Namespace    string        // namespace Velero, в нём создаются Backup и Restore
+	PollInterval time.Duration // период проверки бэкапов и расписаний
+	MissedGrace  time.Duration // расписание считается пропущенным, если бэкапа нет дольше этого после срока
+	BackupTTL    string        // срок хранения бэкапов /backup now, пусто — по умолчанию Velero
+}
+
+// DefaultVeleroConfig возвращает настройки Velero по умолчанию
+func DefaultVeleroConfig() VeleroConfig {
+	return VeleroConfig{
+		Namespace:    "velero",
+		PollInterval: 5 * time.Minute,
+		MissedGrace:  time.Hour,
+	}
+}
+
+// LoadVeleroConfig читает настройки Velero из переменных окружения
+func LoadVeleroConfig() VeleroConfig {
+	cfg := DefaultVeleroConfig()
+	if v := os.Getenv("VELERO_NAMESPACE"); v != "" {
+		cfg.Namespace = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("VELERO_POLL_INTERVAL")); err == nil && v > 0 {
+		cfg.PollInterval = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("VELERO_MISSED_GRACE")); err == nil && v > 0 {
+		cfg.MissedGrace = v
+	}
+	cfg.BackupTTL = os.Getenv("VELERO_BACKUP_TTL")
+	return cfg
+}
+
+// CertConfig настройки отслеживания сертификатов
+type CertConfig struct {
+	WarnWithin   time.Duration // предупреждать, если до notAfter осталось меньше
+	PollInterval time.Duration
+	ScanSecrets  bool // проверять TLS-секреты, которыми не управляет cert-manager
+}
+
+// DefaultCertConfig возвращает настройки сертификатов по умолчанию
+func DefaultCertConfig() CertConfig {
+	return CertConfig{
+		WarnWithin:   14 * 24 * time.Hour,
+		PollInterval: time.Hour,
+		ScanSecrets:  true,
+	}
+}
+
+// LoadCertConfig читает настройки сертификатов из переменных окружения
+func LoadCertConfig() CertConfig {
+	cfg := DefaultCertConfig()
+	if v, err := parsePeriod(os.Getenv("CERT_WARN")); err == nil {
+		cfg.WarnWithin = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("CERT_POLL_INTERVAL")); err == nil && v > 0 {
+		cfg.PollInterval = v
+	}
+	if os.Getenv("CERT_SCAN_SECRETS") == "false" {
+		cfg.ScanSecrets = false
+	}
+	return cfg
+}
+
+// UpgradeConfig настройки отслеживания обновлений k3s через system-upgrade-controller
+type UpgradeConfig struct {
+	Namespace     string
+	PollInterval  time.Duration
+	SuppressGrace time.Duration // сколько ещё молчать по узлу после завершения Job
+	LogTail       int64         // строк логов упавшего Job
+}
+
+// DefaultUpgradeConfig возвращает настройки обновлений по умолчанию
+func DefaultUpgradeConfig() UpgradeConfig {
+	return UpgradeConfig{
+		Namespace:     "system-upgrade",
+		PollInterval:  30 * time.Second,
+		SuppressGrace: 10 * time.Minute,
+		LogTail:       50,
+	}
+}
+
+// LoadUpgradeConfig читает настройки обновлений из переменных окружения
+func LoadUpgradeConfig() UpgradeConfig {
+	cfg := DefaultUpgradeConfig()
+	if v := os.Getenv("UPGRADE_NAMESPACE"); v != "" {
+		cfg.Namespace = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("UPGRADE_POLL_INTERVAL")); err == nil && v > 0 {
+		cfg.PollInterval = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("UPGRADE_SUPPRESS_GRACE")); err == nil && v >= 0 {
+		cfg.SuppressGrace = v
+	}
+	if v, err := strconv.ParseInt(os.Getenv("UPGRADE_LOG_TAIL"), 10, 64); err == nil && v > 0 {
+		cfg.LogTail = v
+	}
+	return cfg
+}
+
+// VPNConfig настройки выдачи доступа через Peer-ы Kilo
+type VPNConfig struct {
+	Range      netip.Prefix // диапазон адресов клиентов
+	Keepalive  int          // PersistentKeepalive в секундах, 0 — отключён
+	DNS        string       // DNS в конфиге клиента
+	AllowedIPs []string     // дополнительные сети, например сервисы кластера
+}
+
+// DefaultVPNConfig возвращает настройки VPN по умолчанию
+func DefaultVPNConfig() VPNConfig {
+	return VPNConfig{
+		Range:     netip.MustParsePrefix("10.5.0.0/24"),
+		Keepalive: 10,
+	}
+}
+
+// LoadVPNConfig читает настройки VPN из переменных окружения
+func LoadVPNConfig() VPNConfig {
+	cfg := DefaultVPNConfig()
+	if v := os.Getenv("VPN_RANGE"); v != "" {
+		if p, err := netip.ParsePrefix(v); err == nil && p.Addr().Is4() {
+			cfg.Range = p.Masked()
+		} else {
+			log.Printf("⚠️ Некорректный VPN_RANGE %q, используется %s", v, cfg.Range)
+		}
+	}
+	if v, err := strconv.Atoi(os.Getenv("VPN_KEEPALIVE")); err == nil && v >= 0 {
+		cfg.Keepalive = v
+	}
+	cfg.DNS = os.Getenv("VPN_DNS")
+	for _, item := range strings.Split(os.Getenv("VPN_ALLOWED_IPS"), ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		if _, err := netip.ParsePrefix(item); err != nil {
+			log.Printf("⚠️ Некорректная сеть %q в VPN_ALLOWED_IPS: %v", item, err)
+			continue
+		}
+		cfg.AllowedIPs = append(cfg.AllowedIPs, item)
+	}
+	return cfg
+}
+
+// MinIOConfig настройки опроса MinIO
+type MinIOConfig struct {
+	URL              string        // адрес S3 API, пусто — /storage и алерты отключены
+	Token            string        // bearer-токен метрик (mc admin prometheus generate)
+	Timeout          time.Duration // таймаут одного запроса
+	PollInterval     time.Duration
+	MaxResponseBytes int64
+	FreeWarnPercent  float64 // алерт, если свободно меньше, процентов
+	Cluster          string  // кластер с PVC MinIO, пусто — первый
+	PVC              string  // namespace/имя PVC с данными, пусто — не проверять
+}
+
+// DefaultMinIOConfig возвращает настройки MinIO по умолчанию (kuber/minio)
+func DefaultMinIOConfig() MinIOConfig {
+	return MinIOConfig{
+		Timeout:          10 * time.Second,
+		PollInterval:     5 * time.Minute,
+		MaxResponseBytes: 8 * 1024 * 1024,
+		FreeWarnPercent:  10,
+		PVC:              "minio-system/minio-pvc",
+	}
+}
+
+// LoadMinIOConfig читает настройки MinIO из переменных окружения
+func LoadMinIOConfig() MinIOConfig {
+	cfg := DefaultMinIOConfig()
+	cfg.URL = strings.TrimRight(os.Getenv("MINIO_URL"), "/")
+	cfg.Token = os.Getenv("MINIO_METRICS_TOKEN")
+	if v, err := time.ParseDuration(os.Getenv("MINIO_TIMEOUT")); err == nil && v > 0 {
+		cfg.Timeout = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("MINIO_POLL_INTERVAL")); err == nil && v > 0 {
+		cfg.PollInterval = v
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("MINIO_FREE_WARN_PERCENT"), 64); err == nil && v >= 0 && v < 100 {
+		cfg.FreeWarnPercent = v
+	}
+	cfg.Cluster = os.Getenv("MINIO_CLUSTER")
+	if v, ok := os.LookupEnv("MINIO_PVC"); ok {
+		cfg.PVC = v
+	}
+	return cfg
+}
+
+// VolumeConfig пороги заполнения PVC и проверки PV
+type VolumeConfig struct {
+	PollInterval      time.Duration
+	WarnPercent       float64       // занято больше — предупреждение
+	CritPercent       float64       // занято больше — критично
+	InodesWarnPercent float64       // занято inode больше — предупреждение
+	PendingGrace      time.Duration // сколько PVC может висеть в Pending без алерта
+}
+
+// DefaultVolumeConfig возвращает пороги томов по умолчанию
+func DefaultVolumeConfig() VolumeConfig {
+	return VolumeConfig{
+		PollInterval:      5 * time.Minute,
+		WarnPercent:       80,
+		CritPercent:       90,
+		InodesWarnPercent: 90,
+		PendingGrace:      10 * time.Minute,
+	}
+}
+
+// LoadVolumeConfig читает пороги томов из переменных окружения
+func LoadVolumeConfig() VolumeConfig {
+	cfg := DefaultVolumeConfig()
+	if v, err := time.ParseDuration(os.Getenv("PVC_POLL_INTERVAL")); err == nil && v > 0 {
+		cfg.PollInterval = v
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("PVC_WARN_PERCENT"), 64); err == nil && v > 0 && v <= 100 {
+		cfg.WarnPercent = v
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("PVC_CRIT_PERCENT"), 64); err == nil && v > 0 && v <= 100 {
+		cfg.CritPercent = v
+	}
+	if cfg.CritPercent < cfg.WarnPercent {
+		cfg.CritPercent = cfg.WarnPercent
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("PVC_INODES_WARN_PERCENT"), 64); err == nil && v > 0 && v <= 100 {
+		cfg.InodesWarnPercent = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("PVC_PENDING_GRACE")); err == nil && v >= 0 {
+		cfg.PendingGrace = v
+	}
+	return cfg
+}
+
+// DrainConfig настройки обслуживания узлов через /drain
+type DrainConfig struct {
+	Timeout    time.Duration // сколько ждать вытеснения подов по умолчанию
+	SilenceFor time.Duration // сколько молчать по выведенному узлу, если не было /uncordon
+}
+
+// DefaultDrainConfig возвращает настройки /drain по умолчанию
+func DefaultDrainConfig() DrainConfig {
+	return DrainConfig{
+		Timeout:    5 * time.Minute,
+		SilenceFor: 24 * time.Hour,
+	}
+}
+
+// LoadDrainConfig читает настройки /drain из переменных окружения
+func LoadDrainConfig() DrainConfig {
+	cfg := DefaultDrainConfig()
+	if v, err := time.ParseDuration(os.Getenv("DRAIN_TIMEOUT")); err == nil && v > 0 {
+		cfg.Timeout = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("DRAIN_SILENCE")); err == nil && v > 0 {
+		cfg.SilenceFor = v
+	}
+	return cfg
+}
+
+// JobConfig настройки наблюдения за Job-ами и CronJob-ами
+type JobConfig struct {
+	Namespace    string // пусто — все namespace
+	PollInterval time.Duration
+	LogTail      int64         // строк логов упавшего Job
+	CronGrace    time.Duration // сколько ждать успешного запуска после времени по расписанию
+}
+
+// DefaultJobConfig возвращает настройки Job-ов по умолчанию
+func DefaultJobConfig() JobConfig {
+	return JobConfig{
+		PollInterval: time.Minute,
+		LogTail:      30,
+		CronGrace:    30 * time.Minute,
+	}
+}
+
+// LoadJobConfig читает настройки Job-ов из переменных окружения
+func LoadJobConfig() JobConfig {
+	cfg := DefaultJobConfig()
+	cfg.Namespace = os.Getenv("JOBS_NAMESPACE")
+	if v, err := time.ParseDuration(os.Getenv("JOBS_POLL_INTERVAL")); err == nil && v > 0 {
+		cfg.PollInterval = v
+	}
+	if v, err := strconv.ParseInt(os.Getenv("JOBS_LOG_TAIL"), 10, 64); err == nil && v > 0 {
+		cfg.LogTail = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("CRONJOB_GRACE")); err == nil && v >= 0 {
+		cfg.CronGrace = v
+	}
+	return cfg
+}
+
+// CleanupConfig настройки /cleanup и плановой очистки кластера
+type CleanupConfig struct {
+	JobAge   time.Duration // успешные Job-ы старше удаляются
+	Schedule string        // cron-выражение плановой очистки; пусто — только вручную
+	Location *time.Location
+	cron     *cronSchedule
+}
+
+// DefaultCleanupConfig возвращает настройки очистки по умолчанию
+func DefaultCleanupConfig() CleanupConfig {
+	return CleanupConfig{
+		JobAge:   24 * time.Hour,
+		Location: time.Local,
+	}
+}
+
+// LoadCleanupConfig читает настройки очистки из переменных окружения
+func LoadCleanupConfig() CleanupConfig {
+	cfg := DefaultCleanupConfig()
+	if v, err := time.ParseDuration(os.Getenv("CLEANUP_JOB_AGE")); err == nil && v >= 0 {
+		cfg.JobAge = v
+	}
+	if v := strings.TrimSpace(os.Getenv("CLEANUP_SCHEDULE")); v != "" {
+		c, err := parseCron(v)
+		if err != nil {
+			log.Printf("⚠️ Расписание очистки пропущено: %v", err)
+		} else {
+			cfg.Schedule, cfg.cron = v, c
+		}
+	}
+	if v := os.Getenv("CLEANUP_TZ"); v != "" {
+		if loc, err := time.LoadLocation(v); err == nil {
+			cfg.Location = loc
+		} else {
+			log.Printf("⚠️ Некорректный CLEANUP_TZ %q: %v", v, err)
+		}
+	}
+	return cfg
+}
+
+// ProbeConfig настройки синтетических HTTP-проверок Ingress-ов и сервисов
+type ProbeConfig struct {
+	DiscoverIngress  bool // проверять хосты Ingress-ов, кроме помеченных go-bot/probe: "false"
+	DiscoverServices bool // проверять сервисы с аннотацией go-bot/probe: "true"
+	Targets          []probeTarget
+	PollInterval     time.Duration
+	Timeout          time.Duration // таймаут одного запроса
+	FailThreshold    int           // неудач подряд до алерта
+	Latency          time.Duration // дольше — проверка не пройдена
+	TLSMinValidity   time.Duration // сертификат, истекающий раньше, считается ошибкой
+	Parallel         int
+}
+
+// DefaultProbeConfig возвращает настройки проверок по умолчанию
+func DefaultProbeConfig() ProbeConfig {
+	return ProbeConfig{
+		DiscoverIngress:  true,
+		DiscoverServices: true,
+		PollInterval:     time.Minute,
+		Timeout:          10 * time.Second,
+		FailThreshold:    3,
+		Latency:          3 * time.Second,
+		TLSMinValidity:   24 * time.Hour,
+		Parallel:         8,
+	}
+}
+
+// LoadProbeConfig читает настройки проверок из переменных окружения.
+// PROBE_DISCOVER: "ingress,services", "ingress" или "off";
+// PROBE_TARGETS: "name=homer url=https://home.example.com status=200 body=Homer latency=1s; ..."
+func LoadProbeConfig() ProbeConfig {
+	cfg := DefaultProbeConfig()
+	if v, ok := os.LookupEnv("PROBE_DISCOVER"); ok {
+		cfg.DiscoverIngress, cfg.DiscoverServices = false, false
+		for _, item := range strings.Split(v, ",") {
+			switch strings.TrimSpace(item) {
+			case "ingress":
+				cfg.DiscoverIngress = true
+			case "services":
+				cfg.DiscoverServices = true
+			}
+		}
+	}
+	for _, entry := range strings.Split(os.Getenv("PROBE_TARGETS"), ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		t, err := parseProbeTarget(entry)
+		if err != nil {
+			log.Printf("⚠️ Проверка %q пропущена: %v", strings.TrimSpace(entry), err)
+			continue
+		}
+		cfg.Targets = append(cfg.Targets, t)
+	}
+	if v, err := time.ParseDuration(os.Getenv("PROBE_INTERVAL")); err == nil && v > 0 {
+		cfg.PollInterval = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("PROBE_TIMEOUT")); err == nil && v > 0 {
+		cfg.Timeout = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("PROBE_FAIL_THRESHOLD")); err == nil && v > 0 {
+		cfg.FailThreshold = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("PROBE_LATENCY")); err == nil && v > 0 {
+		cfg.Latency = v
+	}
+	if v, err := parsePeriod(os.Getenv("PROBE_TLS_MIN_VALIDITY")); err == nil {
+		cfg.TLSMinValidity = v
+	}
+	return cfg
+}
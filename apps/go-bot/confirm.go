@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// confirmTTL сколько действие ждёт подтверждения
+const confirmTTL = 5 * time.Minute
+
+// pendingAction действие, которое выполнится после нажатия «Подтвердить»
+type pendingAction struct {
+	ChatID  int64
+	UserID  int64 // если задан, подтвердить может только этот пользователь
+	Run     func(ctx context.Context) (*Rich, error)
+	Created time.Time
+}
+
+// pendingActions действия, ожидающие подтверждения, по коротким идентификаторам
+var pendingActions = struct {
+	sync.Mutex
+	items map[string]pendingAction
+}{items: make(map[string]pendingAction)}
+
+// shortID случайный идентификатор для callback_data
+func shortID() string {
+	buf := make([]byte, 4)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// askUserConfirmation отправляет описание действия с кнопками подтверждения и отмены;
+// нажать кнопки может только автор команды
+func askUserConfirmation(bot *tgbotapi.BotAPI, chatID, userID int64, prompt *Rich, run func(ctx context.Context) (*Rich, error)) {
+	id := shortID()
+	now := time.Now()
+
+	pendingActions.Lock()
+	for k, v := range pendingActions.items {
+		if now.Sub(v.Created) > confirmTTL {
+			delete(pendingActions.items, k)
+		}
+	}
+	pendingActions.items[id] = pendingAction{ChatID: chatID, UserID: userID, Run: run, Created: now}
+	pendingActions.Unlock()
+
+	lang := langFor(chatID)
+	markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(T(lang, "btn.confirm"), "confirm "+id),
+		tgbotapi.NewInlineKeyboardButtonData(T(lang, "btn.cancel"), "cancel "+id),
+	))
+	prompt.Line().Line().Italic(T(lang, "confirm.expires", formatDuration(lang, confirmTTL)))
+	sendRichMarkup(bot, chatID, prompt, markup)
+}
+
+// handleConfirm выполняет или отменяет действие по кнопке и заменяет вопрос результатом.
+// Действие забирается из хранилища сразу, поэтому повторное нажатие ничего не делает.
+// Нажатие чужой кнопки действие не забирает
+func handleConfirm(bot *tgbotapi.BotAPI, ctx context.Context, chatID, userID int64, messageID int, id string, approved bool) {
+	lang := langFor(chatID)
+	pendingActions.Lock()
+	action, ok := pendingActions.items[id]
+	if ok && action.ChatID == chatID && action.UserID != 0 && action.UserID != userID {
+		pendingActions.Unlock()
+		sendText(bot, chatID, T(lang, "confirm.not_yours"))
+		return
+	}
+	if ok && action.ChatID == chatID {
+		delete(pendingActions.items, id)
+	}
+	pendingActions.Unlock()
+
+	if !ok || action.ChatID != chatID || time.Since(action.Created) > confirmTTL {
+		editRich(bot, chatID, messageID, NewRich().Text(T(lang, "confirm.expired")), nil)
+		return
+	}
+	if !approved {
+		editRich(bot, chatID, messageID, NewRich().Text(T(lang, "confirm.cancelled")), nil)
+		return
+	}
+
+	result, err := action.Run(ctx)
+	if err != nil {
+		log.Printf("❌ Ошибка подтверждённого действия: %v", err)
+		editRich(bot, chatID, messageID, NewRich().Text(T(lang, "error", err)), nil)
+		telemetry.userErrors.Add(1)
+		return
+	}
+	editRich(bot, chatID, messageID, result, nil)
+}
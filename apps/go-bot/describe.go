@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// describeEventsLimit сколько последних событий pod-а показывать
+const describeEventsLimit = 10
+
+// handleDescribe показывает аналог kubectl describe pod с кнопками перехода к логам
+func handleDescribe(bot *tgbotapi.BotAPI, clientset kubernetes.Interface, ctx context.Context, chatID int64, ns, name string) {
+	lang := langFor(chatID)
+	pod, err := clientset.CoreV1().Pods(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+
+	events, err := listPodEvents(ctx, clientset, pod)
+	if err != nil {
+		// События не критичны для описания
+		log.Printf("⚠️ Не удалось получить события %s/%s: %v", ns, name, err)
+	}
+
+	var buttons []tgbotapi.InlineKeyboardButton
+	if data := fmt.Sprintf("logs %s %s", ns, name); len(data) <= maxCallbackData {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(T(lang, "btn.logs"), data))
+	}
+	if data := fmt.Sprintf("logs %s %s --previous", ns, name); len(data) <= maxCallbackData {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(T(lang, "btn.logs_previous"), data))
+	}
+
+	r := renderPodDescribe(lang, pod, events, time.Now())
+	if len(buttons) == 0 || len(r.String()) > outputConfig.MaxMessageLen {
+		// Длинное описание уходит частями, кнопки — отдельным сообщением
+		sendLongRich(bot, chatID, "describe-"+ns+"-"+name, r)
+		if len(buttons) == 0 {
+			return
+		}
+		r = NewRich().Text(T(lang, "describe.actions"))
+	}
+	sendRichMarkup(bot, chatID, r, tgbotapi.NewInlineKeyboardMarkup(buttons))
+}
+
+// listPodEvents возвращает события pod-а, от старых к новым
+func listPodEvents(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod) ([]corev1.Event, error) {
+	sel := fields.AndSelectors(
+		fields.OneTermEqualSelector("involvedObject.kind", "Pod"),
+		fields.OneTermEqualSelector("involvedObject.name", pod.Name),
+	)
+	list, err := clientset.CoreV1().Events(pod.Namespace).List(ctx, metav1.ListOptions{FieldSelector: sel.String()})
+	if err != nil {
+		return nil, err
+	}
+	var events []corev1.Event
+	for _, e := range list.Items {
+		// Отбрасываем события прежнего pod-а с тем же именем
+		if e.InvolvedObject.UID != "" && e.InvolvedObject.UID != pod.UID {
+			continue
+		}
+		events = append(events, e)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+	return events, nil
+}
+
+// eventTime время последнего появления события
+func eventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+// renderPodDescribe формирует описание pod-а
+func renderPodDescribe(lang Lang, pod *corev1.Pod, events []corev1.Event, now time.Time) *Rich {
+	r := NewRich()
+	r.Text("🔍 Pod ").Bold(pod.Name).Line()
+	r.Text(T(lang, "describe.namespace", pod.Namespace)).Line()
+	r.Text(T(lang, "describe.node", orDash(pod.Spec.NodeName))).Line()
+	r.Text(T(lang, "describe.status", podDisplayStatus(*pod), pod.Status.Phase)).Line()
+	if pod.Status.PodIP != "" {
+		r.Text(T(lang, "describe.ip", pod.Status.PodIP)).Line()
+	}
+	if pod.Status.QOSClass != "" {
+		r.Text(T(lang, "describe.qos", pod.Status.QOSClass)).Line()
+	}
+	if pod.Status.StartTime != nil {
+		r.Text(T(lang, "describe.started", formatDuration(lang, now.Sub(pod.Status.StartTime.Time)))).Line()
+	}
+	for _, ref := range pod.OwnerReferences {
+		r.Text(T(lang, "describe.owner", ref.Kind+"/"+ref.Name)).Line()
+	}
+	if pod.Status.Message != "" {
+		r.Text("⚠️ " + pod.Status.Message).Line()
+	}
+
+	if len(pod.Status.Conditions) > 0 {
+		r.Line().Bold(T(lang, "describe.conditions")).Line()
+		for _, c := range pod.Status.Conditions {
+			emoji := "✅"
+			if c.Status != corev1.ConditionTrue {
+				emoji = "❌"
+			}
+			r.Textf("  %s %s", emoji, c.Type)
+			if c.Reason != "" {
+				r.Text(": " + c.Reason)
+			}
+			if c.Message != "" {
+				r.Text(" — " + c.Message)
+			}
+			r.Line()
+		}
+	}
+
+	r.Line().Bold(T(lang, "describe.containers")).Line()
+	statuses := make(map[string]corev1.ContainerStatus)
+	for _, cs := range pod.Status.InitContainerStatuses {
+		statuses["init:"+cs.Name] = cs
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		statuses[cs.Name] = cs
+	}
+	for _, c := range pod.Spec.InitContainers {
+		renderContainer(r, lang, "init:"+c.Name, c, statuses["init:"+c.Name], now)
+	}
+	for _, c := range pod.Spec.Containers {
+		renderContainer(r, lang, c.Name, c, statuses[c.Name], now)
+	}
+
+	if len(pod.Spec.Volumes) > 0 {
+		r.Line().Bold(T(lang, "describe.volumes")).Line()
+		for _, v := range pod.Spec.Volumes {
+			r.Text("  • ").Code(v.Name).Text(": " + describeVolumeSource(v)).Line()
+		}
+	}
+
+	r.Line().Bold(T(lang, "describe.events", describeEventsLimit)).Line()
+	if len(events) == 0 {
+		r.Text("  " + T(lang, "describe.no_events")).Line()
+	}
+	if len(events) > describeEventsLimit {
+		events = events[len(events)-describeEventsLimit:]
+	}
+	for _, e := range events {
+		emoji := "ℹ️"
+		if e.Type == corev1.EventTypeWarning {
+			emoji = "⚠️"
+		}
+		count := ""
+		if e.Count > 1 {
+			count = fmt.Sprintf(" (x%d)", e.Count)
+		}
+		r.Textf("  %s %s ", emoji, formatDuration(lang, now.Sub(eventTime(e)))).
+			Bold(e.Reason).
+			Text(count + ": " + strings.TrimSpace(e.Message)).Line()
+	}
+	return r
+}
+
+// renderContainer добавляет описание контейнера: состояние, последнее завершение, ресурсы
+func renderContainer(r *Rich, lang Lang, name string, c corev1.Container, cs corev1.ContainerStatus, now time.Time) {
+	emoji := "🔴"
+	if cs.Ready {
+		emoji = "🟢"
+	}
+	r.Text(emoji + " ").Bold(name).Text(" ").Code(c.Image).Line()
+
+	switch {
+	case cs.State.Running != nil:
+		r.Text("   " + T(lang, "describe.state_running", formatDuration(lang, now.Sub(cs.State.Running.StartedAt.Time)))).Line()
+	case cs.State.Waiting != nil:
+		r.Text("   " + T(lang, "describe.state_waiting", joinNonEmpty(": ", cs.State.Waiting.Reason, cs.State.Waiting.Message))).Line()
+	case cs.State.Terminated != nil:
+		t := cs.State.Terminated
+		r.Text("   " + T(lang, "describe.state_terminated", joinNonEmpty(": ", t.Reason, t.Message), t.ExitCode)).Line()
+	}
+	if t := cs.LastTerminationState.Terminated; t != nil {
+		r.Text("   " + T(lang, "describe.last_termination", t.Reason, t.ExitCode, formatDuration(lang, now.Sub(t.FinishedAt.Time)))).Line()
+	}
+	r.Text("   " + T(lang, "describe.ready_restarts", cs.Ready, cs.RestartCount)).Line()
+	if len(c.Resources.Requests) > 0 {
+		r.Text("   " + T(lang, "describe.requests", formatResourceList(c.Resources.Requests))).Line()
+	}
+	if len(c.Resources.Limits) > 0 {
+		r.Text("   " + T(lang, "describe.limits", formatResourceList(c.Resources.Limits))).Line()
+	}
+}
+
+// formatResourceList выводит ресурсы в порядке cpu, memory, остальные
+func formatResourceList(rl corev1.ResourceList) string {
+	var names []string
+	for name := range rl {
+		names = append(names, string(name))
+	}
+	order := map[string]int{"cpu": 0, "memory": 1}
+	sort.Slice(names, func(i, j int) bool {
+		oi, okI := order[names[i]]
+		oj, okJ := order[names[j]]
+		switch {
+		case okI && okJ:
+			return oi < oj
+		case okI != okJ:
+			return okI
+		default:
+			return names[i] < names[j]
+		}
+	})
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		q := rl[corev1.ResourceName(name)]
+		parts = append(parts, name+" "+q.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeVolumeSource кратко описывает источник тома
+func describeVolumeSource(v corev1.Volume) string {
+	switch {
+	case v.PersistentVolumeClaim != nil:
+		return "PVC " + v.PersistentVolumeClaim.ClaimName
+	case v.ConfigMap != nil:
+		return "ConfigMap " + v.ConfigMap.Name
+	case v.Secret != nil:
+		return "Secret " + v.Secret.SecretName
+	case v.HostPath != nil:
+		return "HostPath " + v.HostPath.Path
+	case v.EmptyDir != nil:
+		if v.EmptyDir.Medium != "" {
+			return "EmptyDir (" + string(v.EmptyDir.Medium) + ")"
+		}
+		return "EmptyDir"
+	case v.Projected != nil:
+		return "Projected"
+	case v.DownwardAPI != nil:
+		return "DownwardAPI"
+	case v.NFS != nil:
+		return "NFS " + v.NFS.Server + ":" + v.NFS.Path
+	case v.CSI != nil:
+		return "CSI " + v.CSI.Driver
+	case v.Ephemeral != nil:
+		return "Ephemeral"
+	default:
+		return "-"
+	}
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var out []string
+	for _, p := range parts {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, sep)
+}
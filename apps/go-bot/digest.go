@@ -0,0 +1,560 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// digestListLimit сколько строк показывать в каждом разделе сводки
+	digestListLimit = 10
+)
+
+// DigestReport сводка по кластеру в виде данных; сохраняется, чтобы следующая сводка показала изменения
+type DigestReport struct {
+	Cluster     string
+	Taken       time.Time
+	Nodes       []digestNode
+	NotRunning  []digestPod
+	TopRestarts []digestPod
+	Disks       []digestDisk
+	DiskSource  string // prometheus или conditions
+	HasCerts    bool   // сертификаты проверялись: есть cert-manager или включён просмотр TLS-секретов
+	Certs       []digestCert
+	HasBackups  bool // установлен Velero
+	Backup      *veleroBackup
+	Restarts    map[string]int // рестарты всех pod-ов по ключу ns/name
+	Problems    []string       // разделы, которые не удалось собрать
+}
+
+type digestNode struct {
+	Name       string
+	Ready      bool
+	Status     string
+	CPUPercent float64
+	MemPercent float64
+}
+
+type digestPod struct {
+	Namespace string
+	Name      string
+	Status    string
+	Restarts  int
+}
+
+func (p digestPod) key() string { return p.Namespace + "/" + p.Name }
+
+// digestDisk свободное место файловой системы или признак DiskPressure узла
+type digestDisk struct {
+	Target      string
+	FreePercent float64
+	Pressure    bool
+}
+
+type digestCert struct {
+	Namespace string
+	Name      string
+	NotAfter  time.Time
+	Ready     bool
+}
+
+// digestChange изменение относительно прошлой сводки; Kind — суффикс ключа digest.change.*
+type digestChange struct {
+	Kind    string
+	Subject string
+	Detail  string
+}
+
+// Digester рассылает сводки по расписанию и хранит прошлые сводки для раздела изменений
+type Digester struct {
+	cfg     DigestConfig
+	certs   CertConfig
+	bot     *tgbotapi.BotAPI
+	adminID int64
+	prom    *PromClient
+
+	mu   sync.Mutex
+	last map[string]*DigestReport // ключ: расписание/кластер
+}
+
+// NewDigester создаёт рассыльщик и читает прошлые сводки из StatePath
+func NewDigester(cfg DigestConfig, certs CertConfig, bot *tgbotapi.BotAPI, adminID int64, prom *PromClient) *Digester {
+	d := &Digester{cfg: cfg, certs: certs, bot: bot, adminID: adminID, prom: prom, last: make(map[string]*DigestReport)}
+	if cfg.StatePath == "" {
+		return d
+	}
+	data, err := os.ReadFile(cfg.StatePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ Не удалось прочитать состояние дайджеста %s: %v", cfg.StatePath, err)
+		}
+		return d
+	}
+	if err := json.Unmarshal(data, &d.last); err != nil {
+		log.Printf("⚠️ Некорректный файл состояния дайджеста %s: %v", cfg.StatePath, err)
+	}
+	return d
+}
+
+// Start ждёт ближайшего срабатывания расписаний и рассылает сводки по всем кластерам
+func (d *Digester) Start(ctx context.Context) {
+	for {
+		now := time.Now().In(d.cfg.Location)
+		var next time.Time
+		var due []string
+		for _, s := range d.cfg.Schedules {
+			if s.cron == nil {
+				continue
+			}
+			t := s.cron.Next(now)
+			switch {
+			case t.IsZero():
+			case next.IsZero() || t.Before(next):
+				next, due = t, []string{s.Name}
+			case t.Equal(next):
+				due = append(due, s.Name)
+			}
+		}
+		if next.IsZero() {
+			log.Println("⚠️ Нет действующих расписаний дайджеста")
+			return
+		}
+		log.Printf("🗓 Следующий дайджест (%s): %s", strings.Join(due, ", "), next.Format("2006-01-02 15:04 MST"))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		for _, name := range due {
+			d.Send(ctx, d.adminID, name, clusters.All(), true)
+		}
+	}
+}
+
+// Send собирает и отправляет сводку по каждому кластеру. remember сохраняет её как прошлую
+// для расписания: ручной /digest сравнивает с плановой сводкой, но не сдвигает её
+func (d *Digester) Send(ctx context.Context, chatID int64, schedule string, targets []*Cluster, remember bool) {
+	lang := langFor(chatID)
+	for _, cluster := range targets {
+		report, err := d.Build(ctx, cluster)
+		if err != nil {
+			log.Printf("❌ [%s] Ошибка сбора дайджеста: %v", cluster.Name, err)
+			sendText(d.bot, chatID, clusterLabel(cluster.Name)+T(lang, "digest.failed", err))
+			telemetry.Notifications.Inc("digest", "failed")
+			continue
+		}
+
+		key := schedule + "/" + cluster.Name
+		d.mu.Lock()
+		prev := d.last[key]
+		if remember {
+			d.last[key] = report
+		}
+		d.mu.Unlock()
+
+		changes := diffDigest(prev, report)
+		sendLongRich(d.bot, chatID, "digest", renderDigest(lang, schedule, clusterLabel(cluster.Name), report, prev, changes, d.cfg))
+		telemetry.Notifications.Inc("digest", "sent")
+	}
+	if remember {
+		d.save()
+	}
+}
+
+// Build собирает сводку кластера. Ошибки необязательных разделов попадают в Problems
+func (d *Digester) Build(ctx context.Context, cluster *Cluster) (*DigestReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	snap, err := collectSnapshot(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	report := digestFromSnapshot(snap, d.cfg.TopRestarts)
+	report.Cluster = cluster.Name
+
+	// Prometheus один на бота и смотрит в основной кластер
+	var prom *PromClient
+	if names := clusters.Names(); len(names) > 0 && names[0] == cluster.Name {
+		prom = d.prom
+	}
+	if err := collectDiskUsage(ctx, prom, snap, report); err != nil {
+		report.Problems = append(report.Problems, "disk: "+err.Error())
+	}
+	if d.certs.ScanSecrets || cluster.HasAPI(certManagerAPI) {
+		report.HasCerts = true
+		certs, err := listCertificates(ctx, cluster, d.certs.ScanSecrets)
+		if err != nil {
+			report.Problems = append(report.Problems, "certificates: "+err.Error())
+		}
+		deadline := snap.Taken.Add(d.cfg.CertWarn)
+		for _, c := range certs {
+			if !c.Ready || (!c.NotAfter.IsZero() && c.NotAfter.Before(deadline)) {
+				report.Certs = append(report.Certs, digestCert{Namespace: c.Namespace, Name: c.Name, NotAfter: c.NotAfter, Ready: c.Ready})
+			}
+		}
+	}
+	if cluster.HasAPI(veleroAPI) {
+		report.HasBackups = true
+		backup, err := latestBackup(ctx, cluster)
+		if err != nil {
+			report.Problems = append(report.Problems, "backups: "+err.Error())
+		}
+		report.Backup = backup
+	}
+	return report, nil
+}
+
+// save записывает прошлые сводки в StatePath через временный файл
+func (d *Digester) save() {
+	if d.cfg.StatePath == "" {
+		return
+	}
+	d.mu.Lock()
+	data, err := json.Marshal(d.last)
+	d.mu.Unlock()
+	if err != nil {
+		log.Printf("⚠️ Ошибка сериализации состояния дайджеста: %v", err)
+		return
+	}
+	tmp := d.cfg.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		log.Printf("⚠️ Не удалось сохранить состояние дайджеста: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, d.cfg.StatePath); err != nil {
+		log.Printf("⚠️ Не удалось сохранить состояние дайджеста: %v", err)
+	}
+}
+
+// ScheduleNames имена действующих расписаний, первое — расписание по умолчанию для /digest
+func (d *Digester) ScheduleNames() []string {
+	var names []string
+	for _, s := range d.cfg.Schedules {
+		if s.cron != nil {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
+
+// digestFromSnapshot раскладывает данные /status по разделам сводки
+func digestFromSnapshot(snap *clusterSnapshot, topRestarts int) *DigestReport {
+	report := &DigestReport{Taken: snap.Taken, Restarts: make(map[string]int, len(snap.Pods))}
+
+	for _, node := range snap.Nodes {
+		ready, status := getNodeStatus(node)
+		cpu, mem := getNodeUsage(node.Name, snap.NodeMetrics, node, snap.Pods)
+		report.Nodes = append(report.Nodes, digestNode{
+			Name:       node.Name,
+			Ready:      ready,
+			Status:     status,
+			CPUPercent: calculatePercent(cpu, node.Status.Capacity.Cpu().MilliValue()),
+			MemPercent: calculatePercent(mem, node.Status.Capacity.Memory().Value()),
+		})
+	}
+	sort.Slice(report.Nodes, func(i, j int) bool { return report.Nodes[i].Name < report.Nodes[j].Name })
+
+	var restarting []digestPod
+	for _, p := range snap.Pods {
+		row := newPodRow(p, snap.Taken)
+		pod := digestPod{Namespace: p.Namespace, Name: p.Name, Status: row.Status, Restarts: row.Restarts}
+		if row.Status == string(corev1.PodRunning) {
+			// Running без готовности: важнее, сколько контейнеров готово
+			pod.Status += " " + row.Ready
+		}
+		report.Restarts[pod.key()] = row.Restarts
+		if !row.ready {
+			report.NotRunning = append(report.NotRunning, pod)
+		}
+		if row.Restarts > 0 {
+			restarting = append(restarting, pod)
+		}
+	}
+	sort.Slice(report.NotRunning, func(i, j int) bool { return report.NotRunning[i].key() < report.NotRunning[j].key() })
+	sort.Slice(restarting, func(i, j int) bool {
+		if restarting[i].Restarts != restarting[j].Restarts {
+			return restarting[i].Restarts > restarting[j].Restarts
+		}
+		return restarting[i].key() < restarting[j].key()
+	})
+	if len(restarting) > topRestarts {
+		restarting = restarting[:topRestarts]
+	}
+	report.TopRestarts = restarting
+	return report
+}
+
+// collectDiskUsage берёт свободное место из сохранённого запроса disk_free Prometheus,
+// а без Prometheus — узлы с условием DiskPressure
+func collectDiskUsage(ctx context.Context, prom *PromClient, snap *clusterSnapshot, report *DigestReport) error {
+	q, ok := SavedQuery{}, false
+	if prom != nil {
+		q, ok = prom.cfg.Queries["disk_free"]
+	}
+	if !ok {
+		report.DiskSource = "conditions"
+		for _, node := range snap.Nodes {
+			for _, c := range node.Status.Conditions {
+				if c.Type == corev1.NodeDiskPressure && c.Status == corev1.ConditionTrue {
+					report.Disks = append(report.Disks, digestDisk{Target: node.Name, Pressure: true})
+				}
+			}
+		}
+		return nil
+	}
+
+	report.DiskSource = "prometheus"
+	res, err := prom.Query(ctx, q.Expr, snap.Taken)
+	if err != nil {
+		return err
+	}
+	for _, s := range res.Series {
+		target := s.Metric["instance"]
+		if mp := s.Metric["mountpoint"]; mp != "" {
+			target += " " + mp
+		}
+		if target == "" {
+			target = formatPromLabels(s.Metric)
+		}
+		report.Disks = append(report.Disks, digestDisk{Target: target, FreePercent: s.Value.Value})
+	}
+	sort.Slice(report.Disks, func(i, j int) bool { return report.Disks[i].FreePercent < report.Disks[j].FreePercent })
+	if len(report.Disks) > digestListLimit {
+		report.Disks = report.Disks[:digestListLimit]
+	}
+	return nil
+}
+
+// diffDigest сравнивает сводку с прошлой: узлы, проблемные pod-ы, рост рестартов, новый бэкап
+func diffDigest(prev, cur *DigestReport) []digestChange {
+	if prev == nil {
+		return nil
+	}
+	var changes []digestChange
+
+	prevNodes := make(map[string]bool, len(prev.Nodes))
+	for _, n := range prev.Nodes {
+		prevNodes[n.Name] = n.Ready
+	}
+	curNodes := make(map[string]bool, len(cur.Nodes))
+	for _, n := range cur.Nodes {
+		curNodes[n.Name] = true
+		wasReady, existed := prevNodes[n.Name]
+		switch {
+		case !existed:
+			changes = append(changes, digestChange{Kind: "node_added", Subject: n.Name})
+		case wasReady && !n.Ready:
+			changes = append(changes, digestChange{Kind: "node_not_ready", Subject: n.Name, Detail: n.Status})
+		case !wasReady && n.Ready:
+			changes = append(changes, digestChange{Kind: "node_ready", Subject: n.Name})
+		}
+	}
+	for _, n := range prev.Nodes {
+		if !curNodes[n.Name] {
+			changes = append(changes, digestChange{Kind: "node_removed", Subject: n.Name})
+		}
+	}
+
+	prevFailing := make(map[string]bool, len(prev.NotRunning))
+	for _, p := range prev.NotRunning {
+		prevFailing[p.key()] = true
+	}
+	curFailing := make(map[string]bool, len(cur.NotRunning))
+	for _, p := range cur.NotRunning {
+		curFailing[p.key()] = true
+		if !prevFailing[p.key()] {
+			changes = append(changes, digestChange{Kind: "pod_failing", Subject: p.key(), Detail: p.Status})
+		}
+	}
+	for _, p := range prev.NotRunning {
+		// Удалённые pod-ы не считаются восстановившимися
+		if _, exists := cur.Restarts[p.key()]; exists && !curFailing[p.key()] {
+			changes = append(changes, digestChange{Kind: "pod_recovered", Subject: p.key()})
+		}
+	}
+
+	type growth struct {
+		key string
+		n   int
+	}
+	var grown []growth
+	for key, n := range cur.Restarts {
+		if before, ok := prev.Restarts[key]; ok && n > before {
+			grown = append(grown, growth{key, n - before})
+		}
+	}
+	sort.Slice(grown, func(i, j int) bool {
+		if grown[i].n != grown[j].n {
+			return grown[i].n > grown[j].n
+		}
+		return grown[i].key < grown[j].key
+	})
+	if len(grown) > digestListLimit {
+		grown = grown[:digestListLimit]
+	}
+	for _, g := range grown {
+		changes = append(changes, digestChange{Kind: "restarts", Subject: g.key, Detail: fmt.Sprintf("+%d", g.n)})
+	}
+
+	if cur.Backup != nil && (prev.Backup == nil || prev.Backup.Name != cur.Backup.Name) {
+		changes = append(changes, digestChange{Kind: "backup_new", Subject: cur.Backup.Name, Detail: cur.Backup.Phase})
+	}
+	return changes
+}
+
+// renderDigest формирует сводку; prev нужен только для времени прошлой сводки
+func renderDigest(lang Lang, schedule, cluster string, d *DigestReport, prev *DigestReport, changes []digestChange, cfg DigestConfig) *Rich {
+	r := NewRich()
+	r.Text("📋 " + cluster).Bold(T(lang, "digest.title", schedule)).Text(" " + d.Taken.In(cfg.Location).Format("02.01 15:04")).Line().Line()
+
+	ready := 0
+	for _, n := range d.Nodes {
+		if n.Ready {
+			ready++
+		}
+	}
+	r.Text("🖥️ ").Bold(T(lang, "digest.nodes", ready, len(d.Nodes))).Line()
+	for _, n := range d.Nodes {
+		r.Text(getStatusEmoji(n.Ready) + " ").Code(n.Name).Text(T(lang, "digest.node_usage", int(n.CPUPercent), int(n.MemPercent)))
+		if !n.Ready {
+			r.Text(" — " + n.Status)
+		}
+		r.Line()
+	}
+	r.Line()
+
+	if len(d.NotRunning) == 0 {
+		r.Text("✅ " + T(lang, "digest.pods_ok")).Line()
+	} else {
+		r.Text("⚠️ ").Bold(T(lang, "digest.pods_not_running", len(d.NotRunning))).Line()
+		for i, p := range d.NotRunning {
+			if i == digestListLimit {
+				r.Text(T(lang, "digest.more", len(d.NotRunning)-i)).Line()
+				break
+			}
+			r.Code(p.key()).Text(" — " + p.Status).Line()
+		}
+	}
+	if len(d.TopRestarts) > 0 {
+		r.Line().Text("🔁 ").Bold(T(lang, "digest.restarts")).Line()
+		for _, p := range d.TopRestarts {
+			r.Code(p.key()).Textf(" — %d", p.Restarts).Line()
+		}
+	}
+	r.Line()
+
+	if d.DiskSource == "prometheus" {
+		r.Text("💽 ").Bold(T(lang, "digest.disks")).Line()
+		if len(d.Disks) == 0 {
+			r.Text(T(lang, "digest.disks_none")).Line()
+		}
+		for _, disk := range d.Disks {
+			mark := ""
+			if disk.FreePercent < cfg.DiskWarnPercent {
+				mark = " ⚠️"
+			}
+			r.Code(disk.Target).Textf(" — %.0f%%%s", disk.FreePercent, mark).Line()
+		}
+	} else if len(d.Disks) > 0 {
+		names := make([]string, len(d.Disks))
+		for i, disk := range d.Disks {
+			names[i] = disk.Target
+		}
+		r.Text("💽 " + T(lang, "digest.disk_pressure", strings.Join(names, ", "))).Line()
+	} else {
+		r.Text("💽 " + T(lang, "digest.disk_pressure_none")).Line()
+	}
+
+	if d.HasCerts {
+		if len(d.Certs) == 0 {
+			r.Text("🔐 " + T(lang, "digest.certs_ok", formatDuration(lang, cfg.CertWarn))).Line()
+		} else {
+			r.Text("🔐 ").Bold(T(lang, "digest.certs")).Line()
+			for _, c := range d.Certs {
+				r.Code(c.Namespace + "/" + c.Name).Text(" — ")
+				switch {
+				case c.NotAfter.IsZero():
+					r.Text(T(lang, "digest.cert_not_ready"))
+				case !c.NotAfter.After(d.Taken):
+					r.Text(T(lang, "digest.cert_expired"))
+				default:
+					r.Text(T(lang, "digest.cert_expires", formatDuration(lang, c.NotAfter.Sub(d.Taken))))
+					if !c.Ready {
+						r.Text(", " + T(lang, "digest.cert_not_ready"))
+					}
+				}
+				r.Line()
+			}
+		}
+	}
+
+	if d.HasBackups {
+		if b := d.Backup; b == nil {
+			r.Text("💾 " + T(lang, "digest.backup_none")).Line()
+		} else {
+			mark := "✅"
+			if b.Phase != "Completed" {
+				mark = "⚠️"
+			}
+			r.Text("💾 " + T(lang, "digest.backup", b.Name, mark, orDash(b.Phase), formatDuration(lang, d.Taken.Sub(b.Started))))
+			if b.Errors > 0 || b.Warnings > 0 {
+				r.Text(" " + T(lang, "digest.backup_issues", b.Errors, b.Warnings))
+			}
+			r.Line()
+		}
+	}
+	r.Line()
+
+	switch {
+	case prev == nil:
+		r.Italic(T(lang, "digest.first")).Line()
+	case len(changes) == 0:
+		r.Text("🔄 " + T(lang, "digest.no_changes", prev.Taken.In(cfg.Location).Format("02.01 15:04"))).Line()
+	default:
+		r.Text("🔄 ").Bold(T(lang, "digest.changes", prev.Taken.In(cfg.Location).Format("02.01 15:04"))).Line()
+		for _, c := range changes {
+			args := []any{c.Subject}
+			if c.Detail != "" {
+				args = append(args, c.Detail)
+			}
+			r.Text(T(lang, "digest.change."+c.Kind, args...)).Line()
+		}
+	}
+
+	if len(d.Problems) > 0 {
+		r.Line().Italic(T(lang, "digest.problems", strings.Join(d.Problems, "; "))).Line()
+	}
+	return r
+}
+
+// handleDigest отправляет сводку по активному кластеру чата, сравнивая её с плановой
+func handleDigest(bot *tgbotapi.BotAPI, digester *Digester, cluster *Cluster, ctx context.Context, chatID int64, args string) {
+	lang := langFor(chatID)
+	names := digester.ScheduleNames()
+	schedule := strings.TrimSpace(args)
+	if schedule == "" {
+		schedule = "digest"
+		if len(names) > 0 {
+			schedule = names[0]
+		}
+	} else if !slices.Contains(names, schedule) {
+		sendText(bot, chatID, T(lang, "usage.digest", strings.Join(names, ", ")))
+		return
+	}
+	digester.Send(ctx, chatID, schedule, []*Cluster{cluster}, false)
+}
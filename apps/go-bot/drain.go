@@ -0,0 +1,446 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// mirrorPodAnnotation статические поды kubelet-а, удалить их через API нельзя
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// drainRetryInterval пауза между попытками вытеснения, пока PDB не разрешит
+var drainRetryInterval = 5 * time.Second
+
+// drainEditInterval не чаще скольки правим сообщение с ходом drain
+const drainEditInterval = time.Second
+
+// drainProgressLines сколько подов показывать в сообщении с ходом drain
+const drainProgressLines = 20
+
+// errDrainTimeout не все поды вытеснены за отведённое время
+var errDrainTimeout = errors.New("истёк таймаут drain")
+
+// maintenanceNodes узлы, выведенные на обслуживание через /drain
+var maintenanceNodes = newNodeSilences()
+
+// activeDrains узлы, по которым сейчас идёт drain, чтобы не запустить второй
+var activeDrains = struct {
+	sync.Mutex
+	nodes map[string]bool
+}{nodes: make(map[string]bool)}
+
+// drainOptions параметры команды /drain
+type drainOptions struct {
+	Force   bool // вытеснять поды без контроллера
+	Timeout time.Duration
+}
+
+// parseDrainArgs разбирает "<node> [--force] [--timeout 10m]"
+func parseDrainArgs(args string, def time.Duration) (string, drainOptions, bool) {
+	opts := drainOptions{Timeout: def}
+	var node string
+	fields := strings.Fields(args)
+	for i := 0; i < len(fields); i++ {
+		switch f := fields[i]; {
+		case f == "--force":
+			opts.Force = true
+		case f == "--timeout" && i+1 < len(fields):
+			i++
+			d, err := time.ParseDuration(fields[i])
+			if err != nil || d <= 0 {
+				return "", opts, false
+			}
+			opts.Timeout = d
+		case strings.HasPrefix(f, "--timeout="):
+			d, err := time.ParseDuration(strings.TrimPrefix(f, "--timeout="))
+			if err != nil || d <= 0 {
+				return "", opts, false
+			}
+			opts.Timeout = d
+		case strings.HasPrefix(f, "-") || node != "":
+			return "", opts, false
+		default:
+			node = f
+		}
+	}
+	return node, opts, node != ""
+}
+
+// drainPlan поды узла, разобранные перед drain
+type drainPlan struct {
+	Evict     []corev1.Pod
+	DaemonSet int      // поды DaemonSet-ов остаются на узле
+	Mirror    int      // статические поды kubelet-а
+	Unmanaged []string // поды без контроллера, их вытесняем только с --force
+}
+
+// planDrain собирает поды узла и решает, какие из них вытеснять
+func planDrain(ctx context.Context, clientset kubernetes.Interface, node string, force bool) (*drainPlan, error) {
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{FieldSelector: "spec.nodeName=" + node})
+	if err != nil {
+		return nil, err
+	}
+	plan := &drainPlan{}
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+			plan.Mirror++
+			continue
+		}
+		owner := metav1.GetControllerOf(&pod)
+		if owner != nil && owner.Kind == "DaemonSet" {
+			plan.DaemonSet++
+			continue
+		}
+		if owner == nil {
+			plan.Unmanaged = append(plan.Unmanaged, pod.Namespace+"/"+pod.Name)
+			if !force {
+				continue
+			}
+		}
+		plan.Evict = append(plan.Evict, pod)
+	}
+	return plan, nil
+}
+
+// drainPodState стадия вытеснения пода
+type drainPodState int
+
+const (
+	drainPending  drainPodState = iota // ещё не вытеснялся или PDB не разрешил
+	drainDeleting                      // вытеснение принято, ждём удаления пода
+	drainEvicted
+	drainFailed
+)
+
+// drainPod под в ходе drain
+type drainPod struct {
+	Namespace string
+	Name      string
+	UID       types.UID
+	State     drainPodState
+	Reason    string // почему под ещё не вытеснен или не удалось
+}
+
+// drainProgress ход drain узла
+type drainProgress struct {
+	Node     string
+	Pods     []*drainPod
+	Started  time.Time
+	Finished bool
+	TimedOut bool
+}
+
+// newDrainProgress начальное состояние drain: все поды ещё на узле
+func newDrainProgress(node string, pods []corev1.Pod) *drainProgress {
+	progress := &drainProgress{Node: node, Started: time.Now()}
+	for _, pod := range pods {
+		progress.Pods = append(progress.Pods, &drainPod{Namespace: pod.Namespace, Name: pod.Name, UID: pod.UID})
+	}
+	return progress
+}
+
+// Count число подов в указанной стадии
+func (p *drainProgress) Count(state drainPodState) int {
+	n := 0
+	for _, pod := range p.Pods {
+		if pod.State == state {
+			n++
+		}
+	}
+	return n
+}
+
+// Err итог drain: таймаут или поды, которые не удалось вытеснить
+func (p *drainProgress) Err() error {
+	if p.TimedOut {
+		return errDrainTimeout
+	}
+	if n := p.Count(drainFailed); n > 0 {
+		return fmt.Errorf("не удалось вытеснить подов: %d", n)
+	}
+	return nil
+}
+
+// drainNode вытесняет поды через Eviction API до дедлайна.
+// 429 от API означает, что PodDisruptionBudget пока не разрешает вытеснение — повторяем позже.
+// report вызывается после каждого изменения состояния
+func drainNode(ctx context.Context, clientset kubernetes.Interface, progress *drainProgress, deadline time.Time, report func(*drainProgress)) *drainProgress {
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	for {
+		changed := false
+		for _, pod := range progress.Pods {
+			if stepDrainPod(ctx, clientset, pod) {
+				changed = true
+			}
+		}
+		if progress.Count(drainPending)+progress.Count(drainDeleting) == 0 {
+			progress.Finished = true
+			report(progress)
+			return progress
+		}
+		if changed {
+			report(progress)
+		}
+
+		select {
+		case <-ctx.Done():
+			progress.Finished = true
+			progress.TimedOut = true
+			report(progress)
+			return progress
+		case <-time.After(drainRetryInterval):
+		}
+	}
+}
+
+// stepDrainPod продвигает под на одну стадию, возвращает true, если стадия изменилась
+func stepDrainPod(ctx context.Context, clientset kubernetes.Interface, pod *drainPod) bool {
+	switch pod.State {
+	case drainPending:
+		eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}}
+		err := clientset.CoreV1().Pods(pod.Namespace).EvictV1(ctx, eviction)
+		switch {
+		case err == nil:
+			pod.State, pod.Reason = drainDeleting, ""
+			return true
+		case apierrors.IsNotFound(err):
+			pod.State, pod.Reason = drainEvicted, ""
+			return true
+		case apierrors.IsTooManyRequests(err):
+			reason := "PodDisruptionBudget"
+			if status, ok := err.(apierrors.APIStatus); ok && status.Status().Message != "" {
+				reason = status.Status().Message
+			}
+			changed := pod.Reason != reason
+			pod.Reason = reason
+			return changed
+		case ctx.Err() != nil:
+			return false
+		default:
+			pod.State, pod.Reason = drainFailed, err.Error()
+			return true
+		}
+
+	case drainDeleting:
+		current, err := clientset.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) || (err == nil && current.UID != pod.UID) {
+			pod.State = drainEvicted
+			return true
+		}
+	}
+	return false
+}
+
+// setUnschedulable включает или снимает cordon с узла
+func setUnschedulable(ctx context.Context, clientset kubernetes.Interface, node string, unschedulable bool) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable))
+	_, err := clientset.CoreV1().Nodes().Patch(ctx, node, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// handleCordon запрещает планирование подов на узел после подтверждения
+func handleCordon(bot *tgbotapi.BotAPI, cluster *Cluster, ctx context.Context, chatID int64, user *tgbotapi.User, node string) {
+	lang := langFor(chatID)
+	if _, err := cluster.Typed.CoreV1().Nodes().Get(ctx, node, metav1.GetOptions{}); err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	prompt := NewRich().
+		Text("🚧 ").Bold(T(lang, "node.cordon_confirm")).Line().Line().
+		Text("🖥 ").Bold(T(lang, "node.label")).Text(" ").Code(node)
+	owner, label := userLabel(user), clusterLabel(cluster.Name)
+	askUserConfirmation(bot, chatID, user.ID, prompt, func(ctx context.Context) (*Rich, error) {
+		err := setUnschedulable(ctx, cluster.Typed, node, true)
+		auditLog.Record(owner, cluster.Name, "node.cordon", node, err)
+		if err != nil {
+			return nil, err
+		}
+		return NewRich().Text("🚧 " + label + T(lang, "node.cordoned") + " ").Code(node), nil
+	})
+}
+
+// handleUncordon возвращает узел в планирование и снимает тишину алертов
+func handleUncordon(bot *tgbotapi.BotAPI, cluster *Cluster, ctx context.Context, chatID int64, user *tgbotapi.User, node string) {
+	err := setUnschedulable(ctx, cluster.Typed, node, false)
+	auditLog.Record(userLabel(user), cluster.Name, "node.uncordon", node, err)
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	maintenanceNodes.Release(cluster.Name, node)
+	sendRich(bot, chatID, NewRich().Text("✅ "+clusterLabel(cluster.Name)+T(langFor(chatID), "node.uncordoned")+" ").Code(node))
+}
+
+// handleDrain показывает, какие поды будут вытеснены, и после подтверждения
+// выводит узел на обслуживание: cordon, тишина алертов и вытеснение в фоне
+func handleDrain(bot *tgbotapi.BotAPI, cluster *Cluster, cfg DrainConfig, ctx context.Context, chatID int64, user *tgbotapi.User, args string) {
+	lang := langFor(chatID)
+	node, opts, ok := parseDrainArgs(args, cfg.Timeout)
+	if !ok {
+		sendText(bot, chatID, T(lang, "usage.drain"))
+		return
+	}
+	if _, err := cluster.Typed.CoreV1().Nodes().Get(ctx, node, metav1.GetOptions{}); err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	plan, err := planDrain(ctx, cluster.Typed, node, opts.Force)
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	if len(plan.Unmanaged) > 0 && !opts.Force {
+		sendText(bot, chatID, T(lang, "drain.unmanaged", strings.Join(plan.Unmanaged, ", ")))
+		return
+	}
+
+	prompt := renderDrainPlan(lang, node, plan, opts)
+	owner, label := userLabel(user), clusterLabel(cluster.Name)
+	askUserConfirmation(bot, chatID, user.ID, prompt, func(ctx context.Context) (*Rich, error) {
+		key := cluster.Name + "/" + node
+		activeDrains.Lock()
+		busy := activeDrains.nodes[key]
+		activeDrains.nodes[key] = true
+		activeDrains.Unlock()
+		if busy {
+			return nil, errors.New(T(lang, "drain.busy", node))
+		}
+		release := func() {
+			activeDrains.Lock()
+			delete(activeDrains.nodes, key)
+			activeDrains.Unlock()
+		}
+
+		// За время ожидания подтверждения поды могли смениться: вытесняем то, что на узле сейчас
+		plan, err := planDrain(ctx, cluster.Typed, node, opts.Force)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		if len(plan.Unmanaged) > 0 && !opts.Force {
+			release()
+			return nil, errors.New(T(lang, "drain.unmanaged", strings.Join(plan.Unmanaged, ", ")))
+		}
+
+		err = setUnschedulable(ctx, cluster.Typed, node, true)
+		auditLog.Record(owner, cluster.Name, "node.cordon", node, err)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		maintenanceNodes.Hold(cluster.Name, node, time.Now().Add(cfg.SilenceFor))
+
+		go func() {
+			defer release()
+			runDrain(bot, cluster, ctx, chatID, owner, node, plan.Evict, opts.Timeout)
+		}()
+		return NewRich().Text("🚧 " + label + T(lang, "drain.started") + " ").Code(node), nil
+	})
+}
+
+// runDrain вытесняет поды и держит в чате одно сообщение с ходом drain
+func runDrain(bot *tgbotapi.BotAPI, cluster *Cluster, ctx context.Context, chatID int64, owner, node string, pods []corev1.Pod, timeout time.Duration) {
+	lang := langFor(chatID)
+	progress := newDrainProgress(node, pods)
+	msg, err := sendRichMessage(bot, chatID, renderDrainProgress(lang, cluster.Name, progress), nil)
+	if err != nil {
+		log.Printf("⚠️ Не удалось отправить ход drain %s: %v", node, err)
+	}
+	var lastEdit time.Time
+	report := func(p *drainProgress) {
+		if msg.MessageID == 0 || (!p.Finished && time.Since(lastEdit) < drainEditInterval) {
+			return
+		}
+		lastEdit = time.Now()
+		editRich(bot, chatID, msg.MessageID, renderDrainProgress(lang, cluster.Name, p), nil)
+	}
+
+	drainNode(ctx, cluster.Typed, progress, time.Now().Add(timeout), report)
+	auditLog.Record(owner, cluster.Name, "node.drain", node, progress.Err())
+	if msg.MessageID == 0 {
+		sendRich(bot, chatID, renderDrainProgress(lang, cluster.Name, progress))
+	}
+}
+
+// renderDrainPlan вопрос о подтверждении drain со сводкой по подам
+func renderDrainPlan(lang Lang, node string, plan *drainPlan, opts drainOptions) *Rich {
+	r := NewRich().
+		Text("🚧 ").Bold(T(lang, "drain.confirm")).Line().Line().
+		Text("🖥 ").Bold(T(lang, "node.label")).Text(" ").Code(node).Line().
+		Text("📦 ").Bold(T(lang, "drain.evict")).Text(fmt.Sprintf(" %d", len(plan.Evict))).Line().
+		Text("👻 ").Bold(T(lang, "drain.skip")).Text(" " + T(lang, "drain.skip_counts", plan.DaemonSet, plan.Mirror)).Line().
+		Text("⏱ ").Bold(T(lang, "drain.timeout")).Text(" " + formatDuration(lang, opts.Timeout))
+	if opts.Force && len(plan.Unmanaged) > 0 {
+		r.Line().Text("⚠️ " + T(lang, "drain.force", strings.Join(plan.Unmanaged, ", ")))
+	}
+	return r.Line().Line().Italic(T(lang, "drain.silence_note"))
+}
+
+// renderDrainProgress сообщение с ходом drain: счётчики и состояние подов
+func renderDrainProgress(lang Lang, cluster string, p *drainProgress) *Rich {
+	r := NewRich()
+	switch {
+	case !p.Finished:
+		r.Text("⏳ " + alertPrefix(cluster)).Bold(T(lang, "drain.progress_title", p.Node))
+	case p.Err() == nil:
+		r.Text("✅ " + alertPrefix(cluster)).Bold(T(lang, "drain.done_title", p.Node))
+	case p.TimedOut:
+		r.Text("⌛ " + alertPrefix(cluster)).Bold(T(lang, "drain.timeout_title", p.Node))
+	default:
+		r.Text("❌ " + alertPrefix(cluster)).Bold(T(lang, "drain.failed_title", p.Node))
+	}
+	r.Line().Line().Text(T(lang, "drain.counts", p.Count(drainEvicted), len(p.Pods)))
+	if p.Finished {
+		r.Text(", " + formatDuration(lang, time.Since(p.Started)))
+	}
+
+	shown := 0
+	for _, pod := range p.Pods {
+		if shown == drainProgressLines {
+			r.Line().Italic(T(lang, "drain.more", len(p.Pods)-shown))
+			break
+		}
+		shown++
+		r.Line()
+		switch pod.State {
+		case drainEvicted:
+			r.Text("✅ ")
+		case drainDeleting:
+			r.Text("🗑 ")
+		case drainFailed:
+			r.Text("❌ ")
+		default:
+			if pod.Reason != "" {
+				r.Text("🛡 ")
+			} else {
+				r.Text("• ")
+			}
+		}
+		r.Code(pod.Namespace + "/" + pod.Name)
+		if pod.Reason != "" {
+			r.Text(" — " + pod.Reason)
+		}
+	}
+	if p.Finished {
+		r.Line().Line().Italic(T(lang, "drain.uncordon_hint", p.Node))
+	}
+	return r
+}
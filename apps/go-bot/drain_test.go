@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const pdbMessage = "Cannot evict pod as it would violate the pod's disruption budget."
+
+func nodePod(name string, owner string, annotations map[string]string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        name,
+			UID:         types.UID(name + "-uid"),
+			Annotations: annotations,
+		},
+		Spec: corev1.PodSpec{NodeName: "worker-1"},
+	}
+	if owner != "" {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: owner, Name: name + "-owner", Controller: &controller}}
+	}
+	return pod
+}
+
+func drainObjects() []runtime.Object {
+	terminating := nodePod("terminating", "ReplicaSet", nil)
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	return []runtime.Object{
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}},
+		nodePod("web", "ReplicaSet", nil),
+		nodePod("db-0", "StatefulSet", nil),
+		nodePod("node-exporter", "DaemonSet", nil),
+		nodePod("kube-vip", "", map[string]string{mirrorPodAnnotation: "abc"}),
+		nodePod("debug", "", nil),
+		terminating,
+	}
+}
+
+func podNames(pods []corev1.Pod) string {
+	names := make([]string, 0, len(pods))
+	for _, p := range pods {
+		names = append(names, p.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestPlanDrain(t *testing.T) {
+	clientset := fake.NewSimpleClientset(drainObjects()...)
+	ctx := context.Background()
+
+	plan, err := planDrain(ctx, clientset, "worker-1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := podNames(plan.Evict); got != "db-0,web" {
+		t.Errorf("вытесняются %s", got)
+	}
+	if plan.DaemonSet != 1 || plan.Mirror != 1 || strings.Join(plan.Unmanaged, ",") != "default/debug" {
+		t.Errorf("план: daemonset=%d mirror=%d unmanaged=%v", plan.DaemonSet, plan.Mirror, plan.Unmanaged)
+	}
+
+	plan, err = planDrain(ctx, clientset, "worker-1", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := podNames(plan.Evict); got != "db-0,debug,web" {
+		t.Errorf("с --force вытесняются %s", got)
+	}
+}
+
+// evictionReactor имитирует Eviction API: respond решает, что ответить на n-ю попытку
+// вытеснения пода; при успехе под удаляется из трекера
+func evictionReactor(t *testing.T, clientset *fake.Clientset, respond func(pod string, attempt int) error) map[string]int {
+	t.Helper()
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		create, ok := action.(k8stesting.CreateAction)
+		if !ok || action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := create.GetObject().(*policyv1.Eviction)
+		mu.Lock()
+		attempts[eviction.Name]++
+		n := attempts[eviction.Name]
+		mu.Unlock()
+		if err := respond(eviction.Name, n); err != nil {
+			return true, nil, err
+		}
+		gvr := corev1.SchemeGroupVersion.WithResource("pods")
+		return true, nil, clientset.Tracker().Delete(gvr, eviction.Namespace, eviction.Name)
+	})
+	return attempts
+}
+
+func withDrainRetry(t *testing.T, d time.Duration) {
+	t.Helper()
+	prev := drainRetryInterval
+	drainRetryInterval = d
+	t.Cleanup(func() { drainRetryInterval = prev })
+}
+
+func TestDrainNodeRetriesPDB(t *testing.T) {
+	withDrainRetry(t, 5*time.Millisecond)
+	clientset := fake.NewSimpleClientset(drainObjects()...)
+	attempts := evictionReactor(t, clientset, func(pod string, attempt int) error {
+		// PDB разрешает вытеснить db-0 только с третьей попытки
+		if pod == "db-0" && attempt < 3 {
+			return apierrors.NewTooManyRequests(pdbMessage, 0)
+		}
+		return nil
+	})
+	ctx := context.Background()
+	plan, err := planDrain(ctx, clientset, "worker-1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reasons []string
+	progress := drainNode(ctx, clientset, newDrainProgress("worker-1", plan.Evict), time.Now().Add(5*time.Second), func(p *drainProgress) {
+		for _, pod := range p.Pods {
+			if pod.Reason != "" {
+				reasons = append(reasons, pod.Name+": "+pod.Reason)
+			}
+		}
+	})
+	if err := progress.Err(); err != nil || !progress.Finished || progress.TimedOut {
+		t.Fatalf("drain: err=%v finished=%v timedOut=%v", err, progress.Finished, progress.TimedOut)
+	}
+	if progress.Count(drainEvicted) != 2 {
+		t.Errorf("вытеснено %d из 2", progress.Count(drainEvicted))
+	}
+	if attempts["db-0"] != 3 || attempts["web"] != 1 {
+		t.Errorf("попытки вытеснения: %v", attempts)
+	}
+	if len(reasons) == 0 || reasons[0] != "db-0: "+pdbMessage {
+		t.Errorf("причина ожидания не показана: %v", reasons)
+	}
+	if pods, _ := clientset.CoreV1().Pods("default").List(ctx, metav1.ListOptions{}); podNames(pods.Items) != "debug,kube-vip,node-exporter,terminating" {
+		t.Errorf("на узле остались %s", podNames(pods.Items))
+	}
+}
+
+func TestDrainNodeTimeout(t *testing.T) {
+	withDrainRetry(t, 5*time.Millisecond)
+	clientset := fake.NewSimpleClientset(drainObjects()...)
+	evictionReactor(t, clientset, func(pod string, attempt int) error {
+		if pod == "db-0" {
+			return apierrors.NewTooManyRequests(pdbMessage, 0)
+		}
+		return nil
+	})
+	ctx := context.Background()
+	plan, _ := planDrain(ctx, clientset, "worker-1", false)
+
+	started := time.Now()
+	progress := drainNode(ctx, clientset, newDrainProgress("worker-1", plan.Evict), time.Now().Add(50*time.Millisecond), func(*drainProgress) {})
+	if time.Since(started) > 2*time.Second {
+		t.Fatal("drain не остановился по таймауту")
+	}
+	if !progress.TimedOut || !errors.Is(progress.Err(), errDrainTimeout) {
+		t.Fatalf("ожидался таймаут: %+v", progress)
+	}
+	for _, pod := range progress.Pods {
+		switch pod.Name {
+		case "db-0":
+			if pod.State != drainPending || pod.Reason != pdbMessage {
+				t.Errorf("db-0: state=%d reason=%q", pod.State, pod.Reason)
+			}
+		case "web":
+			if pod.State != drainEvicted {
+				t.Errorf("web: state=%d", pod.State)
+			}
+		}
+	}
+}
+
+func TestStepDrainPod(t *testing.T) {
+	ctx := context.Background()
+	pod := nodePod("db-0", "StatefulSet", nil)
+	clientset := fake.NewSimpleClientset(pod)
+	// Вытеснение принято, но под удаляется не сразу
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return action.GetSubresource() == "eviction", nil, nil
+	})
+
+	p := &drainPod{Namespace: "default", Name: "db-0", UID: pod.UID}
+	if !stepDrainPod(ctx, clientset, p) || p.State != drainDeleting {
+		t.Fatalf("после вытеснения: %+v", p)
+	}
+	if stepDrainPod(ctx, clientset, p) || p.State != drainDeleting {
+		t.Fatalf("под ещё на месте: %+v", p)
+	}
+	// StatefulSet пересоздал под с тем же именем — старый уже удалён
+	recreated := pod.DeepCopy()
+	recreated.UID = "db-0-new"
+	if _, err := clientset.CoreV1().Pods("default").Update(ctx, recreated, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !stepDrainPod(ctx, clientset, p) || p.State != drainEvicted {
+		t.Fatalf("после пересоздания: %+v", p)
+	}
+
+	// Прочие ошибки API — под не вытеснен
+	failing := fake.NewSimpleClientset(nodePod("web", "ReplicaSet", nil))
+	evictionReactor(t, failing, func(string, int) error { return apierrors.NewInternalError(errors.New("etcd timeout")) })
+	p = &drainPod{Namespace: "default", Name: "web"}
+	if !stepDrainPod(ctx, failing, p) || p.State != drainFailed || !strings.Contains(p.Reason, "etcd timeout") {
+		t.Fatalf("ошибка вытеснения: %+v", p)
+	}
+}
+
+func TestHandleDrainRefusesUnmanaged(t *testing.T) {
+	cluster := newFakeCluster(t, nil, drainObjects())
+	bot, ft := newFakeTelegram(t, nil)
+	user := &tgbotapi.User{ID: 100}
+	ctx := context.Background()
+
+	handleDrain(bot, cluster, DefaultDrainConfig(), ctx, 42, user, "worker-1")
+	sent := sentSince(ft, 0)
+	if len(sent) != 1 || sent[0] != T(langFor(42), "drain.unmanaged", "default/debug") {
+		t.Fatalf("без --force: %q", sent)
+	}
+	pendingActions.Lock()
+	for _, a := range pendingActions.items {
+		if a.ChatID == 42 && a.UserID == user.ID {
+			t.Error("drain с неуправляемыми подами ждёт подтверждения")
+		}
+	}
+	pendingActions.Unlock()
+
+	// С --force под попадает в план, и drain ждёт подтверждения автора
+	handleDrain(bot, cluster, DefaultDrainConfig(), ctx, 42, user, "worker-1 --force")
+	if sent := sentSince(ft, 1); len(sent) != 1 || !strings.Contains(sent[0], "default/debug") {
+		t.Fatalf("с --force: %q", sent)
+	}
+	pendingActions.Lock()
+	defer pendingActions.Unlock()
+	found := false
+	for _, a := range pendingActions.items {
+		found = found || a.ChatID == 42 && a.UserID == user.ID
+	}
+	if !found {
+		t.Error("нет действия, ожидающего подтверждения автора")
+	}
+}
+
+func TestHandleDrainReplansOnConfirm(t *testing.T) {
+	objects := drainObjects()[:5] // без пода без контроллера
+	cluster := newFakeCluster(t, nil, objects)
+	bot, _ := newFakeTelegram(t, nil)
+	user := &tgbotapi.User{ID: 101}
+	ctx := context.Background()
+
+	handleDrain(bot, cluster, DefaultDrainConfig(), ctx, 43, user, "worker-1")
+	var run func(ctx context.Context) (*Rich, error)
+	pendingActions.Lock()
+	for id, a := range pendingActions.items {
+		if a.ChatID == 43 && a.UserID == user.ID {
+			run = a.Run
+			delete(pendingActions.items, id)
+		}
+	}
+	pendingActions.Unlock()
+	if run == nil {
+		t.Fatal("drain не ждёт подтверждения")
+	}
+
+	// Пока вопрос висел, на узле появился под без контроллера
+	if _, err := cluster.Typed.CoreV1().Pods("default").Create(ctx, nodePod("debug", "", nil), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run(ctx); err == nil || err.Error() != T(langFor(43), "drain.unmanaged", "default/debug") {
+		t.Fatalf("подтверждение не перепроверило план: %v", err)
+	}
+	node, err := cluster.Typed.CoreV1().Nodes().Get(ctx, "worker-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Spec.Unschedulable {
+		t.Error("узел помечен unschedulable, хотя drain отменён")
+	}
+	activeDrains.Lock()
+	defer activeDrains.Unlock()
+	if activeDrains.nodes[cluster.Name+"/worker-1"] {
+		t.Error("отменённый drain остался в списке активных")
+	}
+}
+
+func TestParseDrainArgs(t *testing.T) {
+	cases := []struct {
+		args  string
+		node  string
+		opts  drainOptions
+		valid bool
+	}{
+		{"worker-1", "worker-1", drainOptions{Timeout: 5 * time.Minute}, true},
+		{"worker-1 --force --timeout 10m", "worker-1", drainOptions{Force: true, Timeout: 10 * time.Minute}, true},
+		{"--timeout=30s worker-1", "worker-1", drainOptions{Timeout: 30 * time.Second}, true},
+		{"", "", drainOptions{}, false},
+		{"worker-1 worker-2", "", drainOptions{}, false},
+		{"worker-1 --timeout -1m", "", drainOptions{}, false},
+		{"worker-1 --grace 10", "", drainOptions{}, false},
+	}
+	for _, tc := range cases {
+		node, opts, ok := parseDrainArgs(tc.args, 5*time.Minute)
+		if ok != tc.valid || (ok && (node != tc.node || opts != tc.opts)) {
+			t.Errorf("%q: %q %+v %v", tc.args, node, opts, ok)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"encoding/gob"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Sample одно значение ряда: CPU в ядрах, память в байтах
+type Sample struct {
+	Time  int64 // unix-секунды
+	Value float64
+}
+
+// seriesRing кольцевой буфер значений одного ряда
+type seriesRing struct {
+	Samples []Sample
+	Next    int
+	Count   int
+}
+
+func (r *seriesRing) add(s Sample) {
+	r.Samples[r.Next] = s
+	r.Next = (r.Next + 1) % len(r.Samples)
+	if r.Count < len(r.Samples) {
+		r.Count++
+	}
+}
+
+// ordered возвращает значения от старых к новым
+func (r *seriesRing) ordered() []Sample {
+	out := make([]Sample, 0, r.Count)
+	start := (r.Next - r.Count + len(r.Samples)) % len(r.Samples)
+	for i := 0; i < r.Count; i++ {
+		out = append(out, r.Samples[(start+i)%len(r.Samples)])
+	}
+	return out
+}
+
+// MetricsStore история потребления узлов и namespace с ограниченной глубиной
+type MetricsStore struct {
+	mu     sync.RWMutex
+	cfg    MetricsConfig
+	size   int
+	series map[string]*seriesRing
+}
+
+// metricsStore история метрик, задаётся в main
+var metricsStore = NewMetricsStore(DefaultMetricsConfig())
+
+// NewMetricsStore создаёт хранилище и загружает сохранённую историю, если задан файл
+func NewMetricsStore(cfg MetricsConfig) *MetricsStore {
+	size := int(cfg.Retention / cfg.SampleInterval)
+	if size < 2 {
+		size = 2
+	}
+	s := &MetricsStore{cfg: cfg, size: size, series: make(map[string]*seriesRing)}
+	if cfg.Path == "" {
+		return s
+	}
+	f, err := os.Open(cfg.Path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ Не удалось прочитать историю метрик %s: %v", cfg.Path, err)
+		}
+		return s
+	}
+	defer f.Close()
+
+	var saved map[string]*seriesRing
+	if err := gob.NewDecoder(f).Decode(&saved); err != nil {
+		log.Printf("⚠️ Некорректный файл истории метрик %s: %v", cfg.Path, err)
+		return s
+	}
+	for key, r := range saved {
+		if len(r.Samples) == 0 {
+			continue
+		}
+		// Размер буфера мог измениться вместе с METRICS_RETENTION
+		for _, sample := range r.ordered() {
+			s.add(key, sample)
+		}
+	}
+	return s
+}
+
+// seriesKey ключ ряда: кластер, вид объекта (node или ns), имя и ресурс (cpu или mem)
+func seriesKey(cluster, kind, name, resource string) string {
+	return cluster + "/" + kind + "/" + name + "/" + resource
+}
+
+// Add добавляет значение в ряд
+func (s *MetricsStore) Add(key string, t time.Time, v float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(key, Sample{Time: t.Unix(), Value: v})
+}
+
+func (s *MetricsStore) add(key string, sample Sample) {
+	r, ok := s.series[key]
+	if !ok {
+		r = &seriesRing{Samples: make([]Sample, s.size)}
+		s.series[key] = r
+	}
+	r.add(sample)
+}
+
+// Has сообщает, есть ли данные по ряду
+func (s *MetricsStore) Has(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.series[key]
+	return ok
+}
+
+// Range возвращает значения ряда начиная с since
+func (s *MetricsStore) Range(key string, since time.Time) []Sample {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.series[key]
+	if !ok {
+		return nil
+	}
+	all := r.ordered()
+	i := sort.Search(len(all), func(i int) bool { return all[i].Time >= since.Unix() })
+	return all[i:]
+}
+
+// Save атомарно записывает историю на диск, удаляя ряды без свежих значений
+func (s *MetricsStore) Save() error {
+	if s.cfg.Path == "" {
+		return nil
+	}
+	s.mu.Lock()
+	cutoff := time.Now().Add(-s.cfg.Retention).Unix()
+	for key, r := range s.series {
+		last := r.Samples[(r.Next-1+len(r.Samples))%len(r.Samples)]
+		if r.Count == 0 || last.Time < cutoff {
+			delete(s.series, key)
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.cfg.Path), ".metrics-*")
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	err = gob.NewEncoder(tmp).Encode(s.series)
+	s.mu.Unlock()
+
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.cfg.Path)
+}
+
+// Start периодически снимает метрики всех кластеров и сохраняет историю
+func (s *MetricsStore) Start(ctx context.Context, targets []*Cluster) {
+	sample := time.NewTicker(s.cfg.SampleInterval)
+	defer sample.Stop()
+	flush := time.NewTicker(s.cfg.FlushInterval)
+	defer flush.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.Save(); err != nil {
+				log.Printf("⚠️ Не удалось сохранить историю метрик: %v", err)
+			}
+			return
+		case <-sample.C:
+			for _, c := range targets {
+				if err := s.Collect(ctx, c); err != nil {
+					log.Printf("⚠️ [%s] Ошибка сбора истории метрик: %v", c.Name, err)
+				}
+			}
+		case <-flush.C:
+			if err := s.Save(); err != nil {
+				log.Printf("⚠️ Не удалось сохранить историю метрик: %v", err)
+			}
+		}
+	}
+}
+
+// Collect записывает текущее потребление узлов и namespace кластера
+func (s *MetricsStore) Collect(ctx context.Context, cluster *Cluster) error {
+	if !cluster.HasMetrics() {
+		return nil
+	}
+	now := time.Now()
+	nodeMetrics, err := getNodeMetrics(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	for name, m := range nodeMetrics {
+		s.Add(seriesKey(cluster.Name, "node", name, "cpu"), now, float64(m.CPU)/1000)
+		s.Add(seriesKey(cluster.Name, "node", name, "mem"), now, float64(m.Memory))
+	}
+
+	podMetrics, err := cluster.Metrics.MetricsV1beta1().PodMetricses("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	byNS := make(map[string]struct{ CPU, Memory int64 })
+	for _, pm := range podMetrics.Items {
+		u := byNS[pm.Namespace]
+		for _, c := range pm.Containers {
+			u.CPU += c.Usage.Cpu().MilliValue()
+			u.Memory += c.Usage.Memory().Value()
+		}
+		byNS[pm.Namespace] = u
+	}
+	for ns, u := range byNS {
+		s.Add(seriesKey(cluster.Name, "ns", ns, "cpu"), now, float64(u.CPU)/1000)
+		s.Add(seriesKey(cluster.Name, "ns", ns, "mem"), now, float64(u.Memory))
+	}
+	return nil
+}
+
+// resolveSeries находит ряд по имени узла или namespace; допускаются префиксы node/ и ns/
+func (s *MetricsStore) resolveSeries(cluster, target, resource string) (string, bool) {
+	if kind, name, ok := strings.Cut(target, "/"); ok && (kind == "node" || kind == "ns") {
+		key := seriesKey(cluster, kind, name, resource)
+		return key, s.Has(key)
+	}
+	for _, kind := range []string{"node", "ns"} {
+		if key := seriesKey(cluster, kind, target, resource); s.Has(key) {
+			return key, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,935 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Lang язык сообщений бота
+type Lang string
+
+const (
+	LangRU Lang = "ru"
+	LangEN Lang = "en"
+)
+
+// defaultLang язык по умолчанию (BOT_LANG), задаётся в main
+var defaultLang = LangRU
+
+// parseLang приводит строку к поддерживаемому языку
+func parseLang(v string) (Lang, bool) {
+	switch Lang(strings.ToLower(strings.TrimSpace(v))) {
+	case LangRU:
+		return LangRU, true
+	case LangEN:
+		return LangEN, true
+	}
+	return "", false
+}
+
+// messages каталог сообщений по языкам
+var messages = map[Lang]map[string]string{
+	LangRU: {
+		"access_denied":   "❌ Доступ запрещён.",
+		"admin_only":      "⛔ Команда доступна только администраторам (ADMIN_USERS)",
+		"unknown_command": "Неизвестная команда. /help",
+		"error":           "Ошибка: %s",
+		"usage.restart":   "Использование: /restart <namespace> <deployment>",
+		"usage.scale":     "Использование: /scale <namespace> <deployment> <реплики>",
+		"usage.logs":      "Использование: /logs <namespace> <pod|deploy/<name>|-l selector> [-c container] [--previous] [кол-во строк]",
+		"usage.lang":      "Использование: /lang <ru|en>",
+		"usage.getpods":   "Использование: /getpods [ns|all] [--phase P] [--node N] [-l selector] [--not-ready] [--restarts>N]",
+		"lang.current":    "🌐 Язык: %s",
+		"lang.set":        "✅ Язык переключён: %s",
+		"usage.cluster":   "Переключить: /cluster <имя>",
+		"cluster.title":   "Кластеры:",
+		"cluster.active":  "(активный)",
+		"cluster.set":     "✅ Активный кластер: %s",
+		"cluster.unknown": "❌ Кластер %s не найден. Доступны: %s",
+
+		"help.title":            "Команды:",
+		"help.main.title":       "Основные команды:",
+		"help.main":             "/status — список узлов\n/getpods [ns|all] [--phase P] [--node N] [-l sel] [--not-ready] [--restarts>N] — pod-ы\n/logs <ns> <pod> [-c container] [--previous] [tail] — логи pod-а\n/logs <ns> deploy/<name> [tail] — логи всех pod-ов deployment'а\n/logs <ns> -l <selector> [tail] — логи pod-ов по селектору\n/logs-history <ns> <app> [since] — логи из Loki, включая удалённые pod-ы\n/loki <logql> [since] — поиск по логам в Loki\n/describe <ns> <pod> — подробности pod-а",
+		"help.monitoring.title": "Мониторинг:",
+		"help.monitoring":       "/monitor - статус мониторинга узлов\n/alerts - активные алерты\n/top pods [ns] [--sort cpu|mem] [--limit N] - потребление pod-ов\n/top ns - потребление по namespace\n/capacity [ns deployment] - ёмкость кластера и запас под реплики\n/chart <узел|ns> <cpu|mem> [24h] - график потребления\n/promql [--range 6h] <expr> - запрос к Prometheus\n/q [имя] - сохранённые запросы Prometheus\n/digest [расписание] - сводка по кластеру\n/certs - сертификаты и сроки действия\n/storage - MinIO: здоровье, диски и бакеты\n/pvc [ns] - заполнение PVC\n/jobs [ns] - CronJob-ы и упавшие Job-ы\n/probes - HTTP-проверки Ingress-ов и сервисов",
+		"help.manage.title":     "Управление:",
+		"help.manage":           "/restart <ns> <deployment> - перезапуск deployment'а\n/scale <ns> <deployment> <replicas> - масштабирование\n/backups [n] - бэкапы Velero\n/backup now [ns] - создать бэкап\n/restore <backup> [ns] - восстановить из бэкапа\n/vpn peers|add|remove <имя> - доступ WireGuard через Kilo\n/cordon, /uncordon <узел> - запрет и возврат планирования\n/drain <узел> [--force] - вывести узел на обслуживание\n/cronjob run <ns> <имя> - запустить CronJob вне расписания\n/cleanup [--dry-run] - удалить завершённые Job-ы, упавшие поды и старые ReplicaSet-ы",
+		"help.help.title":       "Помощь:",
+		"help.help":             "/cluster [имя] - список кластеров или выбор активного\n/lang <ru|en> - язык сообщений\n/help - показать это сообщение",
+		"btn.status":            "Статус узлов",
+		"btn.pods_all":          "Pod-ы (все)",
+		"btn.pods_ns":           "Pod-ы (%s)",
+
+		"status.title":           "СТАТУС КЛАСТЕРА",
+		"status.state":           "   📊 Статус: %s",
+		"status.os":              "   🏷️  OS: %s | Arch: %s",
+		"status.cpu":             "   🔵 CPU: %s/%s (%d%%) %s",
+		"status.memory":          "   🟠 Memory: %s/%s (%d%%) %s",
+		"status.pods":            "   📦 Pods: %d/%d запущено",
+		"status.ip":              "   🌐 IP: %s",
+		"status.age":             "   ⏰ Возраст: %s",
+		"status.summary":         "ОБЩАЯ СТАТИСТИКА",
+		"status.nodes_total":     "   🖥️  Всего узлов: %d",
+		"status.nodes_ready":     "   🟢 Готовых: %d",
+		"status.nodes_not_ready": "   🔴 Не готовых: %d",
+		"status.usage":           "Использование ресурсов:",
+
+		"status.requests_fallback": "metrics-server недоступен: вместо потребления показана сумма requests pod-ов, подробнее в /capacity",
+
+		"usage.capacity":           "Использование: /capacity или /capacity <namespace> <deployment>",
+		"capacity.title":           "Ёмкость кластера: allocatable/requests/limits/потребление",
+		"capacity.no_nodes":        "Узлы не найдены",
+		"capacity.requested":       "📥 Requests: CPU %d%%, память %d%% от allocatable",
+		"capacity.limited":         "📤 Limits: CPU %d%%, память %d%% от allocatable",
+		"capacity.used":            "🔥 Потребление: CPU %d%%, память %d%% от allocatable",
+		"capacity.no_metrics":      "🔥 Потребление: нет данных, metrics-server недоступен",
+		"capacity.legend":          "* — узел закрыт для планирования. LIM>ALLOC — overcommit по limits, HOT — потребление ≥ %d%% allocatable, PODS — почти исчерпан лимит pod-ов",
+		"capacity.fit.title":       "Запас под %s",
+		"capacity.fit.per_replica": "На реплику: CPU %s, память %s, ephemeral %s (по requests)",
+		"capacity.fit.no_requests": "⚠️ В шаблоне не заданы requests, оценка только по числу pod-ов",
+		"capacity.fit.skipped":     "не подходит: %s",
+		"capacity.fit.total":       "Поместится ещё реплик: %d",
+
+		"usage.chart":      "Использование: /chart <узел|namespace> <cpu|mem> [24h|7d]",
+		"chart.no_series":  "Нет истории для %s. История копится только при установленном metrics-server",
+		"chart.not_enough": "Для %s пока слишком мало точек за этот период",
+		"chart.unit_cores": "ядер",
+		"chart.caption":    "%s %s за %s\nmin %.2f / avg %.2f / max %.2f / сейчас %.2f %s",
+
+		"usage.promql":       "Использование: /promql [--range 6h] [--step 1m] <выражение>",
+		"prom.disabled":      "Prometheus не настроен: задайте PROMETHEUS_URL",
+		"prom.empty":         "Пустой результат",
+		"prom.unknown_query": "Запрос %s не найден",
+		"prom.saved_title":   "Сохранённые запросы, /q <имя>:",
+		"prom.range":         "за %s",
+
+		"usage.loki":         "Использование: /loki <logql> [1h|6h|2d]",
+		"usage.logs_history": "Использование: /logs-history <namespace> <app> [1h|6h|2d]",
+		"loki.disabled":      "Loki не настроен: задайте LOKI_URL",
+		"loki.empty":         "Нет строк по запросу %s за %s",
+		"loki.truncated":     "⚠️ Показаны последние %d строк, сузьте период или запрос",
+
+		"usage.digest":                 "Использование: /digest [расписание], расписания: %s",
+		"digest.title":                 "Дайджест %s",
+		"digest.failed":                "Не удалось собрать дайджест: %s",
+		"digest.nodes":                 "Узлы: %d/%d готовы",
+		"digest.node_usage":            " CPU %d%% MEM %d%%",
+		"digest.pods_ok":               "Все pod-ы работают",
+		"digest.pods_not_running":      "Не работают pod-ов: %d",
+		"digest.more":                  "…и ещё %d",
+		"digest.restarts":              "Больше всего рестартов:",
+		"digest.disks":                 "Свободно на дисках:",
+		"digest.disks_none":            "Prometheus не вернул данных о дисках",
+		"digest.disk_pressure":         "DiskPressure на узлах: %s",
+		"digest.disk_pressure_none":    "Узлов с DiskPressure нет",
+		"digest.certs":                 "Сертификаты:",
+		"digest.certs_ok":              "Сертификатов, истекающих в ближайшие %s, нет",
+		"digest.cert_expires":          "истекает через %s",
+		"digest.cert_expired":          "истёк",
+		"digest.cert_not_ready":        "не готов",
+		"digest.backup":                "Последний бэкап %s: %s %s, %s назад",
+		"digest.backup_issues":         "(ошибок %d, предупреждений %d)",
+		"digest.backup_none":           "Бэкапов Velero нет",
+		"digest.first":                 "Первая сводка по этому расписанию, сравнивать не с чем",
+		"digest.no_changes":            "Изменений с %s нет",
+		"digest.changes":               "Изменения с %s:",
+		"digest.problems":              "Не удалось собрать: %s",
+		"digest.change.node_added":     "➕ узел %s",
+		"digest.change.node_removed":   "➖ узел %s",
+		"digest.change.node_not_ready": "🔴 узел %s: %s",
+		"digest.change.node_ready":     "🟢 узел %s снова Ready",
+		"digest.change.pod_failing":    "⚠️ %s: %s",
+		"digest.change.pod_recovered":  "✅ %s снова работает",
+		"digest.change.restarts":       "🔁 %s: %s рестартов",
+		"digest.change.backup_new":     "💾 новый бэкап %s: %s",
+
+		"usage.backups":             "Использование: /backups [число]",
+		"usage.backup":              "Использование: /backup now [namespace]",
+		"usage.restore":             "Использование: /restore <бэкап> [namespace]",
+		"btn.confirm":               "✅ Подтвердить",
+		"btn.cancel":                "✖️ Отмена",
+		"confirm.expires":           "Кнопки действуют %s",
+		"confirm.expired":           "⌛ Запрос устарел, повторите команду",
+		"confirm.cancelled":         "✖️ Отменено",
+		"confirm.not_yours":         "Подтвердить может только автор команды",
+		"velero.not_installed":      "Velero в кластере не установлен (нет API velero.io/v1)",
+		"velero.backups_title":      "Бэкапы Velero: %d из %d",
+		"velero.no_backups":         "Бэкапов нет",
+		"velero.backup":             "Бэкап:",
+		"velero.schedule":           "Расписание:",
+		"velero.namespaces":         "Namespace:",
+		"velero.expected":           "Ожидался:",
+		"velero.last_backup":        "Последний бэкап:",
+		"velero.errors":             "Ошибок: %d, предупреждений: %d",
+		"velero.reason":             "Причина: %s",
+		"velero.never":              "не было",
+		"velero.ago":                "%s назад",
+		"velero.failed.title":       "BACKUP: бэкап не удался",
+		"velero.failed.text":        "Подробности: velero backup describe %s --details",
+		"velero.missed.title":       "BACKUP: бэкап по расписанию не выполнен",
+		"velero.all_namespaces":     "все namespace",
+		"velero.all_from_backup":    "все из бэкапа",
+		"velero.backup_created":     "Бэкап создан:",
+		"velero.follow":             "Статус: /backups",
+		"velero.restore_not_ready":  "Бэкап %s в фазе %s, восстановить из него нельзя",
+		"velero.restore_ns_missing": "В бэкапе %s нет namespace %s, есть: %s",
+		"velero.restore_confirm":    "Восстановить из бэкапа?",
+		"velero.restore_note":       "Существующие объекты Velero не перезаписывает.",
+		"velero.restore_created":    "Восстановление запущено:",
+		"velero.restore_follow":     "Статус: velero restore describe %s -n %s",
+
+		"certs.title":           "Сертификаты: %d",
+		"certs.none":            "Сертификатов не найдено",
+		"certs.no_manager":      "cert-manager не установлен, показаны только TLS-секреты",
+		"certs.legend":          "DAYS — полных суток до истечения, ! — меньше %s",
+		"certs.cert":            "Сертификат:",
+		"certs.issuer":          "Издатель:",
+		"certs.not_after":       "Действует до:",
+		"certs.days_left":       "Осталось дней: %d",
+		"certs.reason":          "Причина: %s",
+		"certs.expiring.title":  "CERT: сертификат скоро истекает",
+		"certs.expired.title":   "CERT: сертификат истёк",
+		"certs.not_ready.title": "CERT: сертификат не готов",
+		"certs.not_ready.text":  "Подробности: kubectl describe certificate %s -n %s",
+		"certs.ready.title":     "CERT: сертификат снова готов",
+
+		"upgrade.started.title":   "UPGRADE: обновление узла началось",
+		"upgrade.completed.title": "UPGRADE: узел обновлён",
+		"upgrade.failed.title":    "UPGRADE: обновление узла не удалось",
+		"upgrade.plan":            "План:",
+		"upgrade.kubelet":         "Kubelet:",
+		"upgrade.progress":        "Прогресс плана:",
+		"upgrade.plan_done":       "🎉 План %s применён на всех узлах",
+		"upgrade.suppressed":      "Алерты о недоступности узла на время обновления отключены",
+
+		"usage.vpn":          "Использование: /vpn peers | /vpn add <имя> | /vpn remove <имя>",
+		"vpn.not_installed":  "Kilo (kilo.squat.ai/v1alpha1) не установлен в кластере",
+		"vpn.invalid_name":   "Некорректное имя %q: %s",
+		"vpn.exists":         "Peer %s уже существует",
+		"vpn.range_full":     "В диапазоне %s нет свободных адресов",
+		"vpn.no_nodes":       "Ни у одного узла нет аннотации %s — Kilo не запущен?",
+		"vpn.peers_title":    "VPN-пиры: %d",
+		"vpn.no_peers":       "Peer-ов нет",
+		"vpn.added":          "Peer создан:",
+		"vpn.sent_private":   "Конфиг и QR-код отправлены в личные сообщения",
+		"vpn.private_failed": "Не удалось отправить конфиг в личные сообщения (%v), Peer удалён. Напишите боту /start в личном чате и повторите",
+		"vpn.config_caption": "WireGuard-конфиг %s (%s). Приватный ключ нигде не сохранён, храните файл в надёжном месте",
+		"vpn.qr_caption":     "QR-код для приложения WireGuard",
+		"vpn.remove_confirm": "Отозвать VPN-доступ?",
+		"vpn.peer":           "Peer:",
+		"vpn.address":        "Адрес:",
+		"vpn.owner":          "Выдал:",
+		"vpn.removed":        "Peer удалён:",
+
+		"minio.not_configured": "MinIO не настроен: задайте MINIO_URL",
+		"minio.title":          "MinIO",
+		"minio.healthy":        "Работает, кворум на запись есть",
+		"minio.no_quorum":      "Отвечает, но нет кворума: %s",
+		"minio.unreachable":    "Недоступен: %s",
+		"minio.offline":        "Недоступно серверов: %d, дисков: %d",
+		"minio.metrics_error":  "Метрики недоступны: %s (нужен MINIO_METRICS_TOKEN или MINIO_PROMETHEUS_AUTH_TYPE=public)",
+		"minio.capacity":       "Свободно:",
+		"minio.pvc_used":       "занято бакетами %s из %s (%.1f%%)",
+		"minio.buckets":        "Бакеты: %d",
+		"minio.no_buckets":     "Бакетов нет",
+		"minio.free":           "Свободно:",
+		"minio.disk":           "Диск:",
+		"minio.down.title":     "MINIO: хранилище недоступно",
+		"minio.down.text":      "Бэкапы Velero в MinIO сейчас не пишутся",
+		"minio.up.title":       "MINIO: хранилище снова доступно",
+		"minio.disk_low.title": "MINIO: заканчивается место на диске",
+		"minio.disk_ok.title":  "MINIO: место на диске освободилось",
+		"minio.pvc_low.title":  "MINIO: PVC почти заполнен",
+		"minio.pvc_ok.title":   "MINIO: место в PVC освободилось",
+
+		"usage.pvc":         "Использование: /pvc [namespace]",
+		"pvc.title":         "PVC: %d",
+		"pvc.title_ns":      "PVC в %s: %d",
+		"pvc.none":          "PVC нет",
+		"pvc.no_stats":      "Без данных kubelet-а: %d (hostPath и local-path не отдают заполнение)",
+		"pvc.pv_problems":   "PV, требующие внимания: %d",
+		"pvc.used":          "Занято:",
+		"pvc.claim":         "Claim:",
+		"pvc.pending_for":   "В Pending %s",
+		"pvc.warn.title":    "PVC: том заполняется",
+		"pvc.crit.title":    "PVC: том почти заполнен",
+		"pvc.ok.title":      "PVC: заполнение в норме",
+		"pvc.inodes.title":  "PVC: заканчиваются inode",
+		"pvc.pending.title": "PVC: том не выделен",
+		"pvc.bound.title":   "PVC: том выделен",
+		"pv.released.title": "PV: том освобождён (Released)",
+		"pv.released.text":  "PVC удалён, данные остались на томе. Удалите PV или очистите claimRef: kubectl patch pv %s -p '{\"spec\":{\"claimRef\":null}}'",
+		"pv.failed.title":   "PV: ошибка освобождения тома (Failed)",
+		"pv.failed.text":    "Provisioner не смог удалить или очистить том, проверьте его логи",
+
+		"usage.cordon":         "Использование: /cordon <узел>",
+		"usage.uncordon":       "Использование: /uncordon <узел>",
+		"usage.drain":          "Использование: /drain <узел> [--force] [--timeout 10m]",
+		"node.label":           "Узел:",
+		"node.cordon_confirm":  "Запретить планирование подов на узел?",
+		"node.cordoned":        "Планирование на узел запрещено:",
+		"node.uncordoned":      "Узел снова принимает поды, алерты по нему включены:",
+		"drain.confirm":        "Вывести узел на обслуживание?",
+		"drain.evict":          "Будет вытеснено подов:",
+		"drain.skip":           "Останутся на узле:",
+		"drain.skip_counts":    "DaemonSet — %d, статических — %d",
+		"drain.timeout":        "Таймаут:",
+		"drain.force":          "Поды без контроллера будут удалены без пересоздания: %s",
+		"drain.silence_note":   "Узел будет помечен unschedulable, алерты по нему отключатся до /uncordon. Вытеснение идёт через Eviction API с учётом PodDisruptionBudget",
+		"drain.unmanaged":      "На узле есть поды без контроллера, после вытеснения они не пересоздадутся: %s. Повторите с --force, если это допустимо",
+		"drain.busy":           "drain узла %s уже выполняется",
+		"drain.started":        "Узел выведен из планирования, вытесняем поды:",
+		"drain.progress_title": "drain %s",
+		"drain.done_title":     "drain %s завершён",
+		"drain.timeout_title":  "drain %s: истёк таймаут",
+		"drain.failed_title":   "drain %s: не все поды вытеснены",
+		"drain.counts":         "Вытеснено %d из %d",
+		"drain.more":           "…и ещё %d",
+		"drain.uncordon_hint":  "Узел остаётся unschedulable, алерты по нему отключены. После работ: /uncordon %s",
+
+		"usage.cronjob":      "Использование: /cronjob run <namespace> <имя>",
+		"jobs.cron_title":    "CronJob-ы: %d",
+		"jobs.cron_title_ns": "CronJob-ы в %s: %d",
+		"jobs.no_cron":       "CronJob-ов нет",
+		"jobs.jobs_title":    "Job-ы: выполняются или упали — %d, успешно завершены — %d",
+		"jobs.no_jobs":       "Активных и упавших Job-ов нет",
+		"jobs.more":          "…и ещё %d",
+		"jobs.failed.title":  "Job завершился с ошибкой",
+		"jobs.attempts":      "Неудачных попыток:",
+		"jobs.missed.title":  "CronJob не отработал по расписанию",
+		"jobs.last_success":  "Последний успешный запуск:",
+		"jobs.active_now":    "Сейчас выполняется Job-ов: %d",
+		"jobs.started":       "Запущен Job",
+		"jobs.follow":        "Следить за выполнением: /jobs %s",
+
+		"usage.cleanup":       "Использование: /cleanup [--dry-run]",
+		"cleanup.nothing":     "Удалять нечего",
+		"cleanup.title":       "Можно удалить объектов: %d",
+		"cleanup.jobs":        "Успешные Job-ы старше %s",
+		"cleanup.pods":        "Поды в фазе Failed",
+		"cleanup.replicasets": "ReplicaSet-ы без реплик вне истории ревизий",
+		"cleanup.more":        "…и ещё %d",
+		"cleanup.dry_run":     "Пробный запуск, ничего не удалено. Удалить: /cleanup",
+		"cleanup.done":        "Удалено объектов: %d",
+		"cleanup.scheduled":   "Плановая очистка (CLEANUP_SCHEDULE): %s",
+
+		"probes.title":           "HTTP-проверки: %d, не проходят — %d",
+		"probes.none":            "Проверять нечего: нет Ingress-ов с хостами, сервисов с аннотацией go-bot/probe и PROBE_TARGETS",
+		"probes.url":             "Адрес:",
+		"probes.source":          "Источник:",
+		"probes.failures":        "Неудач подряд:",
+		"probes.latency":         "Задержка:",
+		"probes.down_for":        "Не работало:",
+		"probes.failed.title":    "HTTP-проверка не проходит",
+		"probes.recovered.title": "HTTP-проверка снова проходит",
+		"probes.reason.body":     "в ответе нет %q",
+		"probes.reason.latency":  "задержка %s > %s",
+		"probes.reason.tls":      "TLS-сертификат истекает %s",
+
+		"usage.top":      "Использование: /top pods [ns] [--sort cpu|mem] [--limit N] или /top ns [--sort cpu|mem]",
+		"top.pods_title": "Потребление pod-ов (%s), сортировка по %s",
+		"top.ns_title":   "Потребление по namespace, сортировка по %s",
+		"top.shown":      "Показано %d из %d",
+		"top.none":       "Нет данных о потреблении запущенных pod-ов",
+		"top.no_metrics": "⚠️ metrics-server не установлен или недоступен: %s",
+		"top.legend":     "CPU>REQ / MEM>REQ — потребление выше requests, MEM~LIM — память ≥ %d%% лимита",
+
+		"monitor.title":     "Статус мониторинга узлов",
+		"monitor.no_data":   "ℹ️ Нет данных о узлах",
+		"monitor.state":     "   Статус: %s",
+		"monitor.last_seen": "   Последняя проверка: %s назад",
+		"monitor.notified":  "   ⚠️ Уведомление отправлено",
+
+		"alerts.title":    "Активные алерты",
+		"alerts.problem":  "   Проблема: %s",
+		"alerts.duration": "   Длительность: %s",
+		"alerts.none":     "✅ Активных алертов нет",
+
+		"alert.node":              "Узел:",
+		"alert.status":            "Статус:",
+		"alert.node_down.title":   "ALERT: узел недоступен",
+		"alert.downtime":          "Простой:",
+		"alert.node_down.text":    "⚠️ Узел недоступен более %s!",
+		"alert.recovery.title":    "RECOVERY: узел снова в строю",
+		"alert.recovery.text":     "🎉 Узел восстановил работу!",
+		"alert.missing.title":     "CRITICAL: узел пропал",
+		"alert.missing_for":       "Отсутствует:",
+		"alert.node_missing.text": "🚨 Узел отсутствует в кластере более %s!",
+		"alert.reason":            "Причина: %s",
+		"alert.expected":          "Ожидался:",
+		"alert.never":             "не было",
+		"alert.ago":               "%s назад",
+
+		"pods.header":  "📦 Pod-ы (%s): %d, страница %d/%d",
+		"pods.filters": "Фильтры: %s",
+		"pods.none":    "Pod-ы не найдены",
+		"pods.expired": "Список устарел, выполните /getpods заново",
+		"btn.prev":     "◀️ Назад",
+		"btn.next":     "Вперёд ▶️",
+
+		"restart.done":       "✅ Deployment %s/%s перезапущен",
+		"scale.bad_replicas": "Реплики должны быть числом",
+		"scale.done":         "✅ Deployment %s/%s → %s",
+
+		"logs.error":            "Ошибка логов: %s",
+		"logs.selector_error":   "Ошибка селектора: %s",
+		"logs.no_pods":          "Pod-ы по селектору %s не найдены",
+		"logs.none_fetched":     "Не удалось получить логи ни одного контейнера",
+		"logs.empty":            "Логи пустые",
+		"logs.choose_container": "Pod %s содержит несколько контейнеров, выберите нужный:",
+		"logs.all_containers":   "Все контейнеры",
+
+		"output.file": "Результат в файле (%s)",
+
+		"usage.describe":            "Использование: /describe <namespace> <pod>",
+		"describe.namespace":        "Namespace: %s",
+		"describe.node":             "Узел: %s",
+		"describe.status":           "Статус: %s (фаза %s)",
+		"describe.ip":               "IP: %s",
+		"describe.qos":              "QoS: %s",
+		"describe.started":          "Запущен: %s назад",
+		"describe.owner":            "Владелец: %s",
+		"describe.conditions":       "Условия:",
+		"describe.containers":       "Контейнеры:",
+		"describe.state_running":    "Состояние: Running, %s",
+		"describe.state_waiting":    "Состояние: Waiting, %s",
+		"describe.state_terminated": "Состояние: Terminated, %s (код %d)",
+		"describe.last_termination": "Последнее завершение: %s (код %d), %s назад",
+		"describe.ready_restarts":   "Готов: %t, рестартов: %d",
+		"describe.requests":         "Requests: %s",
+		"describe.limits":           "Limits: %s",
+		"describe.volumes":          "Тома:",
+		"describe.events":           "События (последние %d):",
+		"describe.no_events":        "Событий нет",
+		"describe.actions":          "Действия:",
+		"btn.logs":                  "📜 Логи",
+		"btn.logs_previous":         "⏮ Предыдущие логи",
+
+		"dur.short.day":    "%dд",
+		"dur.short.hour":   "%dч",
+		"dur.short.minute": "%dм",
+	},
+	LangEN: {
+		"access_denied":   "❌ Access denied.",
+		"admin_only":      "⛔ This command is for administrators only (ADMIN_USERS)",
+		"unknown_command": "Unknown command. /help",
+		"error":           "Error: %s",
+		"usage.restart":   "Usage: /restart <namespace> <deployment>",
+		"usage.scale":     "Usage: /scale <namespace> <deployment> <replicas>",
+		"usage.logs":      "Usage: /logs <namespace> <pod|deploy/<name>|-l selector> [-c container] [--previous] [lines]",
+		"usage.lang":      "Usage: /lang <ru|en>",
+		"usage.getpods":   "Usage: /getpods [ns|all] [--phase P] [--node N] [-l selector] [--not-ready] [--restarts>N]",
+		"lang.current":    "🌐 Language: %s",
+		"lang.set":        "✅ Language set: %s",
+		"usage.cluster":   "Switch: /cluster <name>",
+		"cluster.title":   "Clusters:",
+		"cluster.active":  "(active)",
+		"cluster.set":     "✅ Active cluster: %s",
+		"cluster.unknown": "❌ Cluster %s not found. Available: %s",
+
+		"help.title":            "Commands:",
+		"help.main.title":       "Main commands:",
+		"help.main":             "/status — node list\n/getpods [ns|all] [--phase P] [--node N] [-l sel] [--not-ready] [--restarts>N] — pods\n/logs <ns> <pod> [-c container] [--previous] [tail] — pod logs\n/logs <ns> deploy/<name> [tail] — logs of all deployment pods\n/logs <ns> -l <selector> [tail] — logs of pods matching a selector\n/logs-history <ns> <app> [since] — logs from Loki, including deleted pods\n/loki <logql> [since] — log search in Loki\n/describe <ns> <pod> — pod details",
+		"help.monitoring.title": "Monitoring:",
+		"help.monitoring":       "/monitor - node monitoring status\n/alerts - active alerts\n/top pods [ns] [--sort cpu|mem] [--limit N] - pod usage\n/top ns - usage by namespace\n/capacity [ns deployment] - cluster capacity and replica headroom\n/chart <node|ns> <cpu|mem> [24h] - usage chart\n/promql [--range 6h] <expr> - Prometheus query\n/q [name] - saved Prometheus queries\n/digest [schedule] - cluster digest\n/certs - certificates and expiry\n/storage - MinIO health, disks and buckets\n/pvc [ns] - PVC usage\n/jobs [ns] - CronJobs and failed Jobs\n/probes - HTTP probes of Ingresses and Services",
+		"help.manage.title":     "Management:",
+		"help.manage":           "/restart <ns> <deployment> - restart a deployment\n/scale <ns> <deployment> <replicas> - scale a deployment\n/backups [n] - Velero backups\n/backup now [ns] - create a backup\n/restore <backup> [ns] - restore from a backup\n/vpn peers|add|remove <name> - WireGuard access via Kilo\n/cordon, /uncordon <node> - disable and re-enable scheduling\n/drain <node> [--force] - put a node into maintenance\n/cronjob run <ns> <name> - run a CronJob now\n/cleanup [--dry-run] - delete completed Jobs, failed pods and old ReplicaSets",
+		"help.help.title":       "Help:",
+		"help.help":             "/cluster [name] - list clusters or switch the active one\n/lang <ru|en> - message language\n/help - show this message",
+		"btn.status":            "Node status",
+		"btn.pods_all":          "Pods (all)",
+		"btn.pods_ns":           "Pods (%s)",
+
+		"status.title":           "CLUSTER STATUS",
+		"status.state":           "   📊 Status: %s",
+		"status.os":              "   🏷️  OS: %s | Arch: %s",
+		"status.cpu":             "   🔵 CPU: %s/%s (%d%%) %s",
+		"status.memory":          "   🟠 Memory: %s/%s (%d%%) %s",
+		"status.pods":            "   📦 Pods: %d/%d running",
+		"status.ip":              "   🌐 IP: %s",
+		"status.age":             "   ⏰ Age: %s",
+		"status.summary":         "SUMMARY",
+		"status.nodes_total":     "   🖥️  Total nodes: %d",
+		"status.nodes_ready":     "   🟢 Ready: %d",
+		"status.nodes_not_ready": "   🔴 Not ready: %d",
+		"status.usage":           "Resource usage:",
+
+		"status.requests_fallback": "metrics-server unavailable: summed pod requests are shown instead of usage, see /capacity",
+
+		"usage.capacity":           "Usage: /capacity or /capacity <namespace> <deployment>",
+		"capacity.title":           "Cluster capacity: allocatable/requests/limits/usage",
+		"capacity.no_nodes":        "No nodes found",
+		"capacity.requested":       "📥 Requests: CPU %d%%, memory %d%% of allocatable",
+		"capacity.limited":         "📤 Limits: CPU %d%%, memory %d%% of allocatable",
+		"capacity.used":            "🔥 Usage: CPU %d%%, memory %d%% of allocatable",
+		"capacity.no_metrics":      "🔥 Usage: no data, metrics-server unavailable",
+		"capacity.legend":          "* — node is cordoned. LIM>ALLOC — limits overcommit, HOT — usage ≥ %d%% of allocatable, PODS — pod limit nearly exhausted",
+		"capacity.fit.title":       "Headroom for %s",
+		"capacity.fit.per_replica": "Per replica: CPU %s, memory %s, ephemeral %s (by requests)",
+		"capacity.fit.no_requests": "⚠️ The template has no requests, estimate is by pod count only",
+		"capacity.fit.skipped":     "not eligible: %s",
+		"capacity.fit.total":       "Additional replicas that fit: %d",
+
+		"usage.chart":      "Usage: /chart <node|namespace> <cpu|mem> [24h|7d]",
+		"chart.no_series":  "No history for %s. History is collected only when metrics-server is installed",
+		"chart.not_enough": "Not enough data points for %s in this period yet",
+		"chart.unit_cores": "cores",
+		"chart.caption":    "%s %s over %s\nmin %.2f / avg %.2f / max %.2f / now %.2f %s",
+
+		"usage.promql":       "Usage: /promql [--range 6h] [--step 1m] <expression>",
+		"prom.disabled":      "Prometheus is not configured: set PROMETHEUS_URL",
+		"prom.empty":         "Empty result",
+		"prom.unknown_query": "Query %s not found",
+		"prom.saved_title":   "Saved queries, /q <name>:",
+		"prom.range":         "over %s",
+
+		"usage.loki":         "Usage: /loki <logql> [1h|6h|2d]",
+		"usage.logs_history": "Usage: /logs-history <namespace> <app> [1h|6h|2d]",
+		"loki.disabled":      "Loki is not configured: set LOKI_URL",
+		"loki.empty":         "No lines for %s over %s",
+		"loki.truncated":     "⚠️ Showing the last %d lines, narrow the period or the query",
+
+		"usage.digest":                 "Usage: /digest [schedule], schedules: %s",
+		"digest.title":                 "Digest %s",
+		"digest.failed":                "Failed to build the digest: %s",
+		"digest.nodes":                 "Nodes: %d/%d ready",
+		"digest.node_usage":            " CPU %d%% MEM %d%%",
+		"digest.pods_ok":               "All pods are running",
+		"digest.pods_not_running":      "Pods not running: %d",
+		"digest.more":                  "…and %d more",
+		"digest.restarts":              "Most restarts:",
+		"digest.disks":                 "Free disk space:",
+		"digest.disks_none":            "Prometheus returned no disk data",
+		"digest.disk_pressure":         "DiskPressure on nodes: %s",
+		"digest.disk_pressure_none":    "No nodes under DiskPressure",
+		"digest.certs":                 "Certificates:",
+		"digest.certs_ok":              "No certificates expiring within %s",
+		"digest.cert_expires":          "expires in %s",
+		"digest.cert_expired":          "expired",
+		"digest.cert_not_ready":        "not ready",
+		"digest.backup":                "Last backup %s: %s %s, %s ago",
+		"digest.backup_issues":         "(%d errors, %d warnings)",
+		"digest.backup_none":           "No Velero backups",
+		"digest.first":                 "First digest for this schedule, nothing to compare with",
+		"digest.no_changes":            "No changes since %s",
+		"digest.changes":               "Changes since %s:",
+		"digest.problems":              "Could not collect: %s",
+		"digest.change.node_added":     "➕ node %s",
+		"digest.change.node_removed":   "➖ node %s",
+		"digest.change.node_not_ready": "🔴 node %s: %s",
+		"digest.change.node_ready":     "🟢 node %s is Ready again",
+		"digest.change.pod_failing":    "⚠️ %s: %s",
+		"digest.change.pod_recovered":  "✅ %s is running again",
+		"digest.change.restarts":       "🔁 %s: %s restarts",
+		"digest.change.backup_new":     "💾 new backup %s: %s",
+
+		"usage.backups":             "Usage: /backups [count]",
+		"usage.backup":              "Usage: /backup now [namespace]",
+		"usage.restore":             "Usage: /restore <backup> [namespace]",
+		"btn.confirm":               "✅ Confirm",
+		"btn.cancel":                "✖️ Cancel",
+		"confirm.expires":           "Buttons are valid for %s",
+		"confirm.expired":           "⌛ Request expired, run the command again",
+		"confirm.cancelled":         "✖️ Cancelled",
+		"confirm.not_yours":         "Only the author of the command can confirm it",
+		"velero.not_installed":      "Velero is not installed in the cluster (no velero.io/v1 API)",
+		"velero.backups_title":      "Velero backups: %d of %d",
+		"velero.no_backups":         "No backups",
+		"velero.backup":             "Backup:",
+		"velero.schedule":           "Schedule:",
+		"velero.namespaces":         "Namespaces:",
+		"velero.expected":           "Expected at:",
+		"velero.last_backup":        "Last backup:",
+		"velero.errors":             "Errors: %d, warnings: %d",
+		"velero.reason":             "Reason: %s",
+		"velero.never":              "never",
+		"velero.ago":                "%s ago",
+		"velero.failed.title":       "BACKUP: backup failed",
+		"velero.failed.text":        "Details: velero backup describe %s --details",
+		"velero.missed.title":       "BACKUP: scheduled backup did not run",
+		"velero.all_namespaces":     "all namespaces",
+		"velero.all_from_backup":    "everything in the backup",
+		"velero.backup_created":     "Backup created:",
+		"velero.follow":             "Status: /backups",
+		"velero.restore_not_ready":  "Backup %s is in phase %s and cannot be restored",
+		"velero.restore_ns_missing": "Backup %s has no namespace %s, it has: %s",
+		"velero.restore_confirm":    "Restore from backup?",
+		"velero.restore_note":       "Velero does not overwrite existing objects.",
+		"velero.restore_created":    "Restore started:",
+		"velero.restore_follow":     "Status: velero restore describe %s -n %s",
+
+		"certs.title":           "Certificates: %d",
+		"certs.none":            "No certificates found",
+		"certs.no_manager":      "cert-manager is not installed, only TLS secrets are shown",
+		"certs.legend":          "DAYS — whole days until expiry, ! — less than %s",
+		"certs.cert":            "Certificate:",
+		"certs.issuer":          "Issuer:",
+		"certs.not_after":       "Valid until:",
+		"certs.days_left":       "Days left: %d",
+		"certs.reason":          "Reason: %s",
+		"certs.expiring.title":  "CERT: certificate expires soon",
+		"certs.expired.title":   "CERT: certificate expired",
+		"certs.not_ready.title": "CERT: certificate is not ready",
+		"certs.not_ready.text":  "Details: kubectl describe certificate %s -n %s",
+		"certs.ready.title":     "CERT: certificate is ready again",
+
+		"upgrade.started.title":   "UPGRADE: node upgrade started",
+		"upgrade.completed.title": "UPGRADE: node upgraded",
+		"upgrade.failed.title":    "UPGRADE: node upgrade failed",
+		"upgrade.plan":            "Plan:",
+		"upgrade.kubelet":         "Kubelet:",
+		"upgrade.progress":        "Plan progress:",
+		"upgrade.plan_done":       "🎉 Plan %s is applied on all nodes",
+		"upgrade.suppressed":      "Node-down alerts are muted during the upgrade",
+
+		"usage.vpn":          "Usage: /vpn peers | /vpn add <name> | /vpn remove <name>",
+		"vpn.not_installed":  "Kilo (kilo.squat.ai/v1alpha1) is not installed in the cluster",
+		"vpn.invalid_name":   "Invalid name %q: %s",
+		"vpn.exists":         "Peer %s already exists",
+		"vpn.range_full":     "No free addresses left in %s",
+		"vpn.no_nodes":       "No node has the %s annotation — is Kilo running?",
+		"vpn.peers_title":    "VPN peers: %d",
+		"vpn.no_peers":       "No peers",
+		"vpn.added":          "Peer created:",
+		"vpn.sent_private":   "The config and QR code were sent in a private message",
+		"vpn.private_failed": "Could not send the config privately (%v), the peer was removed. Send /start to the bot in a private chat and try again",
+		"vpn.config_caption": "WireGuard config %s (%s). The private key is not stored anywhere, keep this file safe",
+		"vpn.qr_caption":     "QR code for the WireGuard app",
+		"vpn.remove_confirm": "Revoke VPN access?",
+		"vpn.peer":           "Peer:",
+		"vpn.address":        "Address:",
+		"vpn.owner":          "Issued by:",
+		"vpn.removed":        "Peer removed:",
+
+		"minio.not_configured": "MinIO is not configured: set MINIO_URL",
+		"minio.title":          "MinIO",
+		"minio.healthy":        "Up, write quorum available",
+		"minio.no_quorum":      "Responding but no quorum: %s",
+		"minio.unreachable":    "Unreachable: %s",
+		"minio.offline":        "Offline servers: %d, disks: %d",
+		"minio.metrics_error":  "Metrics unavailable: %s (set MINIO_METRICS_TOKEN or MINIO_PROMETHEUS_AUTH_TYPE=public)",
+		"minio.capacity":       "Free:",
+		"minio.pvc_used":       "buckets use %s of %s (%.1f%%)",
+		"minio.buckets":        "Buckets: %d",
+		"minio.no_buckets":     "No buckets",
+		"minio.free":           "Free:",
+		"minio.disk":           "Disk:",
+		"minio.down.title":     "MINIO: storage is down",
+		"minio.down.text":      "Velero backups to MinIO are not being written",
+		"minio.up.title":       "MINIO: storage is back",
+		"minio.disk_low.title": "MINIO: disk is running out of space",
+		"minio.disk_ok.title":  "MINIO: disk space recovered",
+		"minio.pvc_low.title":  "MINIO: PVC is almost full",
+		"minio.pvc_ok.title":   "MINIO: PVC space recovered",
+
+		"usage.pvc":         "Usage: /pvc [namespace]",
+		"pvc.title":         "PVCs: %d",
+		"pvc.title_ns":      "PVCs in %s: %d",
+		"pvc.none":          "No PVCs",
+		"pvc.no_stats":      "No kubelet stats: %d (hostPath and local-path do not report usage)",
+		"pvc.pv_problems":   "PVs needing attention: %d",
+		"pvc.used":          "Used:",
+		"pvc.claim":         "Claim:",
+		"pvc.pending_for":   "Pending for %s",
+		"pvc.warn.title":    "PVC: volume is filling up",
+		"pvc.crit.title":    "PVC: volume is almost full",
+		"pvc.ok.title":      "PVC: usage back to normal",
+		"pvc.inodes.title":  "PVC: running out of inodes",
+		"pvc.pending.title": "PVC: volume not provisioned",
+		"pvc.bound.title":   "PVC: volume provisioned",
+		"pv.released.title": "PV: volume released",
+		"pv.released.text":  "The PVC was deleted but the data is still on the volume. Delete the PV or clear claimRef: kubectl patch pv %s -p '{\"spec\":{\"claimRef\":null}}'",
+		"pv.failed.title":   "PV: volume reclaim failed",
+		"pv.failed.text":    "The provisioner could not delete or scrub the volume, check its logs",
+
+		"usage.cordon":         "Usage: /cordon <node>",
+		"usage.uncordon":       "Usage: /uncordon <node>",
+		"usage.drain":          "Usage: /drain <node> [--force] [--timeout 10m]",
+		"node.label":           "Node:",
+		"node.cordon_confirm":  "Stop scheduling pods on the node?",
+		"node.cordoned":        "Scheduling disabled on node:",
+		"node.uncordoned":      "Node accepts pods again, alerts re-enabled:",
+		"drain.confirm":        "Put the node into maintenance?",
+		"drain.evict":          "Pods to evict:",
+		"drain.skip":           "Staying on the node:",
+		"drain.skip_counts":    "DaemonSet — %d, static — %d",
+		"drain.timeout":        "Timeout:",
+		"drain.force":          "Pods without a controller will be deleted and not recreated: %s",
+		"drain.silence_note":   "The node will be marked unschedulable and its alerts muted until /uncordon. Pods are evicted through the Eviction API honouring PodDisruptionBudgets",
+		"drain.unmanaged":      "The node runs pods without a controller, they will not be recreated after eviction: %s. Repeat with --force if that is acceptable",
+		"drain.busy":           "node %s is already being drained",
+		"drain.started":        "Node cordoned, evicting pods:",
+		"drain.progress_title": "drain %s",
+		"drain.done_title":     "drain %s finished",
+		"drain.timeout_title":  "drain %s: timed out",
+		"drain.failed_title":   "drain %s: some pods were not evicted",
+		"drain.counts":         "Evicted %d of %d",
+		"drain.more":           "…and %d more",
+		"drain.uncordon_hint":  "The node stays unschedulable with alerts muted. When done: /uncordon %s",
+
+		"usage.cronjob":      "Usage: /cronjob run <namespace> <name>",
+		"jobs.cron_title":    "CronJobs: %d",
+		"jobs.cron_title_ns": "CronJobs in %s: %d",
+		"jobs.no_cron":       "No CronJobs",
+		"jobs.jobs_title":    "Jobs: running or failed — %d, completed — %d",
+		"jobs.no_jobs":       "No running or failed Jobs",
+		"jobs.more":          "…and %d more",
+		"jobs.failed.title":  "Job failed",
+		"jobs.attempts":      "Failed attempts:",
+		"jobs.missed.title":  "CronJob missed its schedule",
+		"jobs.last_success":  "Last successful run:",
+		"jobs.active_now":    "Jobs running now: %d",
+		"jobs.started":       "Job started",
+		"jobs.follow":        "Follow it with /jobs %s",
+
+		"usage.cleanup":       "Usage: /cleanup [--dry-run]",
+		"cleanup.nothing":     "Nothing to clean up",
+		"cleanup.title":       "Objects to delete: %d",
+		"cleanup.jobs":        "Completed Jobs older than %s",
+		"cleanup.pods":        "Pods in the Failed phase",
+		"cleanup.replicasets": "Empty ReplicaSets outside revision history",
+		"cleanup.more":        "…and %d more",
+		"cleanup.dry_run":     "Dry run, nothing was deleted. To delete: /cleanup",
+		"cleanup.done":        "Objects deleted: %d",
+		"cleanup.scheduled":   "Scheduled cleanup (CLEANUP_SCHEDULE): %s",
+
+		"probes.title":           "HTTP probes: %d, failing — %d",
+		"probes.none":            "Nothing to probe: no Ingress hosts, no Services annotated go-bot/probe and no PROBE_TARGETS",
+		"probes.url":             "URL:",
+		"probes.source":          "Source:",
+		"probes.failures":        "Consecutive failures:",
+		"probes.latency":         "Latency:",
+		"probes.down_for":        "Was down for:",
+		"probes.failed.title":    "HTTP probe failing",
+		"probes.recovered.title": "HTTP probe recovered",
+		"probes.reason.body":     "body does not contain %q",
+		"probes.reason.latency":  "latency %s > %s",
+		"probes.reason.tls":      "TLS certificate expires %s",
+
+		"usage.top":      "Usage: /top pods [ns] [--sort cpu|mem] [--limit N] or /top ns [--sort cpu|mem]",
+		"top.pods_title": "Pod usage (%s), sorted by %s",
+		"top.ns_title":   "Usage by namespace, sorted by %s",
+		"top.shown":      "Showing %d of %d",
+		"top.none":       "No usage data for running pods",
+		"top.no_metrics": "⚠️ metrics-server is not installed or unavailable: %s",
+		"top.legend":     "CPU>REQ / MEM>REQ — usage above requests, MEM~LIM — memory ≥ %d%% of limit",
+
+		"monitor.title":     "Node monitoring status",
+		"monitor.no_data":   "ℹ️ No node data yet",
+		"monitor.state":     "   Status: %s",
+		"monitor.last_seen": "   Last check: %s ago",
+		"monitor.notified":  "   ⚠️ Notification sent",
+
+		"alerts.title":    "Active alerts",
+		"alerts.problem":  "   Problem: %s",
+		"alerts.duration": "   Duration: %s",
+		"alerts.none":     "✅ No active alerts",
+
+		"alert.node":              "Node:",
+		"alert.status":            "Status:",
+		"alert.node_down.title":   "ALERT: Node Down",
+		"alert.downtime":          "Downtime:",
+		"alert.node_down.text":    "⚠️ Node has been unavailable for more than %s!",
+		"alert.recovery.title":    "RECOVERY: Node Back Online",
+		"alert.recovery.text":     "🎉 Node is back online!",
+		"alert.missing.title":     "CRITICAL: Node Missing",
+		"alert.missing_for":       "Missing for:",
+		"alert.node_missing.text": "🚨 Node has been missing from the cluster for more than %s!",
+		"alert.reason":            "Reason: %s",
+		"alert.expected":          "Expected at:",
+		"alert.never":             "never",
+		"alert.ago":               "%s ago",
+
+		"pods.header":  "📦 Pods (%s): %d, page %d/%d",
+		"pods.filters": "Filters: %s",
+		"pods.none":    "No pods found",
+		"pods.expired": "Listing expired, run /getpods again",
+		"btn.prev":     "◀️ Prev",
+		"btn.next":     "Next ▶️",
+
+		"restart.done":       "✅ Deployment %s/%s restarted",
+		"scale.bad_replicas": "Replicas must be a number",
+		"scale.done":         "✅ Deployment %s/%s → %s",
+
+		"logs.error":            "Logs error: %s",
+		"logs.selector_error":   "Selector error: %s",
+		"logs.no_pods":          "No pods match selector %s",
+		"logs.none_fetched":     "Could not fetch logs from any container",
+		"logs.empty":            "Logs are empty",
+		"logs.choose_container": "Pod %s has several containers, pick one:",
+		"logs.all_containers":   "All containers",
+
+		"output.file": "Output attached as a file (%s)",
+
+		"usage.describe":            "Usage: /describe <namespace> <pod>",
+		"describe.namespace":        "Namespace: %s",
+		"describe.node":             "Node: %s",
+		"describe.status":           "Status: %s (phase %s)",
+		"describe.ip":               "IP: %s",
+		"describe.qos":              "QoS: %s",
+		"describe.started":          "Started: %s ago",
+		"describe.owner":            "Owner: %s",
+		"describe.conditions":       "Conditions:",
+		"describe.containers":       "Containers:",
+		"describe.state_running":    "State: Running, %s",
+		"describe.state_waiting":    "State: Waiting, %s",
+		"describe.state_terminated": "State: Terminated, %s (exit code %d)",
+		"describe.last_termination": "Last termination: %s (exit code %d), %s ago",
+		"describe.ready_restarts":   "Ready: %t, restarts: %d",
+		"describe.requests":         "Requests: %s",
+		"describe.limits":           "Limits: %s",
+		"describe.volumes":          "Volumes:",
+		"describe.events":           "Events (last %d):",
+		"describe.no_events":        "No events",
+		"describe.actions":          "Actions:",
+		"btn.logs":                  "📜 Logs",
+		"btn.logs_previous":         "⏮ Previous logs",
+
+		"dur.short.day":    "%dd",
+		"dur.short.hour":   "%dh",
+		"dur.short.minute": "%dm",
+	},
+}
+
+// pluralForms формы единиц измерения: одна, несколько (2-4), много
+var pluralForms = map[Lang]map[string][3]string{
+	LangRU: {
+		"minute":  {"минута", "минуты", "минут"},
+		"hour":    {"час", "часа", "часов"},
+		"day":     {"день", "дня", "дней"},
+		"replica": {"реплика", "реплики", "реплик"},
+	},
+	LangEN: {
+		"minute":  {"minute", "minutes", "minutes"},
+		"hour":    {"hour", "hours", "hours"},
+		"day":     {"day", "days", "days"},
+		"replica": {"replica", "replicas", "replicas"},
+	},
+}
+
+// T возвращает сообщение по ключу на нужном языке, подставляя args через fmt.Sprintf.
+// Если перевода нет, используется язык по умолчанию, затем сам ключ
+func T(lang Lang, key string, args ...any) string {
+	msg, ok := messages[lang][key]
+	if !ok {
+		if msg, ok = messages[defaultLang][key]; !ok {
+			msg = key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Plural возвращает число с единицей в правильной форме: "5 минут", "1 hour"
+func Plural(lang Lang, n int, unit string) string {
+	forms, ok := pluralForms[lang][unit]
+	if !ok {
+		forms = pluralForms[defaultLang][unit]
+	}
+	return fmt.Sprintf("%d %s", n, forms[pluralIndex(lang, n)])
+}
+
+// pluralIndex выбирает форму по правилам языка
+func pluralIndex(lang Lang, n int) int {
+	if n < 0 {
+		n = -n
+	}
+	if lang != LangRU {
+		if n == 1 {
+			return 0
+		}
+		return 2
+	}
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return 0
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// langStore хранит выбранный язык по чатам; при заданном пути сохраняет его в JSON
+type langStore struct {
+	mu    sync.RWMutex
+	path  string
+	chats map[int64]Lang
+}
+
+// langs языковые настройки чатов, задаются в main
+var langs = newLangStore("")
+
+func newLangStore(path string) *langStore {
+	s := &langStore{path: path, chats: make(map[int64]Lang)}
+	if path == "" {
+		return s
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ Не удалось прочитать языковые настройки %s: %v", path, err)
+		}
+		return s
+	}
+	if err := json.Unmarshal(data, &s.chats); err != nil {
+		log.Printf("⚠️ Некорректный файл языковых настроек %s: %v", path, err)
+	}
+	return s
+}
+
+// Get возвращает язык чата или язык по умолчанию
+func (s *langStore) Get(chatID int64) Lang {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if lang, ok := s.chats[chatID]; ok {
+		return lang
+	}
+	return defaultLang
+}
+
+// Set запоминает язык чата
+func (s *langStore) Set(chatID int64, lang Lang) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chats[chatID] = lang
+	if s.path == "" {
+		return
+	}
+	data, err := json.Marshal(s.chats)
+	if err != nil {
+		log.Printf("⚠️ Ошибка сериализации языковых настроек: %v", err)
+		return
+	}
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		log.Printf("⚠️ Не удалось сохранить языковые настройки %s: %v", s.path, err)
+	}
+}
+
+// langFor возвращает язык сообщений для чата
+func langFor(chatID int64) Lang {
+	return langs.Get(chatID)
+}
+
+// handleLang показывает или переключает язык чата
+func handleLang(bot *tgbotapi.BotAPI, chatID int64, args string) {
+	if strings.TrimSpace(args) == "" {
+		sendText(bot, chatID, T(langFor(chatID), "lang.current", langFor(chatID)))
+		return
+	}
+	lang, ok := parseLang(args)
+	if !ok {
+		sendText(bot, chatID, T(langFor(chatID), "usage.lang"))
+		return
+	}
+	langs.Set(chatID, lang)
+	sendText(bot, chatID, T(lang, "lang.set", lang))
+}
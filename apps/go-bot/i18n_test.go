@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	for key := range messages[LangRU] {
+		if _, ok := messages[LangEN][key]; !ok {
+			t.Errorf("ключа %q нет в en", key)
+		}
+	}
+	for key := range messages[LangEN] {
+		if _, ok := messages[LangRU][key]; !ok {
+			t.Errorf("ключа %q нет в ru", key)
+		}
+	}
+}
+
+// Алерты разных проверок собираются из общих строк alert.*, а не из ключей чужих команд
+func TestSharedAlertStrings(t *testing.T) {
+	withRenderMode(t, tgbotapi.ModeHTML)
+	now := goldenNow
+	cj := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ops", Name: "backup"},
+		Spec:       batchv1.CronJobSpec{Schedule: "0 3 * * *"},
+	}
+	alerts := map[string]*Rich{
+		"cronjob": renderCronJobMissedAlert(LangEN, "prod", cj, now.Add(-time.Hour), now),
+		"minio":   renderMinIODownAlert(LangEN, "prod", "http://minio:9000", "connection refused"),
+		"probe":   renderProbeFailed(LangEN, probeState{Target: probeTarget{URL: "https://app"}, Last: probeResult{Reason: "connection refused"}, Failures: 3}),
+	}
+	want := map[string][]string{
+		"cronjob": {"Expected at:", "never"},
+		"minio":   {"Reason: connection refused"},
+		"probe":   {"Reason: connection refused"},
+	}
+	for name, r := range alerts {
+		for _, s := range want[name] {
+			if !strings.Contains(r.Plain(), s) {
+				t.Errorf("%s: нет %q в %q", name, s, r.Plain())
+			}
+		}
+	}
+
+	cj.Status.LastSuccessfulTime = &metav1.Time{Time: now.Add(-26 * time.Hour)}
+	if got := renderCronJobMissedAlert(LangRU, "", cj, now.Add(-time.Hour), now).Plain(); !strings.Contains(got, "назад") {
+		t.Errorf("нет времени последнего успеха: %q", got)
+	}
+}
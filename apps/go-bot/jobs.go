@@ -0,0 +1,409 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// cronInstantiateAnnotation так kubectl create job --from помечает ручной запуск
+const cronInstantiateAnnotation = "cronjob.kubernetes.io/instantiate"
+
+// jobsListLimit сколько активных и упавших Job-ов показывать в /jobs
+const jobsListLimit = 20
+
+// jobPodLogs хвост логов упавшего контейнера последнего pod-а Job
+func jobPodLogs(ctx context.Context, clientset kubernetes.Interface, ns, job string, tail int64) (string, error) {
+	pods, err := clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + job})
+	if err != nil || len(pods.Items) == 0 {
+		return "", err
+	}
+	pod := pods.Items[0]
+	for _, p := range pods.Items[1:] {
+		if p.CreationTimestamp.After(pod.CreationTimestamp.Time) {
+			pod = p
+		}
+	}
+	data, err := fetchLogs(ctx, clientset, pod.Namespace, pod.Name, failedContainer(pod), tail, false, false)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// jobFailedAt когда Job перешёл в Failed
+func jobFailedAt(job *batchv1.Job) time.Time {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return c.LastTransitionTime.Time
+		}
+	}
+	return time.Time{}
+}
+
+// jobCronJob имя CronJob-а, создавшего Job
+func jobCronJob(job *batchv1.Job) string {
+	if owner := metav1.GetControllerOf(job); owner != nil && owner.Kind == "CronJob" {
+		return owner.Name
+	}
+	return ""
+}
+
+// cronJobDue срок, к которому CronJob должен был успешно отработать после последнего успеха.
+// Часовой пояс берётся из spec.timeZone или префикса CRON_TZ=/TZ=, иначе контроллер
+// считает время в поясе kube-controller-manager, обычно UTC. Как и контроллер,
+// понимает "@every <длительность>"
+func cronJobDue(cj *batchv1.CronJob) (time.Time, error) {
+	base := cj.CreationTimestamp.Time
+	if t := cj.Status.LastSuccessfulTime; t != nil {
+		base = t.Time
+	}
+	expr := strings.TrimSpace(cj.Spec.Schedule)
+	tz := ""
+	if cj.Spec.TimeZone != nil {
+		tz = *cj.Spec.TimeZone
+	}
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if rest, ok := strings.CutPrefix(expr, prefix); ok {
+			tz, expr, _ = strings.Cut(rest, " ")
+			expr = strings.TrimSpace(expr)
+		}
+	}
+	if every, ok := strings.CutPrefix(expr, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(every))
+		if err != nil {
+			return time.Time{}, err
+		}
+		return base.Add(d), nil
+	}
+	loc := time.UTC
+	if tz != "" {
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return time.Time{}, err
+		}
+		loc = l
+	}
+	c, err := parseCron(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return c.Next(base.In(loc)), nil
+}
+
+// JobWatcher сообщает об упавших Job-ах и CronJob-ах, которые перестали успешно выполняться
+type JobWatcher struct {
+	cluster *Cluster
+	cfg     JobConfig
+	bot     *tgbotapi.BotAPI
+	adminID int64
+
+	since  time.Time            // о падениях до запуска бота не сообщаем
+	primed bool                 // первый проход выполнен, дальше любой упавший Job — новый
+	failed map[types.UID]bool   // упавшие Job-ы, которые уже рассмотрены
+	missed map[string]time.Time // CronJob → срок, о пропуске которого уже сообщено
+	broken map[string]bool      // CronJob-ы с непонятным расписанием, ошибка уже в логе
+}
+
+// NewJobWatcher создаёт наблюдателя за Job-ами кластера
+func NewJobWatcher(cluster *Cluster, cfg JobConfig, bot *tgbotapi.BotAPI, adminID int64) *JobWatcher {
+	return &JobWatcher{
+		cluster: cluster,
+		cfg:     cfg,
+		bot:     bot,
+		adminID: adminID,
+		since:   time.Now().Add(-cfg.PollInterval),
+		failed:  make(map[types.UID]bool),
+		missed:  make(map[string]time.Time),
+		broken:  make(map[string]bool),
+	}
+}
+
+// Start проверяет Job-ы сразу и затем каждые PollInterval
+func (w *JobWatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		w.check(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check ищет новые упавшие Job-ы и просроченные CronJob-ы
+func (w *JobWatcher) check(ctx context.Context, now time.Time) {
+	lang := langFor(w.adminID)
+
+	jobs, err := w.cluster.Typed.BatchV1().Jobs(w.cfg.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("❌ [%s] Ошибка получения Job-ов: %v", w.cluster.Name, err)
+		return
+	}
+	present := make(map[types.UID]bool, len(jobs.Items))
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		present[job.UID] = true
+		// Job-ы обновления k3s разбирает UpgradeTracker
+		if _, ok := job.Labels[upgradeLabelPlan]; ok {
+			continue
+		}
+		if _, failed := jobFinished(job); !failed || w.failed[job.UID] {
+			continue
+		}
+		w.failed[job.UID] = true
+		if !w.primed && !jobFailedAt(job).After(w.since) {
+			continue
+		}
+		w.notify("job_failed", renderJobFailedAlert(lang, w.cluster.Name, job))
+		log.Printf("🔔 [%s] Job %s/%s завершился с ошибкой", w.cluster.Name, job.Namespace, job.Name)
+		logs, err := jobPodLogs(ctx, w.cluster.Typed, job.Namespace, job.Name, w.cfg.LogTail)
+		if err != nil {
+			log.Printf("⚠️ [%s] Не удалось получить логи Job %s/%s: %v", w.cluster.Name, job.Namespace, job.Name, err)
+		}
+		if logs != "" {
+			sendLong(w.bot, w.adminID, "job-"+job.Name, logs)
+		}
+	}
+	w.primed = true
+	// Удалённые Job-ы больше не нужно помнить
+	for uid := range w.failed {
+		if !present[uid] {
+			delete(w.failed, uid)
+		}
+	}
+
+	cronJobs, err := w.cluster.Typed.BatchV1().CronJobs(w.cfg.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("❌ [%s] Ошибка получения CronJob-ов: %v", w.cluster.Name, err)
+		return
+	}
+	for i := range cronJobs.Items {
+		cj := &cronJobs.Items[i]
+		if cj.Spec.Suspend != nil && *cj.Spec.Suspend {
+			continue
+		}
+		key := cj.Namespace + "/" + cj.Name
+		due, err := cronJobDue(cj)
+		if err != nil {
+			if !w.broken[key] {
+				w.broken[key] = true
+				log.Printf("⚠️ [%s] Расписание CronJob %s (%q) не разобрано: %v", w.cluster.Name, key, cj.Spec.Schedule, err)
+			}
+			continue
+		}
+		if due.IsZero() || now.Before(due.Add(w.cfg.CronGrace)) || w.missed[key].Equal(due) {
+			continue
+		}
+		w.missed[key] = due
+		w.notify("cronjob_missed", renderCronJobMissedAlert(lang, w.cluster.Name, cj, due, now))
+		log.Printf("🔔 [%s] CronJob %s не отработал успешно, ожидался %s", w.cluster.Name, key, due.Format(time.RFC3339))
+	}
+}
+
+func (w *JobWatcher) notify(kind string, r *Rich) {
+	result := "sent"
+	if err := sendRich(w.bot, w.adminID, r); err != nil {
+		result = "failed"
+	}
+	telemetry.Notifications.Inc(kind, result)
+}
+
+// renderJobFailedAlert уведомление об упавшем Job; логи отправляются отдельно
+func renderJobFailedAlert(lang Lang, cluster string, job *batchv1.Job) *Rich {
+	r := NewRich().
+		Text("❌ " + alertPrefix(cluster)).Bold(T(lang, "jobs.failed.title")).Line().Line().
+		Text("⚙️ Job: ").Code(job.Namespace + "/" + job.Name).Line()
+	if cj := jobCronJob(job); cj != "" {
+		r.Text("⏰ CronJob: ").Code(cj).Line()
+	}
+	r.Text("🔁 ").Bold(T(lang, "jobs.attempts")).Textf(" %d", job.Status.Failed).Line()
+	if reason := jobFailureMessage(job); reason != "" {
+		r.Text(T(lang, "alert.reason", reason)).Line()
+	}
+	return r
+}
+
+// renderCronJobMissedAlert уведомление о CronJob-е без успешного запуска в срок
+func renderCronJobMissedAlert(lang Lang, cluster string, cj *batchv1.CronJob, due, now time.Time) *Rich {
+	last := T(lang, "alert.never")
+	if t := cj.Status.LastSuccessfulTime; t != nil {
+		last = T(lang, "alert.ago", formatDurationForAlert(lang, now.Sub(t.Time)))
+	}
+	r := NewRich().
+		Text("⚠️ " + alertPrefix(cluster)).Bold(T(lang, "jobs.missed.title")).Line().Line().
+		Text("⏰ CronJob: ").Code(cj.Namespace + "/" + cj.Name).Text(" (" + cj.Spec.Schedule + ")").Line().
+		Text("🗓 ").Bold(T(lang, "alert.expected")).Text(" " + due.Format("2006-01-02 15:04 MST")).Line().
+		Text("✅ ").Bold(T(lang, "jobs.last_success")).Text(" " + last)
+	if n := len(cj.Status.Active); n > 0 {
+		r.Line().Text("▶️ " + T(lang, "jobs.active_now", n))
+	}
+	return r
+}
+
+// handleJobs показывает CronJob-ы и незавершённые или упавшие Job-ы: /jobs [ns]
+func handleJobs(bot *tgbotapi.BotAPI, cluster *Cluster, cfg JobConfig, ctx context.Context, chatID int64, ns string) {
+	cronJobs, err := cluster.Typed.BatchV1().CronJobs(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	jobs, err := cluster.Typed.BatchV1().Jobs(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	sendLongRich(bot, chatID, "jobs", renderJobs(langFor(chatID), clusterLabel(cluster.Name), ns, cronJobs.Items, jobs.Items, cfg, time.Now()))
+}
+
+// renderJobs таблица CronJob-ов и список Job-ов, требующих внимания
+func renderJobs(lang Lang, cluster, ns string, cronJobs []batchv1.CronJob, jobs []batchv1.Job, cfg JobConfig, now time.Time) *Rich {
+	name := func(namespace, n string) string {
+		if ns == "" {
+			return namespace + "/" + n
+		}
+		return n
+	}
+	title := T(lang, "jobs.cron_title", len(cronJobs))
+	if ns != "" {
+		title = T(lang, "jobs.cron_title_ns", ns, len(cronJobs))
+	}
+	r := NewRich().Text("⏰ " + cluster).Bold(title).Line()
+	if len(cronJobs) == 0 {
+		r.Text(T(lang, "jobs.no_cron")).Line()
+	} else {
+		sort.Slice(cronJobs, func(i, j int) bool {
+			return cronJobs[i].Namespace+"/"+cronJobs[i].Name < cronJobs[j].Namespace+"/"+cronJobs[j].Name
+		})
+		var sb strings.Builder
+		w := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSCHEDULE\tLAST OK\tSTATE")
+		for i := range cronJobs {
+			cj := &cronJobs[i]
+			last := "-"
+			if t := cj.Status.LastSuccessfulTime; t != nil {
+				last = formatDuration(lang, now.Sub(t.Time))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name(cj.Namespace, cj.Name), cj.Spec.Schedule, last, cronJobState(cj, cfg, now))
+		}
+		w.Flush()
+		r.Pre(strings.TrimRight(sb.String(), "\n")).Line()
+	}
+
+	var attention []batchv1.Job
+	complete := 0
+	for _, job := range jobs {
+		if _, ok := job.Labels[upgradeLabelPlan]; ok {
+			continue
+		}
+		if finished, failed := jobFinished(&job); finished && !failed {
+			complete++
+			continue
+		}
+		attention = append(attention, job)
+	}
+	r.Line().Text("⚙️ ").Bold(T(lang, "jobs.jobs_title", len(attention), complete)).Line()
+	if len(attention) == 0 {
+		return r.Text(T(lang, "jobs.no_jobs"))
+	}
+	sort.Slice(attention, func(i, j int) bool {
+		return attention[i].CreationTimestamp.After(attention[j].CreationTimestamp.Time)
+	})
+	more := 0
+	if len(attention) > jobsListLimit {
+		more = len(attention) - jobsListLimit
+		attention = attention[:jobsListLimit]
+	}
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSTATUS\tAGE")
+	for i := range attention {
+		job := &attention[i]
+		status := "Running"
+		if _, failed := jobFinished(job); failed {
+			status = "Failed"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name(job.Namespace, job.Name), status, formatDuration(lang, now.Sub(job.CreationTimestamp.Time)))
+	}
+	w.Flush()
+	r.Pre(strings.TrimRight(sb.String(), "\n"))
+	if more > 0 {
+		r.Line().Italic(T(lang, "jobs.more", more))
+	}
+	return r
+}
+
+// cronJobState краткое состояние CronJob-а для таблицы /jobs
+func cronJobState(cj *batchv1.CronJob, cfg JobConfig, now time.Time) string {
+	if cj.Spec.Suspend != nil && *cj.Spec.Suspend {
+		return "Suspended"
+	}
+	due, err := cronJobDue(cj)
+	switch {
+	case err != nil:
+		return "BadSchedule"
+	case !due.IsZero() && now.After(due.Add(cfg.CronGrace)):
+		return "Overdue"
+	case len(cj.Status.Active) > 0:
+		return fmt.Sprintf("Active(%d)", len(cj.Status.Active))
+	}
+	return "OK"
+}
+
+// newManualJob Job из шаблона CronJob-а, как kubectl create job --from=cronjob/<name>
+func newManualJob(cj *batchv1.CronJob, now time.Time) *batchv1.Job {
+	suffix := fmt.Sprintf("-manual-%d", now.Unix())
+	base := cj.Name
+	// Имя Job попадает в метку job-name, а она не длиннее 63 символов
+	if len(base)+len(suffix) > 63 {
+		base = base[:63-len(suffix)]
+	}
+	annotations := map[string]string{cronInstantiateAnnotation: "manual"}
+	for k, v := range cj.Spec.JobTemplate.Annotations {
+		annotations[k] = v
+	}
+	labels := make(map[string]string, len(cj.Spec.JobTemplate.Labels))
+	for k, v := range cj.Spec.JobTemplate.Labels {
+		labels[k] = v
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            base + suffix,
+			Namespace:       cj.Namespace,
+			Labels:          labels,
+			Annotations:     annotations,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cj, batchv1.SchemeGroupVersion.WithKind("CronJob"))},
+		},
+		Spec: *cj.Spec.JobTemplate.Spec.DeepCopy(),
+	}
+}
+
+// handleCronJobRun запускает CronJob вне расписания: /cronjob run <ns> <name>
+func handleCronJobRun(bot *tgbotapi.BotAPI, cluster *Cluster, ctx context.Context, chatID int64, user *tgbotapi.User, ns, name string) {
+	lang := langFor(chatID)
+	cj, err := cluster.Typed.BatchV1().CronJobs(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	job, err := cluster.Typed.BatchV1().Jobs(ns).Create(ctx, newManualJob(cj, time.Now()), metav1.CreateOptions{})
+	auditLog.Record(userLabel(user), cluster.Name, "cronjob.run", ns+"/"+name, err)
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	sendRich(bot, chatID, NewRich().Text("✅ "+clusterLabel(cluster.Name)+T(lang, "jobs.started")+" ").Code(ns+"/"+job.Name).Line().Text(T(lang, "jobs.follow", ns)))
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	defaultLogTail = 200
+	// maxCallbackData ограничение Telegram на размер callback_data кнопки
+	maxCallbackData = 64
+)
+
+// logsRequest описывает разобранные аргументы команды /logs
+type logsRequest struct {
+	Namespace     string
+	Pod           string
+	Deployment    string
+	Selector      string
+	Container     string
+	AllContainers bool
+	Previous      bool
+	Tail          int64
+}
+
+// logLine строка лога с источником и временем для слияния потоков
+type logLine struct {
+	Time   time.Time
+	Source string
+	Text   string
+}
+
+// parseLogsArgs разбирает аргументы /logs:
+// <ns> <pod> | <ns> deploy/<name> | <ns> -l <selector>, опционально -c <container>, --previous и кол-во строк
+func parseLogsArgs(args string) (logsRequest, error) {
+	req := logsRequest{Tail: defaultLogTail}
+	parts := strings.Fields(args)
+	if len(parts) < 2 {
+		return req, fmt.Errorf("недостаточно аргументов")
+	}
+	req.Namespace = parts[0]
+
+	for i := 1; i < len(parts); i++ {
+		p := parts[i]
+		switch {
+		case p == "-l" || p == "--selector":
+			if i+1 >= len(parts) {
+				return req, fmt.Errorf("не указан селектор")
+			}
+			i++
+			req.Selector = parts[i]
+		case p == "-c" || p == "--container":
+			if i+1 >= len(parts) {
+				return req, fmt.Errorf("не указан контейнер")
+			}
+			i++
+			req.Container = parts[i]
+		case p == "--all-containers":
+			req.AllContainers = true
+		case p == "-p" || p == "--previous":
+			req.Previous = true
+		case strings.HasPrefix(p, "deploy/") || strings.HasPrefix(p, "deployment/"):
+			req.Deployment = p[strings.Index(p, "/")+1:]
+		default:
+			if t, err := strconv.Atoi(p); err == nil && t > 0 {
+				req.Tail = int64(t)
+				continue
+			}
+			if req.Pod != "" {
+				return req, fmt.Errorf("лишний аргумент: %s", p)
+			}
+			req.Pod = p
+		}
+	}
+
+	targets := 0
+	for _, v := range []string{req.Pod, req.Deployment, req.Selector} {
+		if v != "" {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return req, fmt.Errorf("укажите ровно один pod, deploy/<name> или -l <selector>")
+	}
+	return req, nil
+}
+
+func handleLogs(bot *tgbotapi.BotAPI, clientset kubernetes.Interface, ctx context.Context, chatID int64, req logsRequest) {
+	if req.Pod != "" {
+		handlePodLogs(bot, clientset, ctx, chatID, req)
+		return
+	}
+
+	selector := req.Selector
+	if req.Deployment != "" {
+		d, err := clientset.AppsV1().Deployments(req.Namespace).Get(ctx, req.Deployment, metav1.GetOptions{})
+		if err != nil {
+			sendError(bot, chatID, err)
+			return
+		}
+		sel, err := metav1.LabelSelectorAsSelector(d.Spec.Selector)
+		if err != nil {
+			sendText(bot, chatID, T(langFor(chatID), "logs.selector_error", err))
+			return
+		}
+		selector = sel.String()
+	}
+
+	pods, err := clientset.CoreV1().Pods(req.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	if len(pods.Items) == 0 {
+		sendText(bot, chatID, T(langFor(chatID), "logs.no_pods", selector))
+		return
+	}
+
+	sendMergedLogs(bot, clientset, ctx, chatID, pods.Items, req, logsFileName(req))
+}
+
+// sendMergedLogs собирает логи всех контейнеров pod-ов и отправляет их одним потоком
+func sendMergedLogs(bot *tgbotapi.BotAPI, clientset kubernetes.Interface, ctx context.Context, chatID int64, pods []corev1.Pod, req logsRequest, name string) {
+	var lines []logLine
+	fetched := 0
+	for _, pod := range pods {
+		for _, c := range pod.Spec.Containers {
+			if req.Container != "" && c.Name != req.Container {
+				continue
+			}
+			data, err := fetchLogs(ctx, clientset, pod.Namespace, pod.Name, c.Name, req.Tail, req.Previous, true)
+			if err != nil {
+				log.Printf("⚠️ Логи %s/%s/%s недоступны: %v", pod.Namespace, pod.Name, c.Name, err)
+				continue
+			}
+			fetched++
+			lines = append(lines, parseTimestampedLogs(data, pod.Name+"/"+c.Name)...)
+		}
+	}
+	if fetched == 0 {
+		sendText(bot, chatID, T(langFor(chatID), "logs.none_fetched"))
+		return
+	}
+	if len(lines) == 0 {
+		sendText(bot, chatID, T(langFor(chatID), "logs.empty"))
+		return
+	}
+	sendLong(bot, chatID, name, mergeLogLines(lines))
+}
+
+// handlePodLogs отдаёт логи одного pod-а; при нескольких контейнерах предлагает выбрать контейнер
+func handlePodLogs(bot *tgbotapi.BotAPI, clientset kubernetes.Interface, ctx context.Context, chatID int64, req logsRequest) {
+	pod, err := clientset.CoreV1().Pods(req.Namespace).Get(ctx, req.Pod, metav1.GetOptions{})
+	if err != nil {
+		sendText(bot, chatID, T(langFor(chatID), "logs.error", err))
+		return
+	}
+
+	if req.Container == "" && len(pod.Spec.Containers) > 1 {
+		if req.AllContainers {
+			sendMergedLogs(bot, clientset, ctx, chatID, []corev1.Pod{*pod}, req, logsFileName(req))
+			return
+		}
+		sendContainerChoice(bot, chatID, pod, req)
+		return
+	}
+
+	data, err := fetchLogs(ctx, clientset, req.Namespace, req.Pod, req.Container, req.Tail, req.Previous, false)
+	if err != nil {
+		sendText(bot, chatID, T(langFor(chatID), "logs.error", err))
+		return
+	}
+	if len(data) == 0 {
+		sendText(bot, chatID, T(langFor(chatID), "logs.empty"))
+		return
+	}
+	sendLong(bot, chatID, logsFileName(req), string(data))
+}
+
+// sendContainerChoice предлагает кнопки выбора контейнера для pod-а с несколькими контейнерами
+func sendContainerChoice(bot *tgbotapi.BotAPI, chatID int64, pod *corev1.Pod, req logsRequest) {
+	var sb strings.Builder
+	lang := langFor(chatID)
+	sb.WriteString(T(lang, "logs.choose_container", pod.Name) + "\n")
+
+	flags := fmt.Sprintf("%d", req.Tail)
+	if req.Previous {
+		flags = "--previous " + flags
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, c := range pod.Spec.Containers {
+		data := fmt.Sprintf("logs %s %s -c %s %s", pod.Namespace, pod.Name, c.Name, flags)
+		sb.WriteString(fmt.Sprintf("/%s\n", data))
+		if len(data) <= maxCallbackData {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(c.Name, data),
+			))
+		}
+	}
+	all := fmt.Sprintf("logs %s %s --all-containers %s", pod.Namespace, pod.Name, flags)
+	if len(all) <= maxCallbackData {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(T(lang, "logs.all_containers"), all),
+		))
+	}
+
+	var markup interface{}
+	if len(rows) > 0 {
+		markup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	}
+	sendRichMarkup(bot, chatID, NewRich().Text(sb.String()), markup)
+}
+
+// logsFileName формирует имя файла для логов: logs-<ns>-<pod|deployment|selector>[-container]
+func logsFileName(req logsRequest) string {
+	parts := []string{"logs", req.Namespace}
+	switch {
+	case req.Pod != "":
+		parts = append(parts, req.Pod)
+	case req.Deployment != "":
+		parts = append(parts, req.Deployment)
+	default:
+		parts = append(parts, req.Selector)
+	}
+	if req.Container != "" {
+		parts = append(parts, req.Container)
+	}
+	if req.Previous {
+		parts = append(parts, "previous")
+	}
+	return strings.Join(parts, "-")
+}
+
+// fetchLogs читает хвост логов контейнера
+func fetchLogs(ctx context.Context, clientset kubernetes.Interface, ns, pod, container string, tail int64, previous, timestamps bool) ([]byte, error) {
+	opts := &corev1.PodLogOptions{
+		Container:  container,
+		TailLines:  &tail,
+		Previous:   previous,
+		Timestamps: timestamps,
+	}
+	stream, err := clientset.CoreV1().Pods(ns).GetLogs(pod, opts).Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	return io.ReadAll(stream)
+}
+
+// parseTimestampedLogs разбирает вывод с Timestamps=true в строки с источником
+func parseTimestampedLogs(data []byte, source string) []logLine {
+	var lines []logLine
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := logLine{Source: source, Text: raw}
+		if idx := strings.IndexByte(raw, ' '); idx > 0 {
+			if ts, err := time.Parse(time.RFC3339Nano, raw[:idx]); err == nil {
+				line.Time = ts
+				line.Text = raw[idx+1:]
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// mergeLogLines упорядочивает строки по времени и добавляет префикс pod/container
+func mergeLogLines(lines []logLine) string {
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Time.Before(lines[j].Time)
+	})
+	var sb strings.Builder
+	for _, l := range lines {
+		sb.WriteString(fmt.Sprintf("[%s] %s\n", l.Source, l.Text))
+	}
+	return sb.String()
+}
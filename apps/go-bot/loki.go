@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// LokiClient клиент HTTP API Loki с таймаутом и ограничением размера ответа
+type LokiClient struct {
+	cfg  LokiConfig
+	http *http.Client
+}
+
+// NewLokiClient создаёт клиент; при пустом URL возвращает nil
+func NewLokiClient(cfg LokiConfig) *LokiClient {
+	if cfg.URL == "" {
+		return nil
+	}
+	return &LokiClient{cfg: cfg, http: &http.Client{Timeout: cfg.Timeout, Transport: instrumentTransport(nil, "loki", "")}}
+}
+
+// lokiStream поток Loki: набор меток и пары [время в нс, строка]
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// QueryRange возвращает до Limit последних строк за период, объединённых по времени
+func (c *LokiClient) QueryRange(ctx context.Context, logql string, start, end time.Time) ([]logLine, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	params := url.Values{}
+	params.Set("query", logql)
+	params.Set("start", strconv.FormatInt(start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(end.UnixNano(), 10))
+	params.Set("limit", strconv.Itoa(c.cfg.Limit))
+	params.Set("direction", "backward")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.URL+"/loki/api/v1/query_range?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.cfg.MaxResponseBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > c.cfg.MaxResponseBytes {
+		return nil, fmt.Errorf("ответ Loki больше %d байт, сузьте запрос или период", c.cfg.MaxResponseBytes)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Loki отдаёт ошибки разбора LogQL простым текстом
+		return nil, fmt.Errorf("Loki %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var envelope struct {
+		Status string `json:"status"`
+		Data   struct {
+			ResultType string          `json:"resultType"`
+			Result     json.RawMessage `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.Data.ResultType != "streams" {
+		return nil, fmt.Errorf("запрос вернул %s, а не строки логов; метрики смотрите через /promql", envelope.Data.ResultType)
+	}
+	var streams []lokiStream
+	if err := json.Unmarshal(envelope.Data.Result, &streams); err != nil {
+		return nil, err
+	}
+	return lokiLines(streams), nil
+}
+
+// lokiLines разворачивает потоки в строки, отсортированные от старых к новым
+func lokiLines(streams []lokiStream) []logLine {
+	var lines []logLine
+	for _, s := range streams {
+		source := lokiSource(s.Stream)
+		for _, v := range s.Values {
+			ns, err := strconv.ParseInt(v[0], 10, 64)
+			if err != nil {
+				continue
+			}
+			lines = append(lines, logLine{Time: time.Unix(0, ns), Source: source, Text: strings.TrimRight(v[1], "\n")})
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Time.Before(lines[j].Time) })
+	return lines
+}
+
+// lokiSource подпись потока: pod/container, если promtail их проставил, иначе все метки
+func lokiSource(stream map[string]string) string {
+	if pod := stream["pod"]; pod != "" {
+		if c := stream["container"]; c != "" {
+			return pod + "/" + c
+		}
+		return pod
+	}
+	keys := make([]string, 0, len(stream))
+	for k := range stream {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+stream[k])
+	}
+	return strings.Join(parts, ",")
+}
+
+// formatLokiLines текст для отправки: время, источник и строка
+func formatLokiLines(lines []logLine) string {
+	var sb strings.Builder
+	for _, l := range lines {
+		fmt.Fprintf(&sb, "%s [%s] %s\n", l.Time.Format("01-02 15:04:05"), l.Source, l.Text)
+	}
+	return sb.String()
+}
+
+// splitSince отделяет необязательный последний аргумент-период: "{app=\"x\"} 6h"
+func splitSince(args string, def time.Duration) (string, time.Duration) {
+	args = strings.TrimSpace(args)
+	i := strings.LastIndexAny(args, " \t")
+	if i < 0 {
+		return args, def
+	}
+	if d, err := parsePeriod(args[i+1:]); err == nil {
+		return strings.TrimSpace(args[:i]), d
+	}
+	return args, def
+}
+
+// handleLoki выполняет LogQL-запрос /loki <logql> [since]
+func handleLoki(bot *tgbotapi.BotAPI, loki *LokiClient, ctx context.Context, chatID int64, args string) {
+	lang := langFor(chatID)
+	if loki == nil {
+		sendText(bot, chatID, T(lang, "loki.disabled"))
+		return
+	}
+	logql, since := splitSince(args, loki.cfg.DefaultSince)
+	if logql == "" {
+		sendText(bot, chatID, T(lang, "usage.loki"))
+		return
+	}
+	sendLokiResult(bot, loki, ctx, chatID, logql, since, "loki")
+}
+
+// handleLogsHistory логи приложения из Loki, в том числе удалённых и перезапущенных pod-ов
+func handleLogsHistory(bot *tgbotapi.BotAPI, loki *LokiClient, ctx context.Context, chatID int64, args string) {
+	lang := langFor(chatID)
+	if loki == nil {
+		sendText(bot, chatID, T(lang, "loki.disabled"))
+		return
+	}
+	parts := strings.Fields(args)
+	if len(parts) < 2 || len(parts) > 3 {
+		sendText(bot, chatID, T(lang, "usage.logs_history"))
+		return
+	}
+	since := loki.cfg.DefaultSince
+	if len(parts) == 3 {
+		d, err := parsePeriod(parts[2])
+		if err != nil {
+			sendText(bot, chatID, T(lang, "usage.logs_history"))
+			return
+		}
+		since = d
+	}
+	logql := fmt.Sprintf("{namespace=%s, %s=%s}", strconv.Quote(parts[0]), loki.cfg.AppLabel, strconv.Quote(parts[1]))
+	sendLokiResult(bot, loki, ctx, chatID, logql, since, "history-"+parts[0]+"-"+parts[1])
+}
+
+func sendLokiResult(bot *tgbotapi.BotAPI, loki *LokiClient, ctx context.Context, chatID int64, logql string, since time.Duration, name string) {
+	lang := langFor(chatID)
+	now := time.Now()
+	lines, err := loki.QueryRange(ctx, logql, now.Add(-since), now)
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	if len(lines) == 0 {
+		sendText(bot, chatID, T(lang, "loki.empty", logql, formatPeriod(since)))
+		return
+	}
+	if len(lines) >= loki.cfg.Limit {
+		sendText(bot, chatID, T(lang, "loki.truncated", loki.cfg.Limit))
+	}
+	sendLong(bot, chatID, name, formatLokiLines(lines))
+}
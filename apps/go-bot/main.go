@@ -3,10 +3,10 @@ package main
 import (
 	"context"
 	"fmt"
-	"io"
 	"log"
+	"net/http"
 	"os"
-	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -16,13 +16,21 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
-	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
 const MaxMsgLen = 3800
 
+// publicCommands команды, доступные не только в чате администратора
+var publicCommands = map[string]bool{
+	"help":    true,
+	"start":   true,
+	"status":  true,
+	"getpods": true,
+	"podpage": true,
+	"lang":    true,
+	"cluster": true,
+}
+
 func main() {
 	// Лог только в stdout (корректно для Kubernetes/Docker)
 	log.SetOutput(os.Stdout)
@@ -39,36 +47,105 @@ func main() {
 		}
 	}
 
-	bot, err := tgbotapi.NewBotAPI(token)
+	telegramClient := &http.Client{Transport: instrumentTransport(nil, "telegram", "")}
+	bot, err := tgbotapi.NewBotAPIWithClient(token, tgbotapi.APIEndpoint, telegramClient)
 	if err != nil {
 		log.Fatalf("Ошибка инициализации бота: %v", err)
 	}
 	log.Printf("Бот авторизован: %s", bot.Self.UserName)
+	outputConfig = LoadOutputConfig()
+	renderMode = parseRenderMode(os.Getenv("TELEGRAM_PARSE_MODE"))
+	if lang, ok := parseLang(os.Getenv("BOT_LANG")); ok {
+		defaultLang = lang
+	}
+	langs = newLangStore(os.Getenv("LANG_PREFS_FILE"))
+	auditLog = newAuditLog(os.Getenv("AUDIT_LOG"))
+	admins = newAdminRole(os.Getenv("ADMIN_USERS"), adminID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	cfg, err := rest.InClusterConfig()
+	clusterCfg := LoadClusterConfig()
+	clusters, err = NewClusters(clusterCfg)
 	if err != nil {
 		log.Fatalf("Ошибка конфигурации Kubernetes: %v", err)
 	}
-	clientset, err := kubernetes.NewForConfig(cfg)
-	if err != nil {
-		log.Fatalf("Ошибка клиента Kubernetes: %v", err)
-	}
 
-	ctx := context.Background()
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 	updates := bot.GetUpdatesChan(u)
 
-	monitor := NewMonitor(clientset, bot, adminID)
+	veleroCfg := LoadVeleroConfig()
+	certCfg := LoadCertConfig()
+	upgradeCfg := LoadUpgradeConfig()
+	vpnCfg := LoadVPNConfig()
+	drainCfg := LoadDrainConfig()
+	jobCfg := LoadJobConfig()
+	cleanupCfg := LoadCleanupConfig()
+	volumeCfg := LoadVolumeConfig()
+
+	// Отдельный монитор на каждый кластер
+	monitors := make(map[string]*Monitor)
+	monitoringEnabled := os.Getenv("DISABLE_MONITORING") != "true"
+	for _, cluster := range clusters.All() {
+		if err := cluster.RefreshCapabilities(); err != nil {
+			log.Printf("⚠️ [%s] Ошибка discovery: %v", cluster.Name, err)
+		}
+		if !cluster.HasMetrics() {
+			log.Printf("⚠️ [%s] metrics-server не найден, /status покажет requests вместо потребления", cluster.Name)
+		}
+		go cluster.WatchCapabilities(ctx, clusterCfg.DiscoveryInterval)
+
+		monitor := NewMonitor(cluster, bot, adminID)
+		monitors[cluster.Name] = monitor
+		if monitoringEnabled {
+			go monitor.Start(ctx)
+			// Без Velero в кластере наблюдатель ничего не делает
+			go NewBackupWatcher(cluster, veleroCfg, bot, adminID).Start(ctx)
+			go NewCertWatcher(cluster, certCfg, bot, adminID).Start(ctx)
+			go NewUpgradeTracker(cluster, upgradeCfg, bot, adminID).Start(ctx)
+			go NewVolumeWatcher(cluster, volumeCfg, bot, adminID).Start(ctx)
+			go NewJobWatcher(cluster, jobCfg, bot, adminID).Start(ctx)
+		}
+	}
+	if !monitoringEnabled {
+		log.Println("⚠️ Мониторинг отключен")
+	}
 
-	// Запускаем мониторинг в отдельной горутине
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	prom := NewPromClient(LoadPrometheusConfig())
+	loki := NewLokiClient(LoadLokiConfig())
+	minioCfg := LoadMinIOConfig()
+	minio := NewMinIOClient(minioCfg)
+	if minio != nil && monitoringEnabled {
+		go NewMinIOWatcher(minio, minioCluster(minioCfg), bot, adminID).Start(ctx)
+	}
+
+	// HTTP-проверки Ingress-ов и сервисов для /probes и алертов
+	probes := NewProbeWatcher(LoadProbeConfig(), bot, adminID)
+	if monitoringEnabled {
+		go probes.Start(ctx)
+	}
 
-	if os.Getenv("DISABLE_MONITORING") != "true" {
-		go monitor.Start(ctx)
+	// Плановые сводки в чат администратора
+	digester := NewDigester(LoadDigestConfig(), certCfg, bot, adminID, prom)
+	if adminID != 0 {
+		go digester.Start(ctx)
 	} else {
-		log.Println("⚠️ Мониторинг отключен")
+		log.Println("⚠️ TELEGRAM_CHAT_ID не задан, плановые дайджесты отключены")
+	}
+	// Плановая очистка кластеров, если задан CLEANUP_SCHEDULE
+	go NewCleaner(cleanupCfg, bot, adminID).Start(ctx)
+
+	// История потребления для /chart
+	metricsStore = NewMetricsStore(LoadMetricsConfig())
+	go metricsStore.Start(ctx, clusters.All())
+
+	// /healthz, /readyz и /metrics для Kubernetes и Prometheus
+	if addr := os.Getenv("HTTP_ADDR"); addr != "off" {
+		if addr == "" {
+			addr = ":8080"
+		}
+		go StartHTTPServer(ctx, addr, bot)
 	}
 
 	for update := range updates {
@@ -77,15 +154,25 @@ func main() {
 		}
 
 		var chatID int64
+		var messageID int
 		var cmd, args string
+		var user *tgbotapi.User
 
 		if update.Message != nil {
 			chatID = update.Message.Chat.ID
+			user = update.Message.From
 			cmd = update.Message.Command()
 			args = update.Message.CommandArguments()
+			// "/logs-history" Telegram разбирает как /logs с аргументами "history ..."
+			if cmd == "logs" && strings.HasPrefix(update.Message.Text, "/logs-history") {
+				cmd = "logs_history"
+				args = strings.TrimSpace(strings.TrimPrefix(args, "history"))
+			}
 			log.Printf("[MSG] %s: %s %s", update.Message.From.UserName, cmd, args)
 		} else if update.CallbackQuery != nil {
 			chatID = update.CallbackQuery.Message.Chat.ID
+			user = update.CallbackQuery.From
+			messageID = update.CallbackQuery.Message.MessageID
 			parts := strings.Fields(update.CallbackQuery.Data)
 			if len(parts) > 0 {
 				cmd = parts[0]
@@ -100,88 +187,244 @@ func main() {
 
 		// Проверка доступа
 		if adminID != 0 && chatID != adminID {
-			if cmd != "help" && cmd != "start" && cmd != "status" && cmd != "getpods" {
-				sendText(bot, chatID, "❌ Доступ запрещён.")
+			if !publicCommands[cmd] {
+				sendText(bot, chatID, T(langFor(chatID), "access_denied"))
+				// Имя команды от посторонних не попадает в метки, чтобы не раздувать их число
+				telemetry.Commands.Inc("-", "denied")
 				continue
 			}
 		}
 
+		if adminCommands[cmd] && !admins.Allows(user) {
+			sendText(bot, chatID, T(langFor(chatID), "admin_only"))
+			telemetry.Commands.Inc("-", "denied")
+			continue
+		}
+
+		cluster := clusters.For(chatID)
+		clientset := cluster.Typed
+		started := time.Now()
+		errorsBefore := telemetry.userErrors.Load()
+		result := "ok"
+		label := cmd
+
 		switch cmd {
 		case "start", "help":
 			sendHelpWithButtons(bot, chatID, clientset, ctx)
 
 		case "status":
-			handleStatus(bot, clientset, ctx, chatID)
+			handleStatus(bot, cluster, ctx, chatID)
 
 		case "getpods":
-			ns := strings.TrimSpace(args)
-			if ns == "" || ns == "all" {
-				handleGetAllPods(bot, clientset, ctx, chatID)
-			} else {
-				handleGetPods(bot, clientset, ctx, chatID, ns)
+			f, err := parsePodFilter(args)
+			if err != nil {
+				sendText(bot, chatID, T(langFor(chatID), "usage.getpods"))
+				result = "usage"
+				break
 			}
+			handleGetPods(bot, cluster, ctx, chatID, f)
+
+		case "podpage":
+			handlePodPage(bot, ctx, chatID, messageID, args)
 
 		case "logs":
+			req, err := parseLogsArgs(args)
+			if err != nil {
+				sendText(bot, chatID, T(langFor(chatID), "usage.logs"))
+				result = "usage"
+				break
+			}
+			handleLogs(bot, clientset, ctx, chatID, req)
+
+		case "logs_history":
+			handleLogsHistory(bot, loki, ctx, chatID, args)
+
+		case "loki":
+			handleLoki(bot, loki, ctx, chatID, args)
+
+		case "describe":
 			parts := strings.Fields(args)
-			if len(parts) < 2 {
-				sendText(bot, chatID, "Использование: /logs <namespace> <pod> [кол-во строк]")
-				continue
+			if len(parts) != 2 {
+				sendText(bot, chatID, T(langFor(chatID), "usage.describe"))
+				result = "usage"
+				break
 			}
-			ns, pod := parts[0], parts[1]
-			var tail int64 = 200
-			if len(parts) >= 3 {
-				if t, err := strconv.Atoi(parts[2]); err == nil {
-					tail = int64(t)
-				}
+			handleDescribe(bot, clientset, ctx, chatID, parts[0], parts[1])
+
+		case "top":
+			req, err := parseTopArgs(args)
+			if err != nil {
+				sendText(bot, chatID, T(langFor(chatID), "usage.top"))
+				result = "usage"
+				break
+			}
+			handleTop(bot, cluster, ctx, chatID, req)
+
+		case "chart":
+			req, err := parseChartArgs(args)
+			if err != nil {
+				sendText(bot, chatID, T(langFor(chatID), "usage.chart"))
+				result = "usage"
+				break
+			}
+			handleChart(bot, cluster, ctx, chatID, req)
+
+		case "promql":
+			req, err := parsePromArgs(args)
+			if err != nil {
+				sendText(bot, chatID, T(langFor(chatID), "usage.promql"))
+				result = "usage"
+				break
+			}
+			handlePromQL(bot, prom, ctx, chatID, req)
+
+		case "q":
+			handleSavedQuery(bot, prom, ctx, chatID, args)
+
+		case "digest":
+			handleDigest(bot, digester, cluster, ctx, chatID, args)
+
+		case "capacity":
+			handleCapacity(bot, cluster, ctx, chatID, args)
+
+		case "certs":
+			handleCerts(bot, cluster, certCfg, ctx, chatID)
+
+		case "backups":
+			handleBackups(bot, cluster, ctx, chatID, args)
+
+		case "backup":
+			parts := strings.Fields(args)
+			if len(parts) == 0 || len(parts) > 2 || parts[0] != "now" {
+				sendText(bot, chatID, T(langFor(chatID), "usage.backup"))
+				result = "usage"
+				break
+			}
+			ns := ""
+			if len(parts) == 2 {
+				ns = parts[1]
+			}
+			handleBackupNow(bot, cluster, veleroCfg, ctx, chatID, ns)
+
+		case "restore":
+			parts := strings.Fields(args)
+			if len(parts) == 0 || len(parts) > 2 {
+				sendText(bot, chatID, T(langFor(chatID), "usage.restore"))
+				result = "usage"
+				break
+			}
+			ns := ""
+			if len(parts) == 2 {
+				ns = parts[1]
+			}
+			handleRestore(bot, cluster, veleroCfg, ctx, chatID, user, parts[0], ns)
+
+		case "pvc":
+			handlePVC(bot, cluster, volumeCfg, ctx, chatID, args)
+
+		case "probes":
+			handleProbes(bot, probes, ctx, chatID)
+
+		case "storage":
+			handleStorage(bot, minio, ctx, chatID)
+
+		case "vpn":
+			handleVPN(bot, cluster, vpnCfg, ctx, chatID, user, args)
+
+		case "jobs":
+			handleJobs(bot, cluster, jobCfg, ctx, chatID, strings.TrimSpace(args))
+
+		case "cronjob":
+			parts := strings.Fields(args)
+			if len(parts) != 3 || parts[0] != "run" {
+				sendText(bot, chatID, T(langFor(chatID), "usage.cronjob"))
+				result = "usage"
+				break
+			}
+			handleCronJobRun(bot, cluster, ctx, chatID, user, parts[1], parts[2])
+
+		case "cleanup":
+			handleCleanup(bot, cluster, cleanupCfg, ctx, chatID, user, args)
+
+		case "cordon", "uncordon":
+			parts := strings.Fields(args)
+			if len(parts) != 1 {
+				sendText(bot, chatID, T(langFor(chatID), "usage."+cmd))
+				result = "usage"
+				break
+			}
+			if cmd == "cordon" {
+				handleCordon(bot, cluster, ctx, chatID, user, parts[0])
+			} else {
+				handleUncordon(bot, cluster, ctx, chatID, user, parts[0])
+			}
+
+		case "drain":
+			handleDrain(bot, cluster, drainCfg, ctx, chatID, user, args)
+
+		case "confirm", "cancel":
+			// Только кнопки под вопросом о подтверждении
+			if messageID == 0 {
+				result = "usage"
+				break
 			}
-			handleLogs(bot, clientset, ctx, chatID, ns, pod, tail)
+			handleConfirm(bot, ctx, chatID, user.ID, messageID, args, cmd == "confirm")
 
 		case "restart":
 			parts := strings.Fields(args)
 			if len(parts) != 2 {
-				sendText(bot, chatID, "Использование: /restart <namespace> <deployment>")
-				continue
+				sendText(bot, chatID, T(langFor(chatID), "usage.restart"))
+				result = "usage"
+				break
 			}
 			handleRestart(bot, clientset, ctx, chatID, parts[0], parts[1])
 		case "monitor":
-			handleMonitorStatus(bot, chatID, monitor)
+			handleMonitorStatus(bot, chatID, monitors[cluster.Name])
 
 		case "alerts":
-			handleAlertsStatus(bot, chatID, monitor)
+			handleAlertsStatus(bot, chatID, monitors[cluster.Name])
 
 		case "scale":
 			parts := strings.Fields(args)
 			if len(parts) != 3 {
-				sendText(bot, chatID, "Использование: /scale <namespace> <deployment> <реплики>")
-				continue
+				sendText(bot, chatID, T(langFor(chatID), "usage.scale"))
+				result = "usage"
+				break
 			}
 			handleScale(bot, clientset, ctx, chatID, parts[0], parts[1], parts[2])
 
+		case "lang":
+			handleLang(bot, chatID, args)
+
+		case "cluster":
+			handleCluster(bot, chatID, args)
+
 		default:
-			sendText(bot, chatID, "Неизвестная команда. /help")
+			sendText(bot, chatID, T(langFor(chatID), "unknown_command"))
+			result = "unknown"
+			label = "unknown"
 		}
+
+		if result == "ok" && telemetry.userErrors.Load() != errorsBefore {
+			result = "error"
+		}
+		telemetry.Commands.Inc(label, result)
+		telemetry.CommandDuration.Observe(time.Since(started).Seconds(), label)
 	}
 }
 
 // --- Help + кнопки ---
-func sendHelpWithButtons(bot *tgbotapi.BotAPI, chatID int64, clientset *kubernetes.Clientset, ctx context.Context) {
-	text := `Команды:
-
-*Основные команды:*	
-/status — список узлов
-/getpods [ns|all] — pod-ы
-/logs <ns> <pod> [tail] — логи pod-а
-
-*Мониторинг:*
-/monitor - статус мониторинга узлов
-/alerts - активные алерты
-
-*Управление:*
-/restart <ns> <deployment> - перезапуск deployment'а
-/scale <ns> <deployment> <replicas> - масштабирование
-
-*Помощь:*
-/help - показать это сообщение`
+func sendHelpWithButtons(bot *tgbotapi.BotAPI, chatID int64, clientset kubernetes.Interface, ctx context.Context) {
+	lang := langFor(chatID)
+	help := NewRich().Text(T(lang, "help.title")).Line().Line().
+		Bold(T(lang, "help.main.title")).Line().
+		Text(T(lang, "help.main")).Line().Line().
+		Bold(T(lang, "help.monitoring.title")).Line().
+		Text(T(lang, "help.monitoring")).Line().Line().
+		Bold(T(lang, "help.manage.title")).Line().
+		Text(T(lang, "help.manage")).Line().Line().
+		Bold(T(lang, "help.help.title")).Line().
+		Text(T(lang, "help.help"))
 
 	// Соберем список ns для кнопок
 	nss, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
@@ -191,42 +434,65 @@ func sendHelpWithButtons(bot *tgbotapi.BotAPI, chatID int64, clientset *kubernet
 
 	var rows [][]tgbotapi.InlineKeyboardButton
 	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("Статус узлов", "status"),
-		tgbotapi.NewInlineKeyboardButtonData("Pod-ы (все)", "getpods all"),
+		tgbotapi.NewInlineKeyboardButtonData(T(lang, "btn.status"), "status"),
+		tgbotapi.NewInlineKeyboardButtonData(T(lang, "btn.pods_all"), "getpods all"),
 	))
 	for _, ns := range nss.Items {
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("Pod-ы (%s)", ns.Name), "getpods "+ns.Name),
+			tgbotapi.NewInlineKeyboardButtonData(T(lang, "btn.pods_ns", ns.Name), "getpods "+ns.Name),
 		))
 	}
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ReplyMarkup = keyboard
-	bot.Send(msg)
+	sendRichMarkup(bot, chatID, help, tgbotapi.NewInlineKeyboardMarkup(rows...))
 }
 
 // --- Handlers ---
-func handleStatus(bot *tgbotapi.BotAPI, clientset *kubernetes.Clientset, ctx context.Context, chatID int64) {
-	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+func handleStatus(bot *tgbotapi.BotAPI, cluster *Cluster, ctx context.Context, chatID int64) {
+	snap, err := collectSnapshot(ctx, cluster)
 	if err != nil {
-		sendText(bot, chatID, "Ошибка: "+err.Error())
+		sendError(bot, chatID, err)
 		return
 	}
+	sendLongRich(bot, chatID, "status", renderStatus(langFor(chatID), clusterLabel(cluster.Name), snap.Nodes, snap.NodeMetrics, snap.Pods, snap.Taken))
+}
+
+// clusterSnapshot узлы, pod-ы и потребление кластера на один момент; общий сбор для /status и дайджеста
+type clusterSnapshot struct {
+	Nodes       []corev1.Node
+	Pods        []corev1.Pod
+	NodeMetrics map[string]struct{ CPU, Memory int64 } // пусто без metrics-server
+	Taken       time.Time
+}
+
+// collectSnapshot собирает данные кластера; недоступность metrics-server не считается ошибкой
+func collectSnapshot(ctx context.Context, cluster *Cluster) (*clusterSnapshot, error) {
+	clientset := cluster.Typed
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
 	// Получаем метрики узлов (если установлен metrics-server)
-	nodeMetrics, err := getNodeMetrics(ctx, clientset)
+	nodeMetrics, err := getNodeMetrics(ctx, cluster)
 	if err != nil {
 		log.Printf("⚠️ Metrics server не доступен: %v", err)
 	}
 	// Получаем все поды для подсчета
-	pods, _ := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
-	var sb strings.Builder
-	sb.WriteString("🖥️ *СТАТУС КЛАСТЕРА*\n\n")
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &clusterSnapshot{Nodes: nodes.Items, Pods: pods.Items, NodeMetrics: nodeMetrics, Taken: time.Now()}, nil
+}
+
+// renderStatus формирует отчёт /status
+func renderStatus(lang Lang, cluster string, nodes []corev1.Node, nodeMetrics map[string]struct{ CPU, Memory int64 }, pods []corev1.Pod, now time.Time) *Rich {
+	r := NewRich()
+	r.Text("🖥️ " + cluster).Bold(T(lang, "status.title")).Line().Line()
 	totalCPU, totalMemory := int64(0), int64(0)
 	usedCPU, usedMemory := int64(0), int64(0)
 	readyNodes := 0
 
-	for _, node := range nodes.Items {
+	for _, node := range nodes {
 		nodeReady, nodeStatus := getNodeStatus(node)
 		if nodeReady {
 			readyNodes++
@@ -239,82 +505,87 @@ func handleStatus(bot *tgbotapi.BotAPI, clientset *kubernetes.Clientset, ctx con
 		totalMemory += nodeMemory
 
 		// Использование ресурсов
-		cpuUsage, memoryUsage := getNodeUsage(node.Name, nodeMetrics, node, pods.Items)
+		cpuUsage, memoryUsage := getNodeUsage(node.Name, nodeMetrics, node, pods)
 		usedCPU += cpuUsage
 		usedMemory += memoryUsage
 		// Подсчет подов на узле
-		nodePods := countPodsOnNode(pods.Items, node.Name)
-		runningPods := countRunningPodsOnNode(pods.Items, node.Name)
+		nodePods := countPodsOnNode(pods, node.Name)
+		runningPods := countRunningPodsOnNode(pods, node.Name)
 
 		// Вывод информации об узле
-		sb.WriteString(fmt.Sprintf("%s *%s*\n", getStatusEmoji(nodeReady), node.Name))
-		sb.WriteString(fmt.Sprintf("   📊 Статус: %s\n", nodeStatus))
-		sb.WriteString(fmt.Sprintf("   🏷️  OS: %s | Arch: %s\n",
+		r.Text(getStatusEmoji(nodeReady) + " ").Bold(node.Name).Line()
+		r.Text(T(lang, "status.state", nodeStatus)).Line()
+		r.Text(T(lang, "status.os",
 			node.Status.NodeInfo.OperatingSystem,
-			node.Status.NodeInfo.Architecture))
+			node.Status.NodeInfo.Architecture)).Line()
 
 		// Использование CPU
 		cpuPercent := calculatePercent(cpuUsage, nodeCPU)
-		sb.WriteString(fmt.Sprintf("   🔵 CPU: %s/%s (%d%%) %s\n",
+		r.Text(T(lang, "status.cpu",
 			formatCPU(cpuUsage),
 			formatCPU(nodeCPU),
 			int(cpuPercent),
-			getProgressBar(cpuPercent, 8)))
+			getProgressBar(cpuPercent, 8))).Line()
 
 		// Использование Memory
 		memoryPercent := calculatePercent(memoryUsage, nodeMemory)
-		sb.WriteString(fmt.Sprintf("   🟠 Memory: %s/%s (%d%%) %s\n",
+		r.Text(T(lang, "status.memory",
 			formatMemory(memoryUsage),
 			formatMemory(nodeMemory),
 			int(memoryPercent),
-			getProgressBar(memoryPercent, 8)))
+			getProgressBar(memoryPercent, 8))).Line()
 
 		// Pods
-		sb.WriteString(fmt.Sprintf("   📦 Pods: %d/%d запущено\n", runningPods, nodePods))
+		r.Text(T(lang, "status.pods", runningPods, nodePods)).Line()
 
 		// Внешний IP
 		externalIP := getNodeExternalIP(node)
 		if externalIP != "" {
-			sb.WriteString(fmt.Sprintf("   🌐 IP: %s\n", externalIP))
+			r.Text(T(lang, "status.ip", externalIP)).Line()
 		}
 
 		// Возраст узла
-		age := time.Since(node.CreationTimestamp.Time).Round(time.Hour)
-		sb.WriteString(fmt.Sprintf("   ⏰ Возраст: %s\n", formatDuration(age)))
+		age := now.Sub(node.CreationTimestamp.Time).Round(time.Hour)
+		r.Text(T(lang, "status.age", formatDuration(lang, age))).Line()
 
-		sb.WriteString("\n")
+		r.Line()
 	}
 
 	// Добавим общую статистику кластера
-	sb.WriteString("📈 *ОБЩАЯ СТАТИСТИКА*\n")
-	sb.WriteString(fmt.Sprintf("   🖥️  Всего узлов: %d\n", len(nodes.Items)))
-	sb.WriteString(fmt.Sprintf("   🟢 Готовых: %d\n", readyNodes))
-	sb.WriteString(fmt.Sprintf("   🔴 Не готовых: %d\n", len(nodes.Items)-readyNodes))
+	r.Text("📈 ").Bold(T(lang, "status.summary")).Line()
+	r.Text(T(lang, "status.nodes_total", len(nodes))).Line()
+	r.Text(T(lang, "status.nodes_ready", readyNodes)).Line()
+	r.Text(T(lang, "status.nodes_not_ready", len(nodes)-readyNodes)).Line()
 
 	// Общее использование ресурсов
-	totalPods := len(pods.Items)
-	runningPods := countRunningPods(pods.Items)
-	sb.WriteString(fmt.Sprintf("   📦 Pods: %d/%d запущено\n", runningPods, totalPods))
+	totalPods := len(pods)
+	runningPods := countRunningPods(pods)
+	r.Text(T(lang, "status.pods", runningPods, totalPods)).Line()
 
 	if totalCPU > 0 && totalMemory > 0 {
 		totalCPUPercent := calculatePercent(usedCPU, totalCPU)
 		totalMemoryPercent := calculatePercent(usedMemory, totalMemory)
 
-		sb.WriteString(fmt.Sprintf("\n💾 *Использование ресурсов:*\n"))
-		sb.WriteString(fmt.Sprintf("   🔵 CPU: %s/%s (%d%%) %s\n",
+		r.Line()
+		r.Text("💾 ").Bold(T(lang, "status.usage")).Line()
+		r.Text(T(lang, "status.cpu",
 			formatCPU(usedCPU),
 			formatCPU(totalCPU),
 			int(totalCPUPercent),
-			getProgressBar(totalCPUPercent, 12)))
+			getProgressBar(totalCPUPercent, 12))).Line()
 
-		sb.WriteString(fmt.Sprintf("   🟠 Memory: %s/%s (%d%%) %s\n",
+		r.Text(T(lang, "status.memory",
 			formatMemory(usedMemory),
 			formatMemory(totalMemory),
 			int(totalMemoryPercent),
-			getProgressBar(totalMemoryPercent, 12)))
+			getProgressBar(totalMemoryPercent, 12))).Line()
+	}
+	if len(nodeMetrics) == 0 {
+		// Без metrics-server вместо потребления показана сумма requests
+		r.Line().Italic(T(lang, "status.requests_fallback")).Line()
 	}
 
-	sendLong(bot, chatID, sb.String())
+	return r
 }
 
 // Вспомогательные функции
@@ -338,76 +609,91 @@ func getStatusEmoji(ready bool) string {
 }
 
 func handleMonitorStatus(bot *tgbotapi.BotAPI, chatID int64, monitor *Monitor) {
-	statuses := monitor.GetNodeStatuses()
+	sendLongRich(bot, chatID, "monitor", renderMonitorStatus(langFor(chatID), clusterLabel(monitor.cluster), monitor.GetNodeStatuses(), time.Now()))
+}
 
-	var sb strings.Builder
-	sb.WriteString("📊 *Статус мониторинга узлов*\n\n")
+// renderMonitorStatus формирует отчёт /monitor
+func renderMonitorStatus(lang Lang, cluster string, statuses map[string]*NodeStatus, now time.Time) *Rich {
+	r := NewRich()
+	r.Text("📊 " + cluster).Bold(T(lang, "monitor.title")).Line().Line()
 
 	if len(statuses) == 0 {
-		sb.WriteString("ℹ️ Нет данных о узлах\n")
-	} else {
-		for nodeName, status := range statuses {
-			emoji := "🟢"
-			if status.Status != "Ready" {
-				emoji = "🔴"
-			}
+		r.Text(T(lang, "monitor.no_data")).Line()
+		return r
+	}
+	for _, nodeName := range sortedNodeNames(statuses) {
+		status := statuses[nodeName]
+		emoji := "🟢"
+		if status.Status != "Ready" {
+			emoji = "🔴"
+		}
 
-			duration := time.Since(status.LastSeen)
-			sb.WriteString(fmt.Sprintf("%s *%s*\n", emoji, nodeName))
-			sb.WriteString(fmt.Sprintf("   Статус: %s\n", status.Status))
-			sb.WriteString(fmt.Sprintf("   Последняя проверка: %s назад\n", formatDurationForAlert(duration)))
-			if status.Notified {
-				sb.WriteString("   ⚠️ Уведомление отправлено\n")
-			}
-			sb.WriteString("\n")
+		duration := now.Sub(status.LastSeen)
+		r.Text(emoji + " ").Bold(nodeName).Line()
+		r.Text(T(lang, "monitor.state", status.Status)).Line()
+		r.Text(T(lang, "monitor.last_seen", formatDurationForAlert(lang, duration))).Line()
+		if status.Notified {
+			r.Text(T(lang, "monitor.notified")).Line()
 		}
+		r.Line()
 	}
-
-	sendLong(bot, chatID, sb.String())
+	return r
 }
 
 // handleAlertsStatus показывает активные алерты
 func handleAlertsStatus(bot *tgbotapi.BotAPI, chatID int64, monitor *Monitor) {
-	statuses := monitor.GetNodeStatuses()
+	sendLongRich(bot, chatID, "alerts", renderAlertsStatus(langFor(chatID), clusterLabel(monitor.cluster), monitor.GetNodeStatuses(), time.Now()))
+}
 
-	var sb strings.Builder
-	sb.WriteString("🚨 *Активные алерты*\n\n")
+// renderAlertsStatus формирует отчёт /alerts
+func renderAlertsStatus(lang Lang, cluster string, statuses map[string]*NodeStatus, now time.Time) *Rich {
+	r := NewRich()
+	r.Text("🚨 " + cluster).Bold(T(lang, "alerts.title")).Line().Line()
 
 	hasAlerts := false
-	for nodeName, status := range statuses {
+	for _, nodeName := range sortedNodeNames(statuses) {
+		status := statuses[nodeName]
 		if status.Notified {
 			hasAlerts = true
-			duration := time.Since(status.LastSeen)
-			sb.WriteString(fmt.Sprintf("🔴 *%s*\n", nodeName))
-			sb.WriteString(fmt.Sprintf("   Проблема: %s\n", status.Status))
-			sb.WriteString(fmt.Sprintf("   Длительность: %s\n", formatDurationForAlert(duration)))
-			sb.WriteString("\n")
+			duration := now.Sub(status.LastSeen)
+			r.Text("🔴 ").Bold(nodeName).Line()
+			r.Text(T(lang, "alerts.problem", status.Status)).Line()
+			r.Text(T(lang, "alerts.duration", formatDurationForAlert(lang, duration))).Line()
+			r.Line()
 		}
 	}
 
 	if !hasAlerts {
-		sb.WriteString("✅ Активных алертов нет\n")
+		r.Text(T(lang, "alerts.none")).Line()
 	}
-
-	sendLong(bot, chatID, sb.String())
+	return r
 }
 
-func getNodeMetrics(ctx context.Context, clientset *kubernetes.Clientset) (map[string]struct{ CPU, Memory int64 }, error) {
-	metrics := make(map[string]struct{ CPU, Memory int64 })
+// clusterLabel метка кластера в заголовках отчётов, при одном кластере не нужна
+func clusterLabel(name string) string {
+	if len(clusters.Names()) < 2 {
+		return ""
+	}
+	return alertPrefix(name)
+}
 
-	// Пробуем получить конфигурацию
-	config, err := getK8sConfig()
-	if err != nil {
-		return metrics, err
+// sortedNodeNames возвращает имена узлов в алфавитном порядке для стабильного вывода
+func sortedNodeNames(statuses map[string]*NodeStatus) []string {
+	names := make([]string, 0, len(statuses))
+	for name := range statuses {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
+}
 
-	// Создаем клиент для метрик
-	metricsClient, err := metricsv.NewForConfig(config)
-	if err != nil {
-		return metrics, err
+func getNodeMetrics(ctx context.Context, cluster *Cluster) (map[string]struct{ CPU, Memory int64 }, error) {
+	metrics := make(map[string]struct{ CPU, Memory int64 })
+	if !cluster.HasMetrics() {
+		return metrics, fmt.Errorf("API %s не зарегистрирован", metricsAPI)
 	}
 
-	nodeMetricsList, err := metricsClient.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+	nodeMetricsList, err := cluster.Metrics.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return metrics, err
 	}
@@ -422,23 +708,6 @@ func getNodeMetrics(ctx context.Context, clientset *kubernetes.Clientset) (map[s
 	return metrics, nil
 }
 
-func getK8sConfig() (*rest.Config, error) {
-	// Сначала пробуем in-cluster config (если запущено в поде)
-	config, err := rest.InClusterConfig()
-	if err == nil {
-		return config, nil
-	}
-
-	// Если не в кластере, пробуем kubeconfig из файла
-	home, _ := os.UserHomeDir()
-	kubeconfig := filepath.Join(home, ".kube", "config")
-	if _, err := os.Stat(kubeconfig); err == nil {
-		return clientcmd.BuildConfigFromFlags("", kubeconfig)
-	}
-
-	return nil, fmt.Errorf("не удалось найти конфигурацию Kubernetes")
-}
-
 func getNodeUsage(nodeName string, metrics map[string]struct{ CPU, Memory int64 }, node corev1.Node, pods []corev1.Pod) (int64, int64) {
 	// Если есть метрики - используем их
 	if metric, exists := metrics[nodeName]; exists {
@@ -535,16 +804,17 @@ func formatMemory(bytes int64) string {
 	return fmt.Sprintf("%.1fMB", float64(bytes)/float64(MB))
 }
 
-func formatDuration(d time.Duration) string {
+// formatDuration форматирует возраст в короткой форме: 3д, 5ч, 10м
+func formatDuration(lang Lang, d time.Duration) string {
 	days := int(d.Hours() / 24)
 	if days > 0 {
-		return fmt.Sprintf("%dд", days)
+		return T(lang, "dur.short.day", days)
 	}
 	hours := int(d.Hours())
 	if hours > 0 {
-		return fmt.Sprintf("%dч", hours)
+		return T(lang, "dur.short.hour", hours)
 	}
-	return fmt.Sprintf("%dм", int(d.Minutes()))
+	return T(lang, "dur.short.minute", int(d.Minutes()))
 }
 
 func getProgressBar(percent float64, length int) string {
@@ -577,103 +847,34 @@ func countReadyNodes(nodes []corev1.Node) int {
 	return count
 }
 
-func handleGetPods(bot *tgbotapi.BotAPI, clientset *kubernetes.Clientset, ctx context.Context, chatID int64, ns string) {
-	pods, err := clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
-	if err != nil {
-		sendText(bot, chatID, "Ошибка: "+err.Error())
-		return
-	}
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("📦 Pod-ы `%s`:\n", ns))
-	for _, p := range pods.Items {
-		sb.WriteString(fmt.Sprintf("- %s (%s)\n", p.Name, p.Status.Phase))
-	}
-	sendLong(bot, chatID, sb.String())
-}
-
-func handleGetAllPods(bot *tgbotapi.BotAPI, clientset *kubernetes.Clientset, ctx context.Context, chatID int64) {
-	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
-	if err != nil {
-		sendText(bot, chatID, "Ошибка: "+err.Error())
-		return
-	}
-	var sb strings.Builder
-	sb.WriteString("📦 Pod-ы во всех namespace:\n")
-	for _, p := range pods.Items {
-		sb.WriteString(fmt.Sprintf("[%s] %s (%s)\n", p.Namespace, p.Name, p.Status.Phase))
-	}
-	sendLong(bot, chatID, sb.String())
-}
-
-func handleLogs(bot *tgbotapi.BotAPI, clientset *kubernetes.Clientset, ctx context.Context, chatID int64, ns, pod string, tail int64) {
-	opts := &corev1.PodLogOptions{TailLines: &tail}
-	req := clientset.CoreV1().Pods(ns).GetLogs(pod, opts)
-	stream, err := req.Stream(ctx)
-	if err != nil {
-		sendText(bot, chatID, "Ошибка логов: "+err.Error())
-		return
-	}
-	data, err := io.ReadAll(stream)
-	stream.Close()
-	if err != nil {
-		sendText(bot, chatID, "Ошибка чтения логов: "+err.Error())
-		return
-	}
-	if len(data) == 0 {
-		sendText(bot, chatID, "Логи пустые")
-		return
-	}
-	sendLong(bot, chatID, string(data))
-}
-
-func handleRestart(bot *tgbotapi.BotAPI, clientset *kubernetes.Clientset, ctx context.Context, chatID int64, ns, dep string) {
+func handleRestart(bot *tgbotapi.BotAPI, clientset kubernetes.Interface, ctx context.Context, chatID int64, ns, dep string) {
 	now := time.Now().Format(time.RFC3339)
 	patch := []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":"%s"}}}}}`, now))
 	_, err := clientset.AppsV1().Deployments(ns).Patch(ctx, dep, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
 	if err != nil {
-		sendText(bot, chatID, "Ошибка: "+err.Error())
+		sendError(bot, chatID, err)
 		return
 	}
-	sendText(bot, chatID, fmt.Sprintf("✅ Deployment %s/%s перезапущен", ns, dep))
+	sendText(bot, chatID, T(langFor(chatID), "restart.done", ns, dep))
 }
 
-func handleScale(bot *tgbotapi.BotAPI, clientset *kubernetes.Clientset, ctx context.Context, chatID int64, ns, dep, repStr string) {
+func handleScale(bot *tgbotapi.BotAPI, clientset kubernetes.Interface, ctx context.Context, chatID int64, ns, dep, repStr string) {
 	rep, err := strconv.Atoi(repStr)
 	if err != nil {
-		sendText(bot, chatID, "Реплики должны быть числом")
+		sendText(bot, chatID, T(langFor(chatID), "scale.bad_replicas"))
 		return
 	}
 	d, err := clientset.AppsV1().Deployments(ns).Get(ctx, dep, metav1.GetOptions{})
 	if err != nil {
-		sendText(bot, chatID, "Ошибка: "+err.Error())
+		sendError(bot, chatID, err)
 		return
 	}
 	r := int32(rep)
 	d.Spec.Replicas = &r
 	_, err = clientset.AppsV1().Deployments(ns).Update(ctx, d, metav1.UpdateOptions{})
 	if err != nil {
-		sendText(bot, chatID, "Ошибка: "+err.Error())
-		return
-	}
-	sendText(bot, chatID, fmt.Sprintf("✅ Deployment %s/%s → %d реплик", ns, dep, rep))
-}
-
-// --- Отправка сообщений ---
-func sendText(bot *tgbotapi.BotAPI, chatID int64, txt string) {
-	msg := tgbotapi.NewMessage(chatID, txt)
-	msg.ParseMode = "Markdown"
-	bot.Send(msg)
-}
-
-func sendLong(bot *tgbotapi.BotAPI, chatID int64, txt string) {
-	if len(txt) < MaxMsgLen {
-		sendText(bot, chatID, "```\n"+txt+"\n```")
+		sendError(bot, chatID, err)
 		return
 	}
-	tmp := "/tmp/out.txt"
-	_ = os.WriteFile(tmp, []byte(txt), 0644)
-	doc := tgbotapi.NewDocument(chatID, tgbotapi.FilePath(tmp))
-	doc.Caption = "Результат в файле"
-	bot.Send(doc)
-	_ = os.Remove(tmp)
+	sendText(bot, chatID, T(langFor(chatID), "scale.done", ns, dep, Plural(langFor(chatID), rep, "replica")))
 }
@@ -0,0 +1,551 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// minioDownAfter сколько проверок подряд MinIO должен не отвечать до алерта; перезапуск pod-а не в счёт
+const minioDownAfter = 2
+
+// MinIOClient опрашивает health и Prometheus-метрики MinIO
+type MinIOClient struct {
+	cfg  MinIOConfig
+	http *http.Client
+}
+
+// NewMinIOClient создаёт клиент; при пустом URL возвращает nil
+func NewMinIOClient(cfg MinIOConfig) *MinIOClient {
+	if cfg.URL == "" {
+		return nil
+	}
+	return &MinIOClient{cfg: cfg, http: &http.Client{Timeout: cfg.Timeout, Transport: instrumentTransport(nil, "minio", "")}}
+}
+
+// metricSample одна строка текстового формата Prometheus
+type metricSample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// minioDisk диск сервера MinIO
+type minioDisk struct {
+	Server string
+	Path   string
+	Total  float64
+	Free   float64
+}
+
+// minioBucket размер бакета по данным сканера MinIO
+type minioBucket struct {
+	Name    string
+	Size    float64
+	Objects float64
+}
+
+// minioPVC PVC с данными MinIO; на hostPath ёмкость не ограничена, поэтому занятое считается по бакетам
+type minioPVC struct {
+	Namespace string
+	Name      string
+	Phase     string
+	Capacity  int64
+	Used      int64
+}
+
+// minioReport состояние MinIO на момент опроса
+type minioReport struct {
+	Taken          time.Time
+	Live           bool
+	ClusterHealthy bool
+	HealthErr      string
+	MetricsErr     string
+	UsableTotal    float64
+	UsableFree     float64
+	OfflineNodes   float64
+	OfflineDisks   float64
+	Disks          []minioDisk
+	Buckets        []minioBucket
+	PVC            *minioPVC
+}
+
+// Healthy сервер жив и у кластера MinIO есть кворум на запись
+func (r *minioReport) Healthy() bool {
+	return r.Live && r.ClusterHealthy
+}
+
+// BucketsSize суммарный размер бакетов
+func (r *minioReport) BucketsSize() float64 {
+	var sum float64
+	for _, b := range r.Buckets {
+		sum += b.Size
+	}
+	return sum
+}
+
+func (c *MinIOClient) get(ctx context.Context, path string) (int, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.URL+path, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.cfg.MaxResponseBytes+1))
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+	if int64(len(body)) > c.cfg.MaxResponseBytes {
+		return resp.StatusCode, nil, fmt.Errorf("ответ MinIO больше %d байт", c.cfg.MaxResponseBytes)
+	}
+	return resp.StatusCode, body, nil
+}
+
+// Health проверяет /minio/health/live и /minio/health/cluster
+func (c *MinIOClient) Health(ctx context.Context) (live, cluster bool, err error) {
+	status, _, err := c.get(ctx, "/minio/health/live")
+	if err != nil {
+		return false, false, err
+	}
+	if status != http.StatusOK {
+		return false, false, fmt.Errorf("/minio/health/live: %d", status)
+	}
+	status, _, err = c.get(ctx, "/minio/health/cluster")
+	if err != nil {
+		return true, false, err
+	}
+	if status != http.StatusOK {
+		return true, false, fmt.Errorf("/minio/health/cluster: %d, нет кворума на запись", status)
+	}
+	return true, true, nil
+}
+
+// Metrics метрики кластера; новые версии MinIO отдают метрики бакетов отдельным endpoint-ом
+func (c *MinIOClient) Metrics(ctx context.Context) ([]metricSample, error) {
+	samples, err := c.metrics(ctx, "/minio/v2/metrics/cluster")
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range samples {
+		if strings.HasPrefix(s.Name, "minio_bucket_usage_") {
+			return samples, nil
+		}
+	}
+	if buckets, err := c.metrics(ctx, "/minio/v2/metrics/bucket"); err == nil {
+		samples = append(samples, buckets...)
+	}
+	return samples, nil
+}
+
+func (c *MinIOClient) metrics(ctx context.Context, path string) ([]metricSample, error) {
+	status, body, err := c.get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("%s: %d %s", path, status, strings.TrimSpace(string(body)))
+	}
+	return parseMetricsText(bytes.NewReader(body))
+}
+
+// Report собирает health, ёмкость, диски, бакеты и PVC; ошибки частей попадают в отчёт
+func (c *MinIOClient) Report(ctx context.Context, cluster *Cluster) *minioReport {
+	r := &minioReport{Taken: time.Now()}
+	var err error
+	if r.Live, r.ClusterHealthy, err = c.Health(ctx); err != nil {
+		r.HealthErr = err.Error()
+	}
+	if samples, err := c.Metrics(ctx); err != nil {
+		r.MetricsErr = err.Error()
+	} else {
+		applyMinIOMetrics(r, samples)
+	}
+	if cluster != nil && c.cfg.PVC != "" {
+		r.PVC = minioPVCUsage(ctx, cluster, c.cfg.PVC, int64(r.BucketsSize()))
+	}
+	return r
+}
+
+// applyMinIOMetrics раскладывает метрики v2 по отчёту
+func applyMinIOMetrics(r *minioReport, samples []metricSample) {
+	disks := make(map[string]*minioDisk)
+	buckets := make(map[string]*minioBucket)
+	disk := func(s metricSample) *minioDisk {
+		key := s.Labels["server"] + "\x00" + s.Labels["disk"]
+		if disks[key] == nil {
+			disks[key] = &minioDisk{Server: s.Labels["server"], Path: s.Labels["disk"]}
+		}
+		return disks[key]
+	}
+	bucket := func(s metricSample) *minioBucket {
+		name := s.Labels["bucket"]
+		if buckets[name] == nil {
+			buckets[name] = &minioBucket{Name: name}
+		}
+		return buckets[name]
+	}
+	for _, s := range samples {
+		switch s.Name {
+		case "minio_cluster_capacity_usable_total_bytes":
+			r.UsableTotal = s.Value
+		case "minio_cluster_capacity_usable_free_bytes":
+			r.UsableFree = s.Value
+		case "minio_cluster_nodes_offline_total":
+			r.OfflineNodes = s.Value
+		case "minio_cluster_disk_offline_total", "minio_cluster_drive_offline_total":
+			r.OfflineDisks = s.Value
+		case "minio_node_disk_total_bytes", "minio_node_drive_total_bytes":
+			disk(s).Total = s.Value
+		case "minio_node_disk_free_bytes", "minio_node_drive_free_bytes":
+			disk(s).Free = s.Value
+		case "minio_bucket_usage_total_bytes":
+			bucket(s).Size = s.Value
+		case "minio_bucket_usage_object_total":
+			bucket(s).Objects = s.Value
+		}
+	}
+	for _, d := range disks {
+		if d.Total > 0 {
+			r.Disks = append(r.Disks, *d)
+		}
+	}
+	sort.Slice(r.Disks, func(i, j int) bool {
+		return r.Disks[i].Server+r.Disks[i].Path < r.Disks[j].Server+r.Disks[j].Path
+	})
+	for _, b := range buckets {
+		r.Buckets = append(r.Buckets, *b)
+	}
+	sort.Slice(r.Buckets, func(i, j int) bool {
+		if r.Buckets[i].Size != r.Buckets[j].Size {
+			return r.Buckets[i].Size > r.Buckets[j].Size
+		}
+		return r.Buckets[i].Name < r.Buckets[j].Name
+	})
+}
+
+// minioPVCUsage ёмкость PVC из status.capacity или запроса; занятое — размер бакетов
+func minioPVCUsage(ctx context.Context, cluster *Cluster, ref string, used int64) *minioPVC {
+	ns, name, ok := strings.Cut(ref, "/")
+	if !ok || ns == "" || name == "" {
+		log.Printf("⚠️ Некорректный MINIO_PVC %q, ожидается namespace/имя", ref)
+		return nil
+	}
+	pvc, err := cluster.Typed.CoreV1().PersistentVolumeClaims(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		log.Printf("⚠️ [%s] Не удалось получить PVC MinIO %s: %v", cluster.Name, ref, err)
+		return nil
+	}
+	p := &minioPVC{Namespace: ns, Name: name, Phase: string(pvc.Status.Phase), Used: used}
+	if q, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		p.Capacity = q.Value()
+	} else if q, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		p.Capacity = q.Value()
+	}
+	return p
+}
+
+// parseMetricsText разбирает текстовый формат Prometheus: имя{метки} значение [время]
+func parseMetricsText(r io.Reader) ([]metricSample, error) {
+	var samples []metricSample
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.IndexAny(line, "{ \t")
+		if i < 0 {
+			return nil, fmt.Errorf("строка %d: нет значения", n)
+		}
+		s := metricSample{Name: line[:i], Labels: map[string]string{}}
+		rest := line[i:]
+		if strings.HasPrefix(rest, "{") {
+			var err error
+			if rest, err = parseMetricLabels(rest[1:], s.Labels); err != nil {
+				return nil, fmt.Errorf("строка %d: %w", n, err)
+			}
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("строка %d: нет значения", n)
+		}
+		v, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: %w", n, err)
+		}
+		s.Value = v
+		samples = append(samples, s)
+	}
+	return samples, sc.Err()
+}
+
+// parseMetricLabels читает метки до закрывающей скобки и возвращает остаток строки
+func parseMetricLabels(s string, labels map[string]string) (string, error) {
+	for {
+		s = strings.TrimLeft(s, " ,")
+		if strings.HasPrefix(s, "}") {
+			return s[1:], nil
+		}
+		eq := strings.Index(s, "=")
+		if eq < 0 || len(s) < eq+2 || s[eq+1] != '"' {
+			return "", fmt.Errorf("некорректные метки")
+		}
+		name := strings.TrimSpace(s[:eq])
+		s = s[eq+2:]
+		var val strings.Builder
+		closed := false
+		for i := 0; i < len(s); i++ {
+			switch c := s[i]; {
+			case c == '\\' && i+1 < len(s):
+				i++
+				if s[i] == 'n' {
+					val.WriteByte('\n')
+				} else {
+					val.WriteByte(s[i])
+				}
+			case c == '"':
+				s, closed = s[i+1:], true
+			default:
+				val.WriteByte(c)
+			}
+			if closed {
+				break
+			}
+		}
+		if !closed {
+			return "", fmt.Errorf("незакрытое значение метки %s", name)
+		}
+		labels[name] = val.String()
+	}
+}
+
+// freePercent доля свободного места в процентах
+func freePercent(free, total float64) float64 {
+	if total <= 0 {
+		return 100
+	}
+	return 100 * free / total
+}
+
+// MinIOWatcher алерты о недоступности MinIO и нехватке места на диске и в PVC
+type MinIOWatcher struct {
+	client  *MinIOClient
+	cluster *Cluster
+	bot     *tgbotapi.BotAPI
+	adminID int64
+
+	failures int
+	down     bool
+	diskLow  map[string]bool // server:disk
+	pvcLow   bool
+}
+
+// NewMinIOWatcher создаёт наблюдателя; cluster нужен только для PVC и может быть nil
+func NewMinIOWatcher(client *MinIOClient, cluster *Cluster, bot *tgbotapi.BotAPI, adminID int64) *MinIOWatcher {
+	return &MinIOWatcher{client: client, cluster: cluster, bot: bot, adminID: adminID, diskLow: make(map[string]bool)}
+}
+
+// Start проверяет MinIO сразу и затем каждые PollInterval
+func (w *MinIOWatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.client.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		w.check(w.client.Report(ctx, w.cluster))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *MinIOWatcher) check(r *minioReport) {
+	lang := langFor(w.adminID)
+	name := w.clusterName()
+	warn := w.client.cfg.FreeWarnPercent
+
+	if r.Healthy() {
+		w.failures = 0
+		if w.down {
+			w.down = false
+			w.notify("minio_up", NewRich().Text("✅ "+alertPrefix(name)).Bold(T(lang, "minio.up.title")))
+		}
+	} else if w.failures++; w.failures >= minioDownAfter && !w.down {
+		w.down = true
+		w.notify("minio_down", renderMinIODownAlert(lang, name, w.client.cfg.URL, r.HealthErr))
+		log.Printf("🔔 MinIO недоступен: %s", r.HealthErr)
+	}
+
+	// Без метрик состояние дисков неизвестно, прежние алерты не сбрасываем
+	if r.MetricsErr == "" {
+		present := make(map[string]bool, len(r.Disks))
+		for _, d := range r.Disks {
+			key := d.Server + ":" + d.Path
+			present[key] = true
+			low := freePercent(d.Free, d.Total) < warn
+			if low && !w.diskLow[key] {
+				w.notify("minio_disk_low", renderMinIOSpaceAlert(lang, name, T(lang, "minio.disk_low.title"), T(lang, "minio.disk"), key, int64(d.Free), int64(d.Total)))
+			} else if !low && w.diskLow[key] {
+				w.notify("minio_disk_ok", NewRich().Text("✅ "+alertPrefix(name)).Bold(T(lang, "minio.disk_ok.title")).Text(" ").Code(key))
+			}
+			w.diskLow[key] = low
+		}
+		for key := range w.diskLow {
+			if !present[key] {
+				delete(w.diskLow, key)
+			}
+		}
+	}
+
+	if p := r.PVC; p != nil && p.Capacity > 0 && r.MetricsErr == "" {
+		ref := p.Namespace + "/" + p.Name
+		free := max(p.Capacity-p.Used, 0)
+		low := freePercent(float64(free), float64(p.Capacity)) < warn
+		if low && !w.pvcLow {
+			w.notify("minio_pvc_low", renderMinIOSpaceAlert(lang, name, T(lang, "minio.pvc_low.title"), "PVC:", ref, free, p.Capacity))
+		} else if !low && w.pvcLow {
+			w.notify("minio_pvc_ok", NewRich().Text("✅ "+alertPrefix(name)).Bold(T(lang, "minio.pvc_ok.title")).Text(" ").Code(ref))
+		}
+		w.pvcLow = low
+	}
+}
+
+func (w *MinIOWatcher) clusterName() string {
+	if w.cluster == nil {
+		return ""
+	}
+	return w.cluster.Name
+}
+
+func (w *MinIOWatcher) notify(kind string, r *Rich) {
+	result := "sent"
+	if err := sendRich(w.bot, w.adminID, r); err != nil {
+		result = "failed"
+	}
+	telemetry.Notifications.Inc(kind, result)
+}
+
+// renderMinIODownAlert MinIO не отвечает или потерял кворум
+func renderMinIODownAlert(lang Lang, cluster, url, reason string) *Rich {
+	r := NewRich().
+		Text("🚨 " + alertPrefix(cluster)).Bold(T(lang, "minio.down.title")).Line().Line().
+		Text("🪣 MinIO: ").Code(url).Line()
+	if reason != "" {
+		r.Text(T(lang, "alert.reason", reason)).Line()
+	}
+	return r.Text(T(lang, "minio.down.text"))
+}
+
+// renderMinIOSpaceAlert свободного места на диске или в PVC меньше порога
+func renderMinIOSpaceAlert(lang Lang, cluster, title, label, subject string, free, total int64) *Rich {
+	return NewRich().
+		Text("🚨 "+alertPrefix(cluster)).Bold(title).Line().Line().
+		Text("💽 ").Bold(label).Text(" ").Code(subject).Line().
+		Text("📉 ").Bold(T(lang, "minio.free")).Textf(" %s / %s (%.1f%%)", formatMemory(free), formatMemory(total), freePercent(float64(free), float64(total)))
+}
+
+// handleStorage показывает состояние MinIO: /storage
+func handleStorage(bot *tgbotapi.BotAPI, client *MinIOClient, ctx context.Context, chatID int64) {
+	lang := langFor(chatID)
+	if client == nil {
+		sendText(bot, chatID, T(lang, "minio.not_configured"))
+		return
+	}
+	sendLongRich(bot, chatID, "storage", renderStorage(lang, client.Report(ctx, minioCluster(client.cfg)), client.cfg.FreeWarnPercent))
+}
+
+// minioCluster кластер, в котором развёрнут MinIO
+func minioCluster(cfg MinIOConfig) *Cluster {
+	if cfg.Cluster != "" {
+		if c, ok := clusters.Get(cfg.Cluster); ok {
+			return c
+		}
+		log.Printf("⚠️ Кластер MinIO %q не найден, используется кластер по умолчанию", cfg.Cluster)
+	}
+	if all := clusters.All(); len(all) > 0 {
+		return all[0]
+	}
+	return nil
+}
+
+// renderStorage отчёт /storage: здоровье, ёмкость, диски, PVC и бакеты по размеру
+func renderStorage(lang Lang, r *minioReport, warn float64) *Rich {
+	out := NewRich().Text("🪣 ").Bold(T(lang, "minio.title")).Line().Line()
+	switch {
+	case r.Healthy():
+		out.Text("✅ " + T(lang, "minio.healthy")).Line()
+	case r.Live:
+		out.Text("⚠️ " + T(lang, "minio.no_quorum", r.HealthErr)).Line()
+	default:
+		out.Text("❌ " + T(lang, "minio.unreachable", r.HealthErr)).Line()
+	}
+	if r.OfflineNodes > 0 || r.OfflineDisks > 0 {
+		out.Text("⚠️ " + T(lang, "minio.offline", int(r.OfflineNodes), int(r.OfflineDisks))).Line()
+	}
+	if r.MetricsErr != "" {
+		out.Text("📊 " + T(lang, "minio.metrics_error", r.MetricsErr)).Line()
+	}
+	if r.UsableTotal > 0 {
+		out.Text("📦 ").Bold(T(lang, "minio.capacity")).
+			Textf(" %s / %s (%.1f%%)", formatMemory(int64(r.UsableFree)), formatMemory(int64(r.UsableTotal)), freePercent(r.UsableFree, r.UsableTotal)).Line()
+	}
+	if p := r.PVC; p != nil && p.Capacity > 0 {
+		out.Text("💽 ").Bold("PVC").Text(" ").Code(p.Namespace + "/" + p.Name).
+			Text(" " + T(lang, "minio.pvc_used", formatMemory(p.Used), formatMemory(p.Capacity), 100*float64(p.Used)/float64(p.Capacity))).Line()
+	}
+
+	if len(r.Disks) > 0 {
+		var sb strings.Builder
+		w := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
+		fmt.Fprintln(w, "DISK\tFREE\tTOTAL\tFREE%")
+		for _, d := range r.Disks {
+			pct := freePercent(d.Free, d.Total)
+			mark := ""
+			if pct < warn {
+				mark = "!"
+			}
+			fmt.Fprintf(w, "%s:%s\t%s\t%s\t%.1f%s\n", d.Server, d.Path, formatMemory(int64(d.Free)), formatMemory(int64(d.Total)), pct, mark)
+		}
+		w.Flush()
+		out.Line().Pre(strings.TrimRight(sb.String(), "\n"))
+	}
+
+	if r.MetricsErr == "" {
+		out.Line().Bold(T(lang, "minio.buckets", len(r.Buckets))).Line()
+		if len(r.Buckets) == 0 {
+			out.Text(T(lang, "minio.no_buckets"))
+		} else {
+			var sb strings.Builder
+			w := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
+			fmt.Fprintln(w, "BUCKET\tSIZE\tOBJECTS")
+			for _, b := range r.Buckets {
+				fmt.Fprintf(w, "%s\t%s\t%.0f\n", b.Name, formatMemory(int64(b.Size)), b.Objects)
+			}
+			w.Flush()
+			out.Pre(strings.TrimRight(sb.String(), "\n"))
+		}
+	}
+	return out
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const minioClusterMetrics = `# HELP minio_cluster_capacity_usable_total_bytes Total cluster usable storage capacity
+# TYPE minio_cluster_capacity_usable_total_bytes gauge
+minio_cluster_capacity_usable_total_bytes{server="127.0.0.1:9000"} 1.073741824e+11
+minio_cluster_capacity_usable_free_bytes{server="127.0.0.1:9000"} 5.36870912e+09
+minio_cluster_nodes_offline_total{server="127.0.0.1:9000"} 0
+minio_cluster_drive_offline_total{server="127.0.0.1:9000"} 1
+minio_node_drive_total_bytes{disk="/data1",server="minio-0:9000"} 1.073741824e+11
+minio_node_drive_free_bytes{disk="/data1",server="minio-0:9000"} 5.36870912e+09
+minio_node_drive_total_bytes{disk="/data2",server="minio-0:9000"} 1.073741824e+11
+minio_node_drive_free_bytes{disk="/data2",server="minio-0:9000"} 5.36870912e+10
+`
+
+const minioBucketMetrics = `minio_bucket_usage_total_bytes{bucket="velero",server="127.0.0.1:9000"} 3.221225472e+09
+minio_bucket_usage_object_total{bucket="velero",server="127.0.0.1:9000"} 1200
+minio_bucket_usage_total_bytes{bucket="loki",server="127.0.0.1:9000"} 8.589934592e+09
+minio_bucket_usage_object_total{bucket="loki",server="127.0.0.1:9000"} 52000
+minio_bucket_usage_total_bytes{bucket="empty",server="127.0.0.1:9000"} 0
+minio_bucket_usage_object_total{bucket="empty",server="127.0.0.1:9000"} 0
+`
+
+// fakeMinIO заменяет MinIO: health-эндпоинты и метрики v2 настраиваются на лету
+type fakeMinIO struct {
+	mu       sync.Mutex
+	live     int
+	cluster  int
+	metrics  string
+	buckets  string
+	token    string
+	requests []string
+}
+
+func (f *fakeMinIO) set(live, cluster int, metrics string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.live, f.cluster, f.metrics = live, cluster, metrics
+}
+
+func newFakeMinIO(t *testing.T) (*fakeMinIO, *MinIOClient) {
+	t.Helper()
+	f := &fakeMinIO{live: 200, cluster: 200, metrics: minioClusterMetrics, buckets: minioBucketMetrics, token: "secret"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.requests = append(f.requests, r.URL.Path)
+		if strings.HasPrefix(r.URL.Path, "/minio/v2/metrics/") && r.Header.Get("Authorization") != "Bearer "+f.token {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/minio/health/live":
+			w.WriteHeader(f.live)
+		case "/minio/health/cluster":
+			w.WriteHeader(f.cluster)
+		case "/minio/v2/metrics/cluster":
+			if f.live != http.StatusOK {
+				w.WriteHeader(f.live)
+				return
+			}
+			_, _ = w.Write([]byte(f.metrics))
+		case "/minio/v2/metrics/bucket":
+			_, _ = w.Write([]byte(f.buckets))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	cfg := DefaultMinIOConfig()
+	cfg.URL = srv.URL
+	cfg.Token = "secret"
+	cfg.Timeout = 2 * time.Second
+	return f, NewMinIOClient(cfg)
+}
+
+func minioPVCObject(capacity string) runtime.Object {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "minio-system", Name: "minio-pvc"},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Phase:    corev1.ClaimBound,
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)},
+		},
+	}
+}
+
+func TestMinIOReport(t *testing.T) {
+	f, client := newFakeMinIO(t)
+	cluster := newFakeCluster(t, nil, []runtime.Object{minioPVCObject("20Gi")})
+	r := client.Report(context.Background(), cluster)
+
+	if !r.Healthy() || r.HealthErr != "" || r.MetricsErr != "" {
+		t.Fatalf("отчёт: %+v", r)
+	}
+	if r.UsableTotal != 100<<30 || r.UsableFree != 5<<30 || r.OfflineDisks != 1 {
+		t.Errorf("ёмкость: total=%v free=%v offline=%v", r.UsableTotal, r.UsableFree, r.OfflineDisks)
+	}
+	if len(r.Disks) != 2 || r.Disks[0].Path != "/data1" || r.Disks[0].Free != 5<<30 {
+		t.Errorf("диски: %+v", r.Disks)
+	}
+	// Бакеты от большего к меньшему; в кластерных метриках их нет, поэтому запрошен отдельный endpoint
+	if len(r.Buckets) != 3 || r.Buckets[0].Name != "loki" || r.Buckets[1].Name != "velero" || r.Buckets[0].Objects != 52000 {
+		t.Errorf("бакеты: %+v", r.Buckets)
+	}
+	if !strings.Contains(strings.Join(f.requests, " "), "/minio/v2/metrics/bucket") {
+		t.Errorf("метрики бакетов не запрошены: %v", f.requests)
+	}
+	if r.PVC == nil || r.PVC.Capacity != 20<<30 || r.PVC.Used != 11<<30 {
+		t.Errorf("PVC: %+v", r.PVC)
+	}
+
+	withRenderMode(t, tgbotapi.ModeHTML)
+	assertGolden(t, "storage.html", renderStorage(LangEN, r, client.cfg.FreeWarnPercent).String())
+}
+
+func TestMinIOReportBucketsInClusterMetrics(t *testing.T) {
+	f, client := newFakeMinIO(t)
+	f.set(200, 200, minioClusterMetrics+minioBucketMetrics)
+	r := client.Report(context.Background(), nil)
+	if len(r.Buckets) != 3 {
+		t.Errorf("бакеты: %+v", r.Buckets)
+	}
+	for _, path := range f.requests {
+		if path == "/minio/v2/metrics/bucket" {
+			t.Error("метрики бакетов уже были в кластерных, отдельный запрос не нужен")
+		}
+	}
+}
+
+func TestMinIOHealth(t *testing.T) {
+	f, client := newFakeMinIO(t)
+	ctx := context.Background()
+	cases := []struct {
+		live, cluster     int
+		wantLive, wantCls bool
+		wantErr           string
+	}{
+		{200, 200, true, true, ""},
+		{200, 503, true, false, "нет кворума"},
+		{503, 200, false, false, "/minio/health/live: 503"},
+	}
+	for _, tc := range cases {
+		f.set(tc.live, tc.cluster, minioClusterMetrics)
+		live, cls, err := client.Health(ctx)
+		if live != tc.wantLive || cls != tc.wantCls {
+			t.Errorf("%d/%d: live=%v cluster=%v", tc.live, tc.cluster, live, cls)
+		}
+		if (tc.wantErr == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), tc.wantErr)) {
+			t.Errorf("%d/%d: ошибка %v", tc.live, tc.cluster, err)
+		}
+	}
+
+	// Без токена метрики недоступны, ошибка попадает в отчёт
+	client.cfg.Token = ""
+	f.set(200, 200, minioClusterMetrics)
+	if r := client.Report(ctx, nil); !strings.Contains(r.MetricsErr, "403") {
+		t.Errorf("ошибка метрик без токена: %q", r.MetricsErr)
+	}
+}
+
+func TestMinIOWatcherAlerts(t *testing.T) {
+	f, client := newFakeMinIO(t)
+	cluster := newFakeCluster(t, nil, []runtime.Object{minioPVCObject("12Gi")})
+	bot, ft := newFakeTelegram(t, nil)
+	w := NewMinIOWatcher(client, cluster, bot, 42)
+	ctx := context.Background()
+	step := func() []string {
+		before := len(sentSince(ft, 0))
+		w.check(client.Report(ctx, cluster))
+		return sentSince(ft, before)
+	}
+
+	// /data1 свободен на 5%, PVC занят бакетами на 11 из 12 GiB — оба ниже порога 10%
+	sent := step()
+	if len(sent) != 2 || !strings.Contains(sent[0], "minio-0:9000:/data1") || !strings.Contains(sent[1], "minio-system/minio-pvc") {
+		t.Fatalf("алерты о месте: %q", sent)
+	}
+	if sent := step(); len(sent) != 0 {
+		t.Fatalf("повторные алерты: %q", sent)
+	}
+
+	// Один сбой — ещё не алерт, второй подряд — алерт, о дисках без метрик не сообщаем
+	f.set(503, 503, minioClusterMetrics)
+	if sent := step(); len(sent) != 0 {
+		t.Fatalf("после одного сбоя: %q", sent)
+	}
+	if sent := step(); len(sent) != 1 || !strings.Contains(sent[0], "503") {
+		t.Fatalf("после двух сбоев: %q", sent)
+	}
+	if sent := step(); len(sent) != 0 {
+		t.Fatalf("повтор алерта о недоступности: %q", sent)
+	}
+
+	// Восстановление и освободившееся место
+	f.set(200, 200, strings.Replace(minioClusterMetrics, `free_bytes{disk="/data1",server="minio-0:9000"} 5.36870912e+09`, `free_bytes{disk="/data1",server="minio-0:9000"} 5.36870912e+10`, 1))
+	sent = step()
+	if len(sent) != 2 || !strings.Contains(sent[1], "minio-0:9000:/data1") {
+		t.Fatalf("восстановление: %q", sent)
+	}
+	if w.down || w.failures != 0 || w.diskLow["minio-0:9000:/data1"] || !w.pvcLow {
+		t.Errorf("состояние: down=%v failures=%d diskLow=%v pvcLow=%v", w.down, w.failures, w.diskLow, w.pvcLow)
+	}
+}
+
+func TestParseMetricsText(t *testing.T) {
+	samples, err := parseMetricsText(strings.NewReader("# comment\nup 1\nx{a=\"b\\\"c\",d=\"e\\nf\"} 2.5 1700000000\n\ny{} -3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 3 || samples[0].Value != 1 || samples[1].Labels["a"] != `b"c` || samples[1].Labels["d"] != "e\nf" || samples[2].Value != -3 {
+		t.Errorf("разбор: %+v", samples)
+	}
+	for _, bad := range []string{"up", "up x", `x{a="b} 1`, `x{a=b} 1`} {
+		if _, err := parseMetricsText(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q разобрано без ошибки", bad)
+		}
+	}
+}
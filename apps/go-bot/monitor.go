@@ -2,8 +2,8 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -12,6 +12,9 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+// nodeAlertThreshold время недоступности узла до отправки уведомления
+const nodeAlertThreshold = 10 * time.Minute
+
 // NodeStatus представляет статус узла
 type NodeStatus struct {
 	Name     string
@@ -20,18 +23,62 @@ type NodeStatus struct {
 	Notified bool
 }
 
+// nodeSilences узлы, по которым монитор временно не поднимает тревогу
+type nodeSilences struct {
+	mu    sync.Mutex
+	nodes map[string]time.Time // cluster/node → до какого момента подавлять
+}
+
+func newNodeSilences() *nodeSilences {
+	return &nodeSilences{nodes: make(map[string]time.Time)}
+}
+
+// Hold подавляет алерты по узлу до until
+func (r *nodeSilences) Hold(cluster, node string, until time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := cluster + "/" + node
+	if until.After(r.nodes[key]) {
+		r.nodes[key] = until
+	}
+}
+
+// Release снимает подавление досрочно
+func (r *nodeSilences) Release(cluster, node string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.nodes, cluster+"/"+node)
+}
+
+// Active сообщает, подавлены ли алерты по узлу
+func (r *nodeSilences) Active(cluster, node string, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := cluster + "/" + node
+	until, ok := r.nodes[key]
+	if ok && !now.Before(until) {
+		delete(r.nodes, key)
+		return false
+	}
+	return ok
+}
+
 // Monitor сервис для мониторинга узлов
 type Monitor struct {
-	clientset *kubernetes.Clientset
+	cluster   string
+	clientset kubernetes.Interface
 	bot       *tgbotapi.BotAPI
 	adminID   int64
-	nodes     map[string]*NodeStatus
+
+	mu    sync.Mutex
+	nodes map[string]*NodeStatus
 }
 
-// NewMonitor создает новый монитор
-func NewMonitor(clientset *kubernetes.Clientset, bot *tgbotapi.BotAPI, adminID int64) *Monitor {
+// NewMonitor создает новый монитор кластера
+func NewMonitor(cluster *Cluster, bot *tgbotapi.BotAPI, adminID int64) *Monitor {
 	return &Monitor{
-		clientset: clientset,
+		cluster:   cluster.Name,
+		clientset: cluster.Typed,
 		bot:       bot,
 		adminID:   adminID,
 		nodes:     make(map[string]*NodeStatus),
@@ -43,12 +90,12 @@ func (m *Monitor) Start(ctx context.Context) {
 	ticker := time.NewTicker(1 * time.Minute) // Проверка каждую минуту
 	defer ticker.Stop()
 
-	log.Println("🚀 Запуск мониторинга узлов...")
+	log.Printf("🚀 [%s] Запуск мониторинга узлов...", m.cluster)
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("🛑 Остановка мониторинга...")
+			log.Printf("🛑 [%s] Остановка мониторинга...", m.cluster)
 			return
 		case <-ticker.C:
 			m.checkNodes(ctx)
@@ -60,12 +107,16 @@ func (m *Monitor) Start(ctx context.Context) {
 func (m *Monitor) checkNodes(ctx context.Context) {
 	nodes, err := m.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		log.Printf("❌ Ошибка получения узлов для мониторинга: %v", err)
+		log.Printf("❌ [%s] Ошибка получения узлов для мониторинга: %v", m.cluster, err)
 		return
 	}
 
 	now := time.Now()
 	currentNodes := make(map[string]bool)
+	telemetry.MonitorLastSuccess.Set(float64(now.Unix()), m.cluster)
+
+	var alerts []nodeAlert
+	m.mu.Lock()
 
 	// Проверяем текущие узлы
 	for _, node := range nodes.Items {
@@ -87,15 +138,23 @@ func (m *Monitor) checkNodes(ctx context.Context) {
 				status.Status = "Ready"
 				status.LastSeen = now
 				if status.Notified {
-					m.sendRecoveryNotification(nodeName)
+					alerts = append(alerts, m.recoveryAlert(nodeName))
 					status.Notified = false
 				}
+			} else if maintenanceNodes.Active(m.cluster, nodeName, now) {
+				// Узел выведен на обслуживание через /drain
+				status.Status = "Maintenance"
+				status.LastSeen = now
+			} else if upgradingNodes.Active(m.cluster, nodeName, now) {
+				// Узел перезапускается system-upgrade-controller-ом
+				status.Status = "Upgrading"
+				status.LastSeen = now
 			} else {
 				// Узел не готов
 				status.Status = "NotReady"
 				duration := now.Sub(status.LastSeen)
-				if duration >= 10*time.Minute && !status.Notified {
-					m.sendAlertNotification(nodeName, duration)
+				if duration >= nodeAlertThreshold && !status.Notified {
+					alerts = append(alerts, m.downAlert(nodeName, duration))
 					status.Notified = true
 				}
 			}
@@ -116,67 +175,125 @@ func (m *Monitor) checkNodes(ctx context.Context) {
 	// Проверяем отсутствующие узлы
 	for nodeName, status := range m.nodes {
 		if !currentNodes[nodeName] {
+			if maintenanceNodes.Active(m.cluster, nodeName, now) || upgradingNodes.Active(m.cluster, nodeName, now) {
+				status.LastSeen = now
+				continue
+			}
 			duration := now.Sub(status.LastSeen)
-			if duration >= 10*time.Minute && !status.Notified {
-				m.sendNodeMissingNotification(nodeName, duration)
+			if duration >= nodeAlertThreshold && !status.Notified {
+				alerts = append(alerts, m.missingAlert(nodeName, duration))
 				status.Notified = true
 			}
 		}
 	}
+	m.updateActiveAlerts()
+	m.mu.Unlock()
+
+	// Отправка идёт без блокировки: /monitor и /alerts не ждут ответа Telegram
+	for _, a := range alerts {
+		m.notify(a)
+	}
+}
+
+// updateActiveAlerts обновляет метрику активных алертов; вызывается под m.mu
+func (m *Monitor) updateActiveAlerts() {
+	active := 0
+	for _, status := range m.nodes {
+		if status.Notified {
+			active++
+		}
+	}
+	telemetry.ActiveAlerts.Set(float64(active), m.cluster)
+}
+
+// nodeAlert уведомление, собранное под m.mu и отправляемое после снятия блокировки
+type nodeAlert struct {
+	kind  string
+	node  string
+	about string // для журнала: "о проблеме с узлом"
+	msg   *Rich
+}
+
+// notify отправляет уведомление администратору и учитывает результат в метриках
+func (m *Monitor) notify(a nodeAlert) {
+	result := "sent"
+	if err := sendRich(m.bot, m.adminID, a.msg); err != nil {
+		result = "failed"
+	}
+	telemetry.Notifications.Inc(a.kind, result)
+	log.Printf("🔔 [%s] Отправлено уведомление %s: %s", m.cluster, a.about, a.node)
 }
 
-// sendAlertNotification отправляет уведомление о проблеме с узлом
-func (m *Monitor) sendAlertNotification(nodeName string, duration time.Duration) {
-	message := fmt.Sprintf("🚨 *ALERT: Node Down*\n\n"+
-		"🔧 *Node:* `%s`\n"+
-		"⏰ *Downtime:* %s\n"+
-		"📊 *Status:* Not Ready\n\n"+
-		"⚠️ Узел недоступен более 10 минут!",
-		nodeName, formatDurationForAlert(duration))
+// downAlert уведомление о проблеме с узлом
+func (m *Monitor) downAlert(nodeName string, duration time.Duration) nodeAlert {
+	return nodeAlert{"node_down", nodeName, "о проблеме с узлом", renderNodeDownAlert(langFor(m.adminID), m.cluster, nodeName, duration)}
+}
 
-	sendText(m.bot, m.adminID, message)
-	log.Printf("🔔 Отправлено уведомление о проблеме с узлом: %s", nodeName)
+// recoveryAlert уведомление о восстановлении узла
+func (m *Monitor) recoveryAlert(nodeName string) nodeAlert {
+	return nodeAlert{"recovery", nodeName, "о восстановлении узла", renderNodeRecovery(langFor(m.adminID), m.cluster, nodeName)}
 }
 
-// sendRecoveryNotification отправляет уведомление о восстановлении узла
-func (m *Monitor) sendRecoveryNotification(nodeName string) {
-	message := fmt.Sprintf("✅ *RECOVERY: Node Back Online*\n\n"+
-		"🔧 *Node:* `%s`\n"+
-		"📊 *Status:* Ready\n\n"+
-		"🎉 Узел восстановил работу!",
-		nodeName)
+// missingAlert уведомление об отсутствующем узле
+func (m *Monitor) missingAlert(nodeName string, duration time.Duration) nodeAlert {
+	return nodeAlert{"node_missing", nodeName, "об отсутствующем узле", renderNodeMissingAlert(langFor(m.adminID), m.cluster, nodeName, duration)}
+}
 
-	sendText(m.bot, m.adminID, message)
-	log.Printf("🔔 Отправлено уведомление о восстановлении узла: %s", nodeName)
+// renderNodeDownAlert формирует уведомление о неготовом узле
+func renderNodeDownAlert(lang Lang, cluster, nodeName string, duration time.Duration) *Rich {
+	return NewRich().
+		Text("🚨 " + alertPrefix(cluster)).Bold(T(lang, "alert.node_down.title")).Line().Line().
+		Text("🔧 ").Bold(T(lang, "alert.node")).Text(" ").Code(nodeName).Line().
+		Text("⏰ ").Bold(T(lang, "alert.downtime")).Text(" " + formatDurationForAlert(lang, duration)).Line().
+		Text("📊 ").Bold(T(lang, "alert.status")).Text(" Not Ready").Line().Line().
+		Text(T(lang, "alert.node_down.text", formatDurationForAlert(lang, nodeAlertThreshold)))
 }
 
-// sendNodeMissingNotification отправляет уведомление об отсутствующем узле
-func (m *Monitor) sendNodeMissingNotification(nodeName string, duration time.Duration) {
-	message := fmt.Sprintf("❌ *CRITICAL: Node Missing*\n\n"+
-		"🔧 *Node:* `%s`\n"+
-		"⏰ *Missing for:* %s\n\n"+
-		"🚨 Узел отсутствует в кластере более 10 минут!",
-		nodeName, formatDurationForAlert(duration))
+// renderNodeRecovery формирует уведомление о восстановлении узла
+func renderNodeRecovery(lang Lang, cluster, nodeName string) *Rich {
+	return NewRich().
+		Text("✅ " + alertPrefix(cluster)).Bold(T(lang, "alert.recovery.title")).Line().Line().
+		Text("🔧 ").Bold(T(lang, "alert.node")).Text(" ").Code(nodeName).Line().
+		Text("📊 ").Bold(T(lang, "alert.status")).Text(" Ready").Line().Line().
+		Text(T(lang, "alert.recovery.text"))
+}
+
+// renderNodeMissingAlert формирует уведомление об отсутствующем узле
+func renderNodeMissingAlert(lang Lang, cluster, nodeName string, duration time.Duration) *Rich {
+	return NewRich().
+		Text("❌ " + alertPrefix(cluster)).Bold(T(lang, "alert.missing.title")).Line().Line().
+		Text("🔧 ").Bold(T(lang, "alert.node")).Text(" ").Code(nodeName).Line().
+		Text("⏰ ").Bold(T(lang, "alert.missing_for")).Text(" " + formatDurationForAlert(lang, duration)).Line().Line().
+		Text(T(lang, "alert.node_missing.text", formatDurationForAlert(lang, nodeAlertThreshold)))
+}
 
-	sendText(m.bot, m.adminID, message)
-	log.Printf("🔔 Отправлено уведомление об отсутствующем узле: %s", nodeName)
+// alertPrefix метка кластера в начале уведомления
+func alertPrefix(cluster string) string {
+	return "[" + cluster + "] "
 }
 
-// formatDurationForAlert форматирует время для уведомлений
-func formatDurationForAlert(d time.Duration) string {
+// formatDurationForAlert форматирует время для уведомлений: "1 час 5 минут", "2 hours"
+func formatDurationForAlert(lang Lang, d time.Duration) string {
 	minutes := int(d.Minutes())
 	if minutes < 60 {
-		return fmt.Sprintf("%d minutes", minutes)
+		return Plural(lang, minutes, "minute")
 	}
 	hours := minutes / 60
 	remainingMinutes := minutes % 60
 	if remainingMinutes > 0 {
-		return fmt.Sprintf("%d hours %d minutes", hours, remainingMinutes)
+		return Plural(lang, hours, "hour") + " " + Plural(lang, remainingMinutes, "minute")
 	}
-	return fmt.Sprintf("%d hours", hours)
+	return Plural(lang, hours, "hour")
 }
 
-// GetNodeStatuses возвращает текущие статусы узлов
+// GetNodeStatuses возвращает копию текущих статусов узлов
 func (m *Monitor) GetNodeStatuses() map[string]*NodeStatus {
-	return m.nodes
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	statuses := make(map[string]*NodeStatus, len(m.nodes))
+	for name, status := range m.nodes {
+		copied := *status
+		statuses[name] = &copied
+	}
+	return statuses
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestCheckNodesSendsWithoutLock(t *testing.T) {
+	sending := make(chan struct{})
+	release := make(chan struct{})
+	bot, ft := newFakeTelegram(t, func(call int) (int, string) {
+		close(sending)
+		<-release
+		return 200, ""
+	})
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionFalse},
+		}},
+	}
+	m := NewMonitor(newFakeCluster(t, nil, []runtime.Object{node}), bot, 42)
+	m.nodes["worker-1"] = &NodeStatus{Name: "worker-1", Status: "NotReady", LastSeen: time.Now().Add(-time.Hour)}
+
+	done := make(chan struct{})
+	go func() {
+		m.checkNodes(context.Background())
+		close(done)
+	}()
+	<-sending
+
+	// Пока Telegram не ответил, статусы должны читаться без ожидания
+	got := make(chan map[string]*NodeStatus)
+	go func() { got <- m.GetNodeStatuses() }()
+	select {
+	case statuses := <-got:
+		if !statuses["worker-1"].Notified {
+			t.Error("узел должен быть отмечен как оповещённый до отправки")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("GetNodeStatuses ждёт отправки уведомления")
+	}
+	close(release)
+	<-done
+
+	if len(ft.texts) != 1 {
+		t.Fatalf("отправлено %d сообщений, ожидалось 1", len(ft.texts))
+	}
+}
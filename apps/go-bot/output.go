@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// outputConfig настройки отправки длинного вывода, задаются в main
+var outputConfig = DefaultOutputConfig()
+
+// sendLong отправляет вывод команды моноширинным блоком: одним или несколькими сообщениями,
+// а если он длиннее FileThreshold — файлом "<name>-<ts>.txt" из памяти
+func sendLong(bot *tgbotapi.BotAPI, chatID int64, name, txt string) {
+	if len(txt) <= outputConfig.FileThreshold {
+		for _, chunk := range splitMessage(txt, outputConfig.MaxMessageLen) {
+			sendRich(bot, chatID, NewRich().Pre(chunk))
+		}
+		return
+	}
+	sendFile(bot, chatID, outputFileName(name), []byte(txt))
+}
+
+// sendLongRich отправляет размеченный отчёт частями по границам строк,
+// а слишком длинный — файлом без разметки
+func sendLongRich(bot *tgbotapi.BotAPI, chatID int64, name string, r *Rich) {
+	plain := r.Plain()
+	if len(plain) > outputConfig.FileThreshold {
+		sendFile(bot, chatID, outputFileName(name), []byte(plain))
+		return
+	}
+	for _, chunk := range r.Chunks(outputConfig.MaxMessageLen) {
+		sendRich(bot, chatID, chunk)
+	}
+}
+
+// sendFile загружает содержимое как документ, при необходимости сжимая его
+func sendFile(bot *tgbotapi.BotAPI, chatID int64, fileName string, data []byte) {
+	caption := T(langFor(chatID), "output.file", formatMemory(int64(len(data))))
+	if len(data) > outputConfig.GzipThreshold {
+		compressed, err := gzipBytes(data)
+		if err != nil {
+			log.Printf("⚠️ Ошибка сжатия %s: %v", fileName, err)
+		} else {
+			data = compressed
+			fileName += ".gz"
+			caption += ", gzip"
+		}
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: fileName, Bytes: data})
+	doc.Caption = caption
+	if _, err := bot.Send(doc); err != nil {
+		log.Printf("❌ Ошибка отправки файла %s в %d: %v", fileName, chatID, err)
+	}
+}
+
+// splitMessage делит текст на части не длиннее limit байт по границам строк
+func splitMessage(txt string, limit int) []string {
+	txt = strings.TrimRight(txt, "\n")
+	if len(txt) <= limit {
+		return []string{txt}
+	}
+
+	var chunks []string
+	var cur strings.Builder
+	flush := func() {
+		if cur.Len() > 0 {
+			chunks = append(chunks, strings.TrimRight(cur.String(), "\n"))
+			cur.Reset()
+		}
+	}
+
+	for _, line := range strings.SplitAfter(txt, "\n") {
+		if cur.Len()+len(line) > limit {
+			flush()
+		}
+		// Строка длиннее лимита режется по границе символа
+		for len(line) > limit {
+			cut := limit
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			chunks = append(chunks, line[:cut])
+			line = line[cut:]
+		}
+		cur.WriteString(line)
+	}
+	flush()
+	return chunks
+}
+
+// outputFileName формирует имя файла вида logs-ns-pod-20060102-150405.txt
+func outputFileName(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('-')
+		}
+	}
+	if sb.Len() == 0 {
+		sb.WriteString("output")
+	}
+	return fmt.Sprintf("%s-%s.txt", sb.String(), time.Now().Format("20060102-150405"))
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	podsPageSize = 20
+	// podListingTTL сколько живут сохранённые запросы для кнопок навигации
+	podListingTTL = time.Hour
+)
+
+// podFilter условия отбора pod-ов для /getpods
+type podFilter struct {
+	Namespace   string // пусто — все namespace
+	Phase       string
+	Node        string
+	Selector    string
+	NotReady    bool
+	MinRestarts int // показывать pod-ы с рестартами больше N; -1 — без фильтра
+}
+
+// podRow строка таблицы pod-ов в стиле kubectl get pods -o wide
+type podRow struct {
+	Namespace string
+	Name      string
+	Ready     string
+	Status    string
+	Restarts  int
+	Age       time.Duration
+	IP        string
+	Node      string
+	ready     bool
+}
+
+// podListing сохранённый запрос, к которому обращаются кнопки Prev/Next
+type podListing struct {
+	Cluster string
+	Filter  podFilter
+	Created time.Time
+}
+
+// podListings хранилище запросов /getpods по коротким идентификаторам
+var podListings = struct {
+	sync.Mutex
+	items map[string]podListing
+}{items: make(map[string]podListing)}
+
+// parsePodFilter разбирает аргументы /getpods:
+// [ns|all] [--phase P] [--node N] [-l selector] [--not-ready] [--restarts>N]
+func parsePodFilter(args string) (podFilter, error) {
+	f := podFilter{MinRestarts: -1}
+	parts := strings.Fields(args)
+	value := func(i *int, name string) (string, error) {
+		if *i+1 >= len(parts) {
+			return "", fmt.Errorf("не указано значение %s", name)
+		}
+		*i++
+		return parts[*i], nil
+	}
+
+	for i := 0; i < len(parts); i++ {
+		p := parts[i]
+		var err error
+		switch {
+		case p == "--phase":
+			f.Phase, err = value(&i, p)
+		case strings.HasPrefix(p, "--phase="):
+			f.Phase = strings.TrimPrefix(p, "--phase=")
+		case p == "--node":
+			f.Node, err = value(&i, p)
+		case strings.HasPrefix(p, "--node="):
+			f.Node = strings.TrimPrefix(p, "--node=")
+		case p == "-l" || p == "--selector":
+			f.Selector, err = value(&i, p)
+		case strings.HasPrefix(p, "--selector="):
+			f.Selector = strings.TrimPrefix(p, "--selector=")
+		case p == "--not-ready":
+			f.NotReady = true
+		case strings.HasPrefix(p, "--restarts"):
+			v := strings.TrimLeft(strings.TrimPrefix(p, "--restarts"), ">=")
+			if v == "" {
+				v, err = value(&i, p)
+			}
+			if err == nil {
+				f.MinRestarts, err = strconv.Atoi(v)
+			}
+		case strings.HasPrefix(p, "-"):
+			err = fmt.Errorf("неизвестный флаг: %s", p)
+		default:
+			if f.Namespace != "" {
+				err = fmt.Errorf("лишний аргумент: %s", p)
+			} else if p != "all" {
+				f.Namespace = p
+			}
+		}
+		if err != nil {
+			return f, err
+		}
+	}
+	if f.Phase != "" {
+		// Running, Pending... — как в status.phase
+		f.Phase = strings.ToUpper(f.Phase[:1]) + strings.ToLower(f.Phase[1:])
+	}
+	return f, nil
+}
+
+// String описывает активные фильтры для заголовка
+func (f podFilter) String() string {
+	var parts []string
+	if f.Phase != "" {
+		parts = append(parts, "--phase "+f.Phase)
+	}
+	if f.Node != "" {
+		parts = append(parts, "--node "+f.Node)
+	}
+	if f.Selector != "" {
+		parts = append(parts, "-l "+f.Selector)
+	}
+	if f.NotReady {
+		parts = append(parts, "--not-ready")
+	}
+	if f.MinRestarts >= 0 {
+		parts = append(parts, fmt.Sprintf("--restarts>%d", f.MinRestarts))
+	}
+	return strings.Join(parts, " ")
+}
+
+// handleGetPods выводит первую страницу pod-ов и сохраняет запрос для навигации
+func handleGetPods(bot *tgbotapi.BotAPI, cluster *Cluster, ctx context.Context, chatID int64, f podFilter) {
+	rows, err := listPodRows(ctx, cluster.Typed, f, time.Now())
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	id := savePodListing(cluster.Name, f)
+	r, markup := renderPodPage(langFor(chatID), f, rows, id, 0)
+	if markup != nil {
+		sendRichMarkup(bot, chatID, r, *markup)
+		return
+	}
+	sendRich(bot, chatID, r)
+}
+
+// handlePodPage перелистывает список pod-ов, редактируя исходное сообщение
+// Страницы берутся из кластера исходного запроса, даже если чат переключился на другой
+func handlePodPage(bot *tgbotapi.BotAPI, ctx context.Context, chatID int64, messageID int, args string) {
+	parts := strings.Fields(args)
+	if len(parts) != 2 {
+		return
+	}
+	page, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return
+	}
+	listing, ok := loadPodListing(parts[0])
+	cluster, found := clusters.Get(listing.Cluster)
+	if !ok || !found {
+		sendText(bot, chatID, T(langFor(chatID), "pods.expired"))
+		return
+	}
+	rows, err := listPodRows(ctx, cluster.Typed, listing.Filter, time.Now())
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	r, markup := renderPodPage(langFor(chatID), listing.Filter, rows, parts[0], page)
+	editRich(bot, chatID, messageID, r, markup)
+}
+
+// listPodRows получает pod-ы с учётом фильтров; phase и node отбираются на стороне API
+func listPodRows(ctx context.Context, clientset kubernetes.Interface, f podFilter, now time.Time) ([]podRow, error) {
+	var fieldSel []fields.Selector
+	if f.Phase != "" {
+		fieldSel = append(fieldSel, fields.OneTermEqualSelector("status.phase", f.Phase))
+	}
+	if f.Node != "" {
+		fieldSel = append(fieldSel, fields.OneTermEqualSelector("spec.nodeName", f.Node))
+	}
+	opts := metav1.ListOptions{LabelSelector: f.Selector}
+	if len(fieldSel) > 0 {
+		opts.FieldSelector = fields.AndSelectors(fieldSel...).String()
+	}
+
+	pods, err := clientset.CoreV1().Pods(f.Namespace).List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []podRow
+	for _, p := range pods.Items {
+		row := newPodRow(p, now)
+		if f.NotReady && row.ready {
+			continue
+		}
+		if f.MinRestarts >= 0 && row.Restarts <= f.MinRestarts {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Namespace != rows[j].Namespace {
+			return rows[i].Namespace < rows[j].Namespace
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return rows, nil
+}
+
+func newPodRow(p corev1.Pod, now time.Time) podRow {
+	readyCount, restarts := 0, 0
+	for _, cs := range p.Status.ContainerStatuses {
+		if cs.Ready {
+			readyCount++
+		}
+		restarts += int(cs.RestartCount)
+	}
+	total := len(p.Spec.Containers)
+	return podRow{
+		Namespace: p.Namespace,
+		Name:      p.Name,
+		Ready:     fmt.Sprintf("%d/%d", readyCount, total),
+		Status:    podDisplayStatus(p),
+		Restarts:  restarts,
+		Age:       now.Sub(p.CreationTimestamp.Time),
+		IP:        p.Status.PodIP,
+		Node:      p.Spec.NodeName,
+		ready:     p.Status.Phase == corev1.PodSucceeded || (p.Status.Phase == corev1.PodRunning && readyCount == total),
+	}
+}
+
+// podDisplayStatus повторяет колонку STATUS kubectl: причина ожидания или завершения контейнера важнее фазы
+func podDisplayStatus(p corev1.Pod) string {
+	if p.DeletionTimestamp != nil {
+		return "Terminating"
+	}
+	status := string(p.Status.Phase)
+	if p.Status.Reason != "" {
+		status = p.Status.Reason
+	}
+	for _, cs := range p.Status.InitContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" && cs.State.Waiting.Reason != "PodInitializing" {
+			return "Init:" + cs.State.Waiting.Reason
+		}
+		if cs.State.Terminated != nil && cs.State.Terminated.ExitCode != 0 {
+			return "Init:" + cs.State.Terminated.Reason
+		}
+	}
+	for _, cs := range p.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+			return cs.State.Waiting.Reason
+		}
+		if cs.State.Terminated != nil && cs.State.Terminated.Reason != "" {
+			status = cs.State.Terminated.Reason
+		}
+	}
+	return status
+}
+
+// renderPodPage формирует страницу таблицы и кнопки навигации
+func renderPodPage(lang Lang, f podFilter, rows []podRow, listingID string, page int) (*Rich, *tgbotapi.InlineKeyboardMarkup) {
+	scope := f.Namespace
+	if scope == "" {
+		scope = "all"
+	}
+	r := NewRich()
+	if len(rows) == 0 {
+		r.Text(T(lang, "pods.header", scope, 0, 1, 1)).Line()
+		if filters := f.String(); filters != "" {
+			r.Text(T(lang, "pods.filters", filters)).Line()
+		}
+		r.Text(T(lang, "pods.none"))
+		return r, nil
+	}
+
+	pages := (len(rows) + podsPageSize - 1) / podsPageSize
+	if page < 0 {
+		page = 0
+	}
+	if page >= pages {
+		page = pages - 1
+	}
+	start := page * podsPageSize
+	end := start + podsPageSize
+	if end > len(rows) {
+		end = len(rows)
+	}
+
+	r.Text(T(lang, "pods.header", scope, len(rows), page+1, pages)).Line()
+	if filters := f.String(); filters != "" {
+		r.Text(T(lang, "pods.filters", filters)).Line()
+	}
+	r.Pre(formatPodTable(lang, rows[start:end], f.Namespace == ""))
+
+	if pages == 1 {
+		return r, nil
+	}
+	var buttons []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(T(lang, "btn.prev"), fmt.Sprintf("podpage %s %d", listingID, page-1)))
+	}
+	if page < pages-1 {
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(T(lang, "btn.next"), fmt.Sprintf("podpage %s %d", listingID, page+1)))
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(buttons)
+	return r, &markup
+}
+
+// formatPodTable выравнивает строки pod-ов в колонки
+func formatPodTable(lang Lang, rows []podRow, withNamespace bool) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
+	if withNamespace {
+		fmt.Fprint(w, "NAMESPACE\t")
+	}
+	fmt.Fprintln(w, "NAME\tREADY\tSTATUS\tRESTARTS\tAGE\tIP\tNODE")
+	for _, row := range rows {
+		if withNamespace {
+			fmt.Fprintf(w, "%s\t", row.Namespace)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			row.Name, row.Ready, row.Status, row.Restarts, formatDuration(lang, row.Age), orDash(row.IP), orDash(row.Node))
+	}
+	w.Flush()
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// savePodListing сохраняет запрос и возвращает его идентификатор для callback_data
+func savePodListing(cluster string, f podFilter) string {
+	id := shortID()
+
+	podListings.Lock()
+	defer podListings.Unlock()
+	now := time.Now()
+	for k, v := range podListings.items {
+		if now.Sub(v.Created) > podListingTTL {
+			delete(podListings.items, k)
+		}
+	}
+	podListings.items[id] = podListing{Cluster: cluster, Filter: f, Created: now}
+	return id
+}
+
+func loadPodListing(id string) (podListing, bool) {
+	podListings.Lock()
+	defer podListings.Unlock()
+	l, ok := podListings.items[id]
+	if !ok || time.Since(l.Created) > podListingTTL {
+		return podListing{}, false
+	}
+	return l, true
+}
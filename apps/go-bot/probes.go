@@ -0,0 +1,592 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Аннотации Ingress-ов и сервисов, которыми настраиваются проверки
+const (
+	probeAnnotation       = "go-bot/probe"        // "false" у Ingress — не проверять, "true" у сервиса — проверять
+	probePathAnnotation   = "go-bot/probe-path"   // путь вместо путей из правил Ingress или "/"
+	probeStatusAnnotation = "go-bot/probe-status" // допустимые коды через запятую, например "200,401"
+	probeBodyAnnotation   = "go-bot/probe-body"   // подстрока, которая должна быть в ответе
+	probePortAnnotation   = "go-bot/probe-port"   // порт сервиса, если их несколько
+)
+
+// probeBodyLimit сколько байт ответа читать для проверки содержимого
+const probeBodyLimit = 64 << 10
+
+// probeTarget адрес, который проверяет бот
+type probeTarget struct {
+	Name     string
+	URL      string
+	Cluster  string        // пусто у целей из PROBE_TARGETS
+	Source   string        // откуда цель: Ingress ns/имя, Service ns/имя или config
+	Status   []int         // допустимые коды, пусто — любой ответ до 400
+	Body     string        // подстрока ответа
+	Latency  time.Duration // порог задержки, 0 — из настроек
+	Insecure bool          // не проверять сертификат: сервисы внутри кластера
+}
+
+// key различает цели с одним адресом, но разными ожиданиями
+func (t probeTarget) key() string {
+	return fmt.Sprintf("%s|%s|%s|%v|%s", t.Cluster, t.Name, t.URL, t.Status, t.Body)
+}
+
+// parseProbeTarget разбирает цель из PROBE_TARGETS: поля key=value через пробел
+func parseProbeTarget(entry string) (probeTarget, error) {
+	t := probeTarget{Source: "config"}
+	for _, field := range strings.Fields(entry) {
+		k, v, ok := strings.Cut(field, "=")
+		if !ok {
+			return t, fmt.Errorf("ожидается key=value: %s", field)
+		}
+		switch k {
+		case "name":
+			t.Name = v
+		case "url":
+			t.URL = v
+		case "status":
+			codes, err := parseProbeStatus(v)
+			if err != nil {
+				return t, err
+			}
+			t.Status = codes
+		case "body":
+			t.Body = v
+		case "latency":
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return t, fmt.Errorf("некорректная задержка: %s", v)
+			}
+			t.Latency = d
+		case "insecure":
+			t.Insecure = v == "true"
+		default:
+			return t, fmt.Errorf("неизвестный параметр %s", k)
+		}
+	}
+	u, err := url.Parse(t.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return t, fmt.Errorf("нужен url=http(s)://...")
+	}
+	if t.Name == "" {
+		t.Name = u.Host + u.Path
+	}
+	return t, nil
+}
+
+// parseProbeStatus разбирает список кодов "200,401"
+func parseProbeStatus(v string) ([]int, error) {
+	var codes []int
+	for _, s := range strings.Split(v, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("некорректный код ответа: %s", s)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// ingressTargets цели по правилам Ingress-ов: хост и путь, https для хостов из spec.tls
+func ingressTargets(cluster string, ingresses []networkingv1.Ingress) []probeTarget {
+	var targets []probeTarget
+	for _, ing := range ingresses {
+		ann := ing.Annotations
+		if ann[probeAnnotation] == "false" {
+			continue
+		}
+		tlsHosts := make(map[string]bool)
+		for _, t := range ing.Spec.TLS {
+			for _, h := range t.Hosts {
+				tlsHosts[h] = true
+			}
+		}
+		status, _ := parseProbeStatus(ann[probeStatusAnnotation])
+		seen := make(map[string]bool)
+		for _, rule := range ing.Spec.Rules {
+			// Без хоста или с маской адрес не построить
+			if rule.Host == "" || strings.Contains(rule.Host, "*") {
+				continue
+			}
+			scheme := "http"
+			if tlsHosts[rule.Host] {
+				scheme = "https"
+			}
+			var paths []string
+			if p := ann[probePathAnnotation]; p != "" {
+				paths = []string{p}
+			} else if rule.HTTP != nil {
+				for _, p := range rule.HTTP.Paths {
+					// Регулярные выражения ingress-nginx в ImplementationSpecific не превратить в адрес
+					if p.Path == "" || strings.ContainsAny(p.Path, "()*[]$^") {
+						continue
+					}
+					paths = append(paths, p.Path)
+				}
+			}
+			if len(paths) == 0 {
+				paths = []string{"/"}
+			}
+			for _, p := range paths {
+				u := scheme + "://" + rule.Host + p
+				if seen[u] {
+					continue
+				}
+				seen[u] = true
+				targets = append(targets, probeTarget{
+					Name:    rule.Host + p,
+					URL:     u,
+					Cluster: cluster,
+					Source:  "Ingress " + ing.Namespace + "/" + ing.Name,
+					Status:  status,
+					Body:    ann[probeBodyAnnotation],
+				})
+			}
+		}
+	}
+	return targets
+}
+
+// serviceTargets цели по сервисам с аннотацией go-bot/probe: "true"
+func serviceTargets(cluster string, services []corev1.Service) []probeTarget {
+	var targets []probeTarget
+	for _, svc := range services {
+		ann := svc.Annotations
+		if ann[probeAnnotation] != "true" || len(svc.Spec.Ports) == 0 {
+			continue
+		}
+		port := svc.Spec.Ports[0]
+		if want := ann[probePortAnnotation]; want != "" {
+			for _, p := range svc.Spec.Ports {
+				if p.Name == want || strconv.Itoa(int(p.Port)) == want {
+					port = p
+				}
+			}
+		}
+		scheme := "http"
+		if port.Port == 443 || port.Name == "https" {
+			scheme = "https"
+		}
+		path := ann[probePathAnnotation]
+		if path == "" {
+			path = "/"
+		}
+		status, _ := parseProbeStatus(ann[probeStatusAnnotation])
+		targets = append(targets, probeTarget{
+			Name:    fmt.Sprintf("%s/%s:%d", svc.Namespace, svc.Name, port.Port),
+			URL:     fmt.Sprintf("%s://%s.%s.svc:%d%s", scheme, svc.Name, svc.Namespace, port.Port, path),
+			Cluster: cluster,
+			Source:  "Service " + svc.Namespace + "/" + svc.Name,
+			Status:  status,
+			Body:    ann[probeBodyAnnotation],
+			// Сертификат сервиса выписан на внешнее имя, а не на *.svc
+			Insecure: scheme == "https",
+		})
+	}
+	return targets
+}
+
+// probeResult итог одной проверки
+type probeResult struct {
+	OK         bool
+	Status     int
+	Latency    time.Duration
+	Reason     string // текст ошибки запроса
+	ReasonKey  string // причина из каталога, если запрос прошёл, но проверка — нет
+	ReasonArgs []any
+	CertExpiry time.Time
+	Checked    time.Time
+}
+
+// runProbe выполняет запрос и сверяет код, содержимое, задержку и сертификат
+func runProbe(ctx context.Context, client *http.Client, t probeTarget, cfg ProbeConfig, now time.Time) probeResult {
+	res := probeResult{Checked: now}
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.URL, nil)
+	if err != nil {
+		res.Reason = err.Error()
+		return res
+	}
+	req.Header.Set("User-Agent", "go-bot-probe")
+
+	started := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		res.Latency = time.Since(started)
+		res.Reason = probeError(err)
+		return res
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, probeBodyLimit))
+	res.Latency = time.Since(started)
+	res.Status = resp.StatusCode
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		res.CertExpiry = resp.TLS.PeerCertificates[0].NotAfter
+	}
+
+	limit := t.Latency
+	if limit == 0 {
+		limit = cfg.Latency
+	}
+	switch {
+	case err != nil:
+		res.Reason = err.Error()
+	case !probeStatusOK(t.Status, resp.StatusCode):
+		res.Reason = "HTTP " + strconv.Itoa(resp.StatusCode)
+	case t.Body != "" && !strings.Contains(string(body), t.Body):
+		res.ReasonKey, res.ReasonArgs = "probes.reason.body", []any{t.Body}
+	case res.Latency > limit:
+		res.ReasonKey, res.ReasonArgs = "probes.reason.latency", []any{res.Latency.Round(time.Millisecond), limit}
+	case !t.Insecure && !res.CertExpiry.IsZero() && res.CertExpiry.Sub(now) < cfg.TLSMinValidity:
+		res.ReasonKey, res.ReasonArgs = "probes.reason.tls", []any{res.CertExpiry.UTC().Format("2006-01-02 15:04 UTC")}
+	default:
+		res.OK = true
+	}
+	return res
+}
+
+// reason причина неудачи на языке lang
+func (r probeResult) reason(lang Lang) string {
+	if r.ReasonKey != "" {
+		return T(lang, r.ReasonKey, r.ReasonArgs...)
+	}
+	return r.Reason
+}
+
+// probeError короткая причина сетевой ошибки: без повтора метода и адреса
+func probeError(err error) string {
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		err = uerr.Err
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	return err.Error()
+}
+
+func probeStatusOK(allowed []int, code int) bool {
+	if len(allowed) == 0 {
+		return code < 400
+	}
+	for _, c := range allowed {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// probeClient HTTP-клиент проверок: без перехода по редиректам, чтобы 302 на логин
+// считался ответом самого сервиса
+func probeClient(insecure bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+	if insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &http.Client{
+		Transport: instrumentTransport(transport, "probe", ""),
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// probeState состояние цели между проверками
+type probeState struct {
+	Target   probeTarget
+	Last     probeResult
+	Failures int       // неудач подряд
+	Since    time.Time // с какого момента проверка не проходит
+	Alerted  bool
+}
+
+// ProbeWatcher периодически проверяет Ingress-ы, сервисы и адреса из PROBE_TARGETS
+type ProbeWatcher struct {
+	cfg      ProbeConfig
+	bot      *tgbotapi.BotAPI
+	adminID  int64
+	client   *http.Client
+	insecure *http.Client
+
+	running sync.Mutex // проходы не пересекаются: /probes может проверить раньше Start
+	passes  int        // сколько проходов завершено, под running
+
+	mu     sync.Mutex
+	states map[string]*probeState
+}
+
+// NewProbeWatcher создаёт наблюдателя за HTTP-проверками
+func NewProbeWatcher(cfg ProbeConfig, bot *tgbotapi.BotAPI, adminID int64) *ProbeWatcher {
+	return &ProbeWatcher{
+		cfg:      cfg,
+		bot:      bot,
+		adminID:  adminID,
+		client:   probeClient(false),
+		insecure: probeClient(true),
+		states:   make(map[string]*probeState),
+	}
+}
+
+// Start проверяет цели сразу и затем каждые PollInterval
+func (w *ProbeWatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		w.check(ctx, clusters.All())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// targets цели из настроек и найденные в кластерах
+func (w *ProbeWatcher) targets(ctx context.Context, targets []*Cluster) []probeTarget {
+	list := append([]probeTarget{}, w.cfg.Targets...)
+	for _, cluster := range targets {
+		if w.cfg.DiscoverIngress {
+			ingresses, err := cluster.Typed.NetworkingV1().Ingresses("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				log.Printf("❌ [%s] Ошибка получения Ingress-ов: %v", cluster.Name, err)
+			} else {
+				list = append(list, ingressTargets(cluster.Name, ingresses.Items)...)
+			}
+		}
+		if w.cfg.DiscoverServices {
+			services, err := cluster.Typed.CoreV1().Services("").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				log.Printf("❌ [%s] Ошибка получения сервисов: %v", cluster.Name, err)
+			} else {
+				list = append(list, serviceTargets(cluster.Name, services.Items)...)
+			}
+		}
+	}
+	return list
+}
+
+// check проверяет все цели параллельно и сообщает о смене состояния
+func (w *ProbeWatcher) check(ctx context.Context, targets []*Cluster) {
+	w.running.Lock()
+	defer w.running.Unlock()
+	w.checkLocked(ctx, targets)
+}
+
+// checkFirst делает первый проход, если его ещё не было: иначе ожидание проверки
+// и повтор засчитали бы неудачи дважды
+func (w *ProbeWatcher) checkFirst(ctx context.Context, targets []*Cluster) {
+	w.running.Lock()
+	defer w.running.Unlock()
+	if w.passes == 0 {
+		w.checkLocked(ctx, targets)
+	}
+}
+
+// checkLocked один проход; вызывается под running
+func (w *ProbeWatcher) checkLocked(ctx context.Context, targets []*Cluster) {
+	defer func() { w.passes++ }()
+	list := w.targets(ctx, targets)
+	results := make([]probeResult, len(list))
+	sem := make(chan struct{}, max(w.cfg.Parallel, 1))
+	var wg sync.WaitGroup
+	for i, t := range list {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			client := w.client
+			if t.Insecure {
+				client = w.insecure
+			}
+			results[i] = runProbe(ctx, client, t, w.cfg, time.Now())
+		}()
+	}
+	wg.Wait()
+
+	lang := langFor(w.adminID)
+	var alerts []*Rich
+	var kinds []string
+	w.mu.Lock()
+	present := make(map[string]bool, len(list))
+	for i, t := range list {
+		res := results[i]
+		present[t.key()] = true
+		state, ok := w.states[t.key()]
+		if !ok {
+			state = &probeState{}
+			w.states[t.key()] = state
+		}
+		state.Target, state.Last = t, res
+		if res.OK {
+			if state.Alerted {
+				alerts = append(alerts, renderProbeRecovered(lang, *state))
+				kinds = append(kinds, "probe_recovered")
+				log.Printf("✅ %sПроверка %s снова проходит", probePrefix(t.Cluster), t.URL)
+			}
+			state.Failures, state.Since, state.Alerted = 0, time.Time{}, false
+			continue
+		}
+		if state.Failures == 0 {
+			state.Since = res.Checked
+		}
+		state.Failures++
+		if state.Failures >= w.cfg.FailThreshold && !state.Alerted {
+			state.Alerted = true
+			alerts = append(alerts, renderProbeFailed(lang, *state))
+			kinds = append(kinds, "probe_failed")
+			log.Printf("🔔 %sПроверка %s не проходит %d раз подряд: %s", probePrefix(t.Cluster), t.URL, state.Failures, res.reason(LangRU))
+		}
+	}
+	// Удалённые Ingress-ы и сервисы больше не проверяются
+	for key := range w.states {
+		if !present[key] {
+			delete(w.states, key)
+		}
+	}
+	w.mu.Unlock()
+
+	for i, r := range alerts {
+		result := "sent"
+		if err := sendRich(w.bot, w.adminID, r); err != nil {
+			result = "failed"
+		}
+		telemetry.Notifications.Inc(kinds[i], result)
+	}
+}
+
+// Snapshot состояние всех целей: сначала не проходящие, затем по имени
+func (w *ProbeWatcher) Snapshot() []probeState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	list := make([]probeState, 0, len(w.states))
+	for _, s := range w.states {
+		list = append(list, *s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Last.OK != list[j].Last.OK {
+			return !list[i].Last.OK
+		}
+		if list[i].Target.Cluster != list[j].Target.Cluster {
+			return list[i].Target.Cluster < list[j].Target.Cluster
+		}
+		return list[i].Target.Name < list[j].Target.Name
+	})
+	return list
+}
+
+// probePrefix метка кластера в алерте; у целей из настроек кластера нет
+func probePrefix(cluster string) string {
+	if cluster == "" {
+		return ""
+	}
+	return alertPrefix(cluster)
+}
+
+// renderProbeFailed алерт о проверке, не проходящей FailThreshold раз подряд
+func renderProbeFailed(lang Lang, s probeState) *Rich {
+	return NewRich().
+		Text("🚨 "+probePrefix(s.Target.Cluster)).Bold(T(lang, "probes.failed.title")).Line().Line().
+		Text("🌐 ").Bold(T(lang, "probes.url")).Text(" ").Code(s.Target.URL).Line().
+		Text("📋 ").Bold(T(lang, "probes.source")).Text(" "+s.Target.Source).Line().
+		Text("🔁 ").Bold(T(lang, "probes.failures")).Textf(" %d", s.Failures).Line().
+		Text(T(lang, "alert.reason", s.Last.reason(lang)))
+}
+
+// renderProbeRecovered уведомление о восстановлении проверки
+func renderProbeRecovered(lang Lang, s probeState) *Rich {
+	r := NewRich().
+		Text("✅ " + probePrefix(s.Target.Cluster)).Bold(T(lang, "probes.recovered.title")).Line().Line().
+		Text("🌐 ").Bold(T(lang, "probes.url")).Text(" ").Code(s.Target.URL).Line().
+		Text("⏱ ").Bold(T(lang, "probes.latency")).Text(" " + formatProbeLatency(s.Last.Latency))
+	if !s.Since.IsZero() {
+		r.Line().Text("⌛ ").Bold(T(lang, "probes.down_for")).Text(" " + formatDurationForAlert(lang, s.Last.Checked.Sub(s.Since)))
+	}
+	return r
+}
+
+func formatProbeLatency(d time.Duration) string {
+	return strconv.FormatInt(d.Milliseconds(), 10) + "ms"
+}
+
+// handleProbes показывает результаты последних проверок; до первого прохода проверяет сразу,
+// а если проход уже идёт, дожидается его
+func handleProbes(bot *tgbotapi.BotAPI, watcher *ProbeWatcher, ctx context.Context, chatID int64) {
+	states := watcher.Snapshot()
+	if len(states) == 0 {
+		watcher.checkFirst(ctx, clusters.All())
+		states = watcher.Snapshot()
+	}
+	sendLongRich(bot, chatID, "probes", renderProbes(langFor(chatID), states, time.Now()))
+}
+
+// renderProbes таблица проверок и причины неудач
+func renderProbes(lang Lang, states []probeState, now time.Time) *Rich {
+	failing := 0
+	for _, s := range states {
+		if !s.Last.OK {
+			failing++
+		}
+	}
+	r := NewRich().Text("🌐 ").Bold(T(lang, "probes.title", len(states), failing)).Line()
+	if len(states) == 0 {
+		return r.Text(T(lang, "probes.none"))
+	}
+	multi := len(clusters.Names()) > 1
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "TARGET\tCODE\tTIME\tTLS\tOK")
+	for _, s := range states {
+		name := s.Target.Name
+		if multi && s.Target.Cluster != "" {
+			name = s.Target.Cluster + ":" + name
+		}
+		code := "-"
+		if s.Last.Status != 0 {
+			code = strconv.Itoa(s.Last.Status)
+		}
+		cert := "-"
+		if !s.Last.CertExpiry.IsZero() {
+			cert = formatDuration(lang, s.Last.CertExpiry.Sub(now))
+		}
+		ok := "✓"
+		if !s.Last.OK {
+			ok = fmt.Sprintf("✗%d", s.Failures)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, code, formatProbeLatency(s.Last.Latency), cert, ok)
+	}
+	w.Flush()
+	r.Pre(strings.TrimRight(sb.String(), "\n"))
+
+	for _, s := range states {
+		if s.Last.OK {
+			continue
+		}
+		r.Line().Text("❌ ").Code(s.Target.Name).Text(" — " + s.Last.reason(lang))
+	}
+	return r
+}
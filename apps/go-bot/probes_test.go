@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func probeTestConfig() ProbeConfig {
+	cfg := DefaultProbeConfig()
+	cfg.DiscoverIngress, cfg.DiscoverServices = false, false
+	cfg.Timeout = time.Second
+	cfg.Latency = 200 * time.Millisecond
+	cfg.FailThreshold = 2
+	return cfg
+}
+
+func TestRunProbe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/ok":
+			_, _ = w.Write([]byte(`{"status":"healthy"}`))
+		case "/login":
+			http.Redirect(w, req, "/sso", http.StatusFound)
+		case "/private":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/slow":
+			time.Sleep(300 * time.Millisecond)
+		case "/hang":
+			select {
+			case <-req.Context().Done():
+			case <-time.After(2 * time.Second):
+			}
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := probeTestConfig()
+	cfg.Timeout = 500 * time.Millisecond
+	cases := []struct {
+		name   string
+		target probeTarget
+		status int
+		reason string
+	}{
+		{"ok", probeTarget{URL: srv.URL + "/ok"}, 200, ""},
+		{"body", probeTarget{URL: srv.URL + "/ok", Body: "healthy"}, 200, ""},
+		{"wrong body", probeTarget{URL: srv.URL + "/ok", Body: "ready"}, 200, `body does not contain "ready"`},
+		{"redirect is an answer", probeTarget{URL: srv.URL + "/login"}, 302, ""},
+		{"5xx", probeTarget{URL: srv.URL + "/down"}, 503, "HTTP 503"},
+		{"401 not allowed", probeTarget{URL: srv.URL + "/private"}, 401, "HTTP 401"},
+		{"401 allowed", probeTarget{URL: srv.URL + "/private", Status: []int{200, 401}}, 401, ""},
+		{"slow", probeTarget{URL: srv.URL + "/slow"}, 200, "latency"},
+		{"target latency", probeTarget{URL: srv.URL + "/slow", Latency: time.Second}, 200, ""},
+		{"timeout", probeTarget{URL: srv.URL + "/hang"}, 0, "timeout"},
+		{"refused", probeTarget{URL: "http://127.0.0.1:1/"}, 0, "connection refused"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := runProbe(context.Background(), probeClient(false), tc.target, cfg, time.Now())
+			if res.Status != tc.status {
+				t.Errorf("код %d, ожидался %d", res.Status, tc.status)
+			}
+			if res.OK != (tc.reason == "") || !strings.Contains(res.reason(LangEN), tc.reason) {
+				t.Errorf("OK=%v reason=%q, ожидалась причина %q", res.OK, res.reason(LangEN), tc.reason)
+			}
+			if tc.status != 0 && res.Latency <= 0 {
+				t.Errorf("не измерена задержка: %v", res.Latency)
+			}
+		})
+	}
+}
+
+func TestRunProbeTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	expiry := srv.Certificate().NotAfter
+	now := time.Now()
+	cfg := probeTestConfig()
+
+	// Клиент сервера доверяет его самоподписанному сертификату
+	res := runProbe(context.Background(), srv.Client(), probeTarget{URL: srv.URL}, cfg, now)
+	if !res.OK || !res.CertExpiry.Equal(expiry) {
+		t.Fatalf("OK=%v reason=%q expiry=%v, ожидался %v", res.OK, res.Reason, res.CertExpiry, expiry)
+	}
+
+	// Сертификат истекает раньше TLSMinValidity
+	cfg.TLSMinValidity = expiry.Sub(now) + time.Hour
+	res = runProbe(context.Background(), srv.Client(), probeTarget{URL: srv.URL}, cfg, now)
+	if res.OK || !strings.HasPrefix(res.reason(LangEN), "TLS certificate expires "+expiry.UTC().Format("2006-01-02")) {
+		t.Errorf("истекающий сертификат: OK=%v reason=%q", res.OK, res.reason(LangEN))
+	}
+
+	// У сервисов внутри кластера сертификат не проверяется
+	res = runProbe(context.Background(), probeClient(true), probeTarget{URL: srv.URL, Insecure: true}, cfg, now)
+	if !res.OK {
+		t.Errorf("insecure: %q", res.Reason)
+	}
+	// Непроверенный сертификат — ошибка проверки
+	res = runProbe(context.Background(), probeClient(false), probeTarget{URL: srv.URL}, probeTestConfig(), now)
+	if res.OK || !strings.Contains(res.Reason, "certificate") {
+		t.Errorf("недоверенный сертификат: OK=%v reason=%q", res.OK, res.Reason)
+	}
+}
+
+// flakyServer отвечает 200 или 503 в зависимости от healthy и считает запросы
+type flakyServer struct {
+	*httptest.Server
+	healthy  atomic.Bool
+	requests atomic.Int32
+	inFlight atomic.Int32
+	maxSeen  atomic.Int32
+	delay    time.Duration
+}
+
+func newFlakyServer(t *testing.T, delay time.Duration) *flakyServer {
+	t.Helper()
+	fs := &flakyServer{delay: delay}
+	fs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fs.requests.Add(1)
+		n := fs.inFlight.Add(1)
+		defer fs.inFlight.Add(-1)
+		for {
+			m := fs.maxSeen.Load()
+			if n <= m || fs.maxSeen.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(fs.delay)
+		if !fs.healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(fs.Close)
+	return fs
+}
+
+func TestProbeWatcherTransitions(t *testing.T) {
+	srv := newFlakyServer(t, 0)
+	cfg := probeTestConfig()
+	cfg.Targets = []probeTarget{{Name: "app", URL: srv.URL, Source: "config"}}
+	bot, ft := newFakeTelegram(t, nil)
+	w := NewProbeWatcher(cfg, bot, 42)
+	ctx := context.Background()
+	lang := langFor(42)
+
+	steps := []struct {
+		healthy  bool
+		failures int
+		alert    string
+	}{
+		{true, 0, ""},
+		{false, 1, ""},
+		{false, 2, T(lang, "probes.failed.title")},
+		{false, 3, ""}, // о той же неудаче повторно не сообщаем
+		{true, 0, T(lang, "probes.recovered.title")},
+		{true, 0, ""},
+		{false, 1, ""}, // одиночная неудача после восстановления — ещё не алерт
+	}
+	for i, step := range steps {
+		srv.healthy.Store(step.healthy)
+		before := len(sentSince(ft, 0))
+		w.check(ctx, nil)
+
+		states := w.Snapshot()
+		if len(states) != 1 || states[0].Failures != step.failures || states[0].Last.OK != step.healthy {
+			t.Fatalf("шаг %d: %+v", i, states)
+		}
+		sent := sentSince(ft, before)
+		switch {
+		case step.alert == "" && len(sent) != 0:
+			t.Errorf("шаг %d: лишнее уведомление %q", i, sent)
+		case step.alert != "" && (len(sent) != 1 || !strings.Contains(sent[0], step.alert)):
+			t.Errorf("шаг %d: ожидалось %q, отправлено %q", i, step.alert, sent)
+		}
+	}
+
+	// Цель убрали из настроек — её состояние забыто
+	w.cfg.Targets = nil
+	w.check(ctx, nil)
+	if states := w.Snapshot(); len(states) != 0 {
+		t.Errorf("осталось состояние удалённой цели: %+v", states)
+	}
+}
+
+// /probes до первого прохода не запускает проверку параллельно с Start
+// и не засчитывает неудачу дважды
+func TestHandleProbesWaitsForRunningCheck(t *testing.T) {
+	srv := newFlakyServer(t, 100*time.Millisecond)
+	cfg := probeTestConfig()
+	cfg.Targets = []probeTarget{{Name: "app", URL: srv.URL, Source: "config"}}
+	bot, ft := newFakeTelegram(t, nil)
+	w := NewProbeWatcher(cfg, bot, 42)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.check(ctx, nil)
+	}()
+	for srv.requests.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	handleProbes(bot, w, ctx, 7)
+	wg.Wait()
+
+	if n := srv.requests.Load(); n != 1 {
+		t.Errorf("запросов к цели %d, ожидался один проход", n)
+	}
+	if states := w.Snapshot(); len(states) != 1 || states[0].Failures != 1 {
+		t.Errorf("состояние: %+v", states)
+	}
+	if sent := sentSince(ft, 0); len(sent) != 1 || !strings.Contains(sent[0], "app") {
+		t.Errorf("ответ /probes: %q", sent)
+	}
+
+	// Параллельные проходы выполняются по очереди
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.check(ctx, nil)
+		}()
+	}
+	wg.Wait()
+	if srv.maxSeen.Load() != 1 {
+		t.Errorf("проходы пересеклись: одновременно %d запросов", srv.maxSeen.Load())
+	}
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// promChartPoints примерное число точек на графике, по нему выбирается step
+const promChartPoints = 120
+
+// PromClient клиент HTTP API Prometheus с таймаутом и ограничением размера ответа
+type PromClient struct {
+	cfg  PrometheusConfig
+	http *http.Client
+}
+
+// NewPromClient создаёт клиент; при пустом URL возвращает nil
+func NewPromClient(cfg PrometheusConfig) *PromClient {
+	if cfg.URL == "" {
+		return nil
+	}
+	return &PromClient{cfg: cfg, http: &http.Client{Timeout: cfg.Timeout, Transport: instrumentTransport(nil, "prometheus", "")}}
+}
+
+// promPoint точка ряда: в API это пара [unix-время, "значение"]
+type promPoint struct {
+	Time  float64
+	Value float64
+}
+
+func (p *promPoint) UnmarshalJSON(data []byte) error {
+	var raw [2]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[0], &p.Time); err != nil {
+		return err
+	}
+	var v string
+	if err := json.Unmarshal(raw[1], &v); err != nil {
+		return err
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return err
+	}
+	p.Value = f
+	return nil
+}
+
+// promSeries ряд instant- или range-запроса
+type promSeries struct {
+	Metric map[string]string `json:"metric"`
+	Value  promPoint         `json:"value"`
+	Values []promPoint       `json:"values"`
+}
+
+// promResult разобранный ответ: vector, matrix или scalar
+type promResult struct {
+	Type   string
+	Series []promSeries
+	Scalar promPoint
+}
+
+// Query выполняет instant-запрос
+func (c *PromClient) Query(ctx context.Context, expr string, at time.Time) (promResult, error) {
+	params := url.Values{}
+	params.Set("query", expr)
+	params.Set("time", strconv.FormatInt(at.Unix(), 10))
+	return c.do(ctx, "/api/v1/query", params)
+}
+
+// QueryRange выполняет range-запрос
+func (c *PromClient) QueryRange(ctx context.Context, expr string, start, end time.Time, step time.Duration) (promResult, error) {
+	params := url.Values{}
+	params.Set("query", expr)
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+	params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	return c.do(ctx, "/api/v1/query_range", params)
+}
+
+func (c *PromClient) do(ctx context.Context, path string, params url.Values) (promResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+	// Prometheus прервёт тяжёлый запрос сам, не дожидаясь обрыва соединения
+	params.Set("timeout", c.cfg.Timeout.String())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.URL+path, strings.NewReader(params.Encode()))
+	if err != nil {
+		return promResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return promResult{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.cfg.MaxResponseBytes+1))
+	if err != nil {
+		return promResult{}, err
+	}
+	if int64(len(body)) > c.cfg.MaxResponseBytes {
+		return promResult{}, fmt.Errorf("ответ Prometheus больше %d байт, уточните запрос", c.cfg.MaxResponseBytes)
+	}
+
+	var envelope struct {
+		Status    string `json:"status"`
+		ErrorType string `json:"errorType"`
+		Error     string `json:"error"`
+		Data      struct {
+			ResultType string          `json:"resultType"`
+			Result     json.RawMessage `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return promResult{}, fmt.Errorf("Prometheus вернул %s: %w", resp.Status, err)
+	}
+	if envelope.Status != "success" {
+		return promResult{}, fmt.Errorf("%s: %s", envelope.ErrorType, envelope.Error)
+	}
+
+	res := promResult{Type: envelope.Data.ResultType}
+	switch res.Type {
+	case "vector", "matrix":
+		err = json.Unmarshal(envelope.Data.Result, &res.Series)
+	case "scalar", "string":
+		err = json.Unmarshal(envelope.Data.Result, &res.Scalar)
+	default:
+		err = fmt.Errorf("неизвестный тип результата: %s", res.Type)
+	}
+	return res, err
+}
+
+// promRequest разобранные аргументы /promql
+type promRequest struct {
+	Expr  string
+	Range time.Duration // 0 — instant-запрос
+	Step  time.Duration
+}
+
+// parsePromArgs разбирает /promql [--range 6h] [--step 1m] <expr>; выражение берётся как есть
+func parsePromArgs(args string) (promRequest, error) {
+	var req promRequest
+	rest := strings.TrimSpace(args)
+	for strings.HasPrefix(rest, "--") {
+		flag, tail, _ := strings.Cut(rest, " ")
+		value, tail, _ := strings.Cut(strings.TrimSpace(tail), " ")
+		d, err := parsePeriod(value)
+		if err != nil {
+			return req, err
+		}
+		switch flag {
+		case "--range":
+			req.Range = d
+		case "--step":
+			req.Step = d
+		default:
+			return req, fmt.Errorf("неизвестный флаг: %s", flag)
+		}
+		rest = strings.TrimSpace(tail)
+	}
+	if rest == "" {
+		return req, fmt.Errorf("пустой запрос")
+	}
+	req.Expr = rest
+	return req, nil
+}
+
+// handlePromQL выполняет запрос: instant-вектор выводится таблицей, range — графиком
+func handlePromQL(bot *tgbotapi.BotAPI, prom *PromClient, ctx context.Context, chatID int64, req promRequest) {
+	lang := langFor(chatID)
+	if prom == nil {
+		sendText(bot, chatID, T(lang, "prom.disabled"))
+		return
+	}
+
+	now := time.Now()
+	if req.Range == 0 {
+		res, err := prom.Query(ctx, req.Expr, now)
+		if err != nil {
+			sendError(bot, chatID, err)
+			return
+		}
+		sendLongRich(bot, chatID, "promql", renderPromInstant(lang, req.Expr, res, prom.cfg.MaxSeries))
+		return
+	}
+
+	step := req.Step
+	if step == 0 {
+		step = max(req.Range/promChartPoints, 15*time.Second)
+	}
+	res, err := prom.QueryRange(ctx, req.Expr, now.Add(-req.Range), now, step)
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	series := res.Series
+	if len(series) == 0 {
+		sendText(bot, chatID, T(lang, "prom.empty"))
+		return
+	}
+	if len(series) > prom.cfg.MaxChartSeries {
+		series = series[:prom.cfg.MaxChartSeries]
+	}
+	data := make([][]Sample, len(series))
+	for i, s := range series {
+		for _, p := range s.Values {
+			if math.IsNaN(p.Value) || math.IsInf(p.Value, 0) {
+				continue
+			}
+			data[i] = append(data[i], Sample{Time: int64(p.Time), Value: p.Value})
+		}
+	}
+	img, err := renderLineChart(data, 1, chartWidth, chartHeight)
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "promql.png", Bytes: img})
+	photo.Caption = promChartCaption(lang, req, series, len(res.Series))
+	if _, err := bot.Send(photo); err != nil {
+		sendError(bot, chatID, err)
+	}
+}
+
+// handleSavedQuery выполняет сохранённый запрос /q <name> или показывает их список
+func handleSavedQuery(bot *tgbotapi.BotAPI, prom *PromClient, ctx context.Context, chatID int64, args string) {
+	lang := langFor(chatID)
+	if prom == nil {
+		sendText(bot, chatID, T(lang, "prom.disabled"))
+		return
+	}
+	name := strings.TrimSpace(args)
+	q, ok := prom.cfg.Queries[name]
+	if !ok {
+		sendLongRich(bot, chatID, "queries", renderSavedQueries(lang, prom.cfg.Queries, name))
+		return
+	}
+	req := promRequest{Expr: q.Expr}
+	if q.Range != "" {
+		d, err := parsePeriod(q.Range)
+		if err != nil {
+			sendError(bot, chatID, err)
+			return
+		}
+		req.Range = d
+	}
+	handlePromQL(bot, prom, ctx, chatID, req)
+}
+
+// renderSavedQueries список сохранённых запросов
+func renderSavedQueries(lang Lang, queries map[string]SavedQuery, missing string) *Rich {
+	r := NewRich()
+	if missing != "" {
+		r.Text(T(lang, "prom.unknown_query", missing)).Line().Line()
+	}
+	r.Text("📐 ").Bold(T(lang, "prom.saved_title")).Line()
+	names := make([]string, 0, len(queries))
+	for name := range queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		r.Text("• ").Code(name)
+		if q := queries[name]; q.Range != "" {
+			r.Text(" (" + q.Range + ")")
+		}
+		r.Line()
+	}
+	return r
+}
+
+// renderPromInstant таблица instant-вектора, отсортированная по убыванию значения
+func renderPromInstant(lang Lang, expr string, res promResult, maxSeries int) *Rich {
+	r := NewRich()
+	r.Text("📐 ").Code(expr).Line()
+	if res.Type == "scalar" || res.Type == "string" {
+		return r.Bold(formatPromValue(res.Scalar.Value))
+	}
+	if len(res.Series) == 0 {
+		return r.Text(T(lang, "prom.empty"))
+	}
+
+	series := append([]promSeries(nil), res.Series...)
+	sort.SliceStable(series, func(i, j int) bool { return series[i].Value.Value > series[j].Value.Value })
+	if len(series) > maxSeries {
+		r.Text(T(lang, "top.shown", maxSeries, len(series))).Line()
+		series = series[:maxSeries]
+	}
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "SERIES\tVALUE")
+	for _, s := range series {
+		fmt.Fprintf(w, "%s\t%s\n", formatPromLabels(s.Metric), formatPromValue(s.Value.Value))
+	}
+	w.Flush()
+	r.Pre(strings.TrimRight(sb.String(), "\n"))
+	return r
+}
+
+// promChartCaption подпись графика: выражение и соответствие цветов рядам
+func promChartCaption(lang Lang, req promRequest, series []promSeries, total int) string {
+	var sb strings.Builder
+	sb.WriteString(req.Expr + "\n" + T(lang, "prom.range", formatPeriod(req.Range)))
+	if total > len(series) {
+		sb.WriteString(", " + T(lang, "top.shown", len(series), total))
+	}
+	for i, s := range series {
+		sb.WriteString("\n" + chartPaletteNames[i%len(chartPaletteNames)] + " " + formatPromLabels(s.Metric))
+	}
+	// Подпись к фото ограничена 1024 символами
+	caption := []rune(sb.String())
+	if len(caption) > 1024 {
+		caption = append(caption[:1021], []rune("...")...)
+	}
+	return string(caption)
+}
+
+// formatPromLabels компактная запись ряда: name a=b c=d
+func formatPromLabels(metric map[string]string) string {
+	keys := make([]string, 0, len(metric))
+	for k := range metric {
+		if k != "__name__" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys)+1)
+	if name := metric["__name__"]; name != "" {
+		parts = append(parts, name)
+	}
+	for _, k := range keys {
+		parts = append(parts, k+"="+metric[k])
+	}
+	if len(parts) == 0 {
+		return "{}"
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatPromValue короткая запись значения без лишних знаков
+func formatPromValue(v float64) string {
+	switch {
+	case math.IsNaN(v) || math.IsInf(v, 0):
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case v == math.Trunc(v) && math.Abs(v) < 1e15:
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	case math.Abs(v) >= 1e6 || math.Abs(v) < 1e-3:
+		return strconv.FormatFloat(v, 'g', 4, 64)
+	default:
+		return strconv.FormatFloat(v, 'f', 3, 64)
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakePrometheus отвечает на /api/v1/query и /api/v1/query_range заранее заданным телом
+func fakePrometheus(t *testing.T, handler func(path string, form map[string]string) (int, string)) *PromClient {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("форма запроса: %v", err)
+		}
+		form := make(map[string]string)
+		for k := range r.PostForm {
+			form[k] = r.PostForm.Get(k)
+		}
+		code, body := handler(r.URL.Path, form)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	cfg := DefaultPrometheusConfig()
+	cfg.URL = srv.URL
+	cfg.Timeout = 2 * time.Second
+	return NewPromClient(cfg)
+}
+
+func TestPromClientQuery(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	prom := fakePrometheus(t, func(path string, form map[string]string) (int, string) {
+		if path != "/api/v1/query" || form["query"] != "up" || form["time"] != "1700000000" || form["timeout"] != "2s" {
+			t.Errorf("запрос %s %v", path, form)
+		}
+		return 200, `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"__name__":"up","job":"node"},"value":[1700000000,"1"]},
+			{"metric":{"__name__":"up","job":"api"},"value":[1700000000,"0"]}]}}`
+	})
+	res, err := prom.Query(context.Background(), "up", at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Type != "vector" || len(res.Series) != 2 {
+		t.Fatalf("результат %+v", res)
+	}
+	if s := res.Series[0]; s.Metric["job"] != "node" || s.Value.Value != 1 || s.Value.Time != 1700000000 {
+		t.Errorf("первый ряд %+v", s)
+	}
+}
+
+func TestPromClientQueryRange(t *testing.T) {
+	end := time.Unix(1700003600, 0)
+	prom := fakePrometheus(t, func(path string, form map[string]string) (int, string) {
+		if path != "/api/v1/query_range" || form["start"] != "1700000000" || form["end"] != "1700003600" || form["step"] != "30" {
+			t.Errorf("запрос %s %v", path, form)
+		}
+		return 200, `{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"instance":"a"},"values":[[1700000000,"1.5"],[1700000030,"NaN"],[1700000060,"-2"]]}]}}`
+	})
+	res, err := prom.QueryRange(context.Background(), "x", end.Add(-time.Hour), end, 30*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Type != "matrix" || len(res.Series) != 1 || len(res.Series[0].Values) != 3 {
+		t.Fatalf("результат %+v", res)
+	}
+	if v := res.Series[0].Values[2].Value; v != -2 {
+		t.Errorf("последняя точка %v", v)
+	}
+}
+
+func TestPromClientErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		code int
+		body string
+		want string
+	}{
+		{"bad query", 400, `{"status":"error","errorType":"bad_data","error":"parse error at char 3"}`, "bad_data: parse error"},
+		{"not json", 502, `<html>bad gateway</html>`, "502"},
+		{"unknown type", 200, `{"status":"success","data":{"resultType":"weird","result":[]}}`, "weird"},
+		{"too large", 200, `{"status":"success","data":{"resultType":"vector","result":[` + strings.Repeat(`{"metric":{},"value":[1,"1"]},`, 100) + `{"metric":{},"value":[1,"1"]}]}}`, "больше"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			prom := fakePrometheus(t, func(string, map[string]string) (int, string) { return tc.code, tc.body })
+			prom.cfg.MaxResponseBytes = 1024
+			_, err := prom.Query(context.Background(), "up", time.Now())
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("ошибка %v, ожидалась с %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestPromClientScalar(t *testing.T) {
+	prom := fakePrometheus(t, func(string, map[string]string) (int, string) {
+		return 200, `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"3.25"]}}`
+	})
+	res, err := prom.Query(context.Background(), "1+2.25", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Type != "scalar" || res.Scalar.Value != 3.25 {
+		t.Errorf("результат %+v", res)
+	}
+}
+
+func TestParsePromArgs(t *testing.T) {
+	cases := []struct {
+		args    string
+		want    promRequest
+		wantErr bool
+	}{
+		{args: "up", want: promRequest{Expr: "up"}},
+		{args: "  sum by (job) (up)  ", want: promRequest{Expr: "sum by (job) (up)"}},
+		{args: "--range 6h rate(x[5m])", want: promRequest{Expr: "rate(x[5m])", Range: 6 * time.Hour}},
+		{args: "--range 1d --step 5m up", want: promRequest{Expr: "up", Range: 24 * time.Hour, Step: 5 * time.Minute}},
+		{args: "-node_memory_MemTotal_bytes", want: promRequest{Expr: "-node_memory_MemTotal_bytes"}},
+		{args: "", wantErr: true},
+		{args: "--range 6h", wantErr: true},
+		{args: "--range abc up", wantErr: true},
+		{args: "--offset 5m up", wantErr: true},
+	}
+	for _, tc := range cases {
+		got, err := parsePromArgs(tc.args)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%q: ошибка %v", tc.args, err)
+			continue
+		}
+		if !tc.wantErr && got != tc.want {
+			t.Errorf("%q: %+v, ожидалось %+v", tc.args, got, tc.want)
+		}
+	}
+}
+
+func TestRenderPromInstant(t *testing.T) {
+	withRenderMode(t, tgbotapi.ModeHTML)
+	res := promResult{Type: "vector", Series: []promSeries{
+		{Metric: map[string]string{"__name__": "load", "instance": "a"}, Value: promPoint{Value: 0.5}},
+		{Metric: map[string]string{"__name__": "load", "instance": "b<c>"}, Value: promPoint{Value: 2}},
+		{Metric: map[string]string{"instance": "c"}, Value: promPoint{Value: 1250000}},
+	}}
+	got := renderPromInstant(LangEN, "load > 0", res, 2).String()
+	want := "📐 <code>load &gt; 0</code>\n" +
+		"Showing 2 of 3\n" +
+		"<pre>SERIES             VALUE\n" +
+		"instance=c         1250000\n" +
+		"load instance=b&lt;c&gt; 2</pre>"
+	if got != want {
+		t.Errorf("таблица:\n%s\nожидалось:\n%s", got, want)
+	}
+
+	scalar := renderPromInstant(LangEN, "1+1", promResult{Type: "scalar", Scalar: promPoint{Value: 2}}, 10).Plain()
+	if scalar != "📐 1+1\n2" {
+		t.Errorf("scalar: %q", scalar)
+	}
+	if empty := renderPromInstant(LangRU, "up == 2", promResult{Type: "vector"}, 10).Plain(); !strings.HasSuffix(empty, "Пустой результат") {
+		t.Errorf("пустой вектор: %q", empty)
+	}
+}
@@ -0,0 +1,520 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Уровни заполнения PVC
+const (
+	pvcLevelOK = iota
+	pvcLevelWarn
+	pvcLevelCrit
+)
+
+// kubeletSummary часть ответа /stats/summary с томами pod-ов
+type kubeletSummary struct {
+	Pods []struct {
+		PodRef struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"podRef"`
+		Volumes []struct {
+			PVCRef *struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"pvcRef"`
+			CapacityBytes  *int64 `json:"capacityBytes"`
+			UsedBytes      *int64 `json:"usedBytes"`
+			AvailableBytes *int64 `json:"availableBytes"`
+			Inodes         *int64 `json:"inodes"`
+			InodesUsed     *int64 `json:"inodesUsed"`
+		} `json:"volume"`
+	} `json:"pods"`
+}
+
+// volumeStats заполнение тома по данным kubelet-а
+type volumeStats struct {
+	Capacity   int64
+	Used       int64
+	Available  int64
+	Inodes     int64
+	InodesUsed int64
+	Pod        string
+	Node       string
+}
+
+// pvcUsage PVC и, если kubelet их отдаёт, данные о заполнении; для hostPath и local-path их нет
+type pvcUsage struct {
+	Namespace    string
+	Name         string
+	Phase        corev1.PersistentVolumeClaimPhase
+	StorageClass string
+	Volume       string
+	Requested    int64
+	Created      time.Time
+	Stats        *volumeStats
+}
+
+func (p pvcUsage) key() string {
+	return p.Namespace + "/" + p.Name
+}
+
+// UsedPercent занято места, процентов
+func (p pvcUsage) UsedPercent() float64 {
+	if p.Stats == nil || p.Stats.Capacity <= 0 {
+		return 0
+	}
+	return 100 * float64(p.Stats.Used) / float64(p.Stats.Capacity)
+}
+
+// InodesPercent занято inode, процентов
+func (p pvcUsage) InodesPercent() float64 {
+	if p.Stats == nil || p.Stats.Inodes <= 0 {
+		return 0
+	}
+	return 100 * float64(p.Stats.InodesUsed) / float64(p.Stats.Inodes)
+}
+
+// level уровень заполнения по порогам
+func (p pvcUsage) level(cfg VolumeConfig) int {
+	switch pct := p.UsedPercent(); {
+	case p.Stats == nil:
+		return pvcLevelOK
+	case pct >= cfg.CritPercent:
+		return pvcLevelCrit
+	case pct >= cfg.WarnPercent:
+		return pvcLevelWarn
+	}
+	return pvcLevelOK
+}
+
+// fetchVolumeStats тома PVC узла из /api/v1/nodes/<node>/proxy/stats/summary
+func fetchVolumeStats(ctx context.Context, cluster *Cluster, node string) (map[string]volumeStats, error) {
+	data, err := cluster.Typed.CoreV1().RESTClient().Get().
+		AbsPath("/api/v1/nodes", node, "proxy", "stats", "summary").DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return parseVolumeStats(data, node)
+}
+
+// parseVolumeStats тома с pvcRef по ключу namespace/имя; PVC, смонтированный в несколько pod-ов, берётся один раз
+func parseVolumeStats(data []byte, node string) (map[string]volumeStats, error) {
+	var summary kubeletSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, err
+	}
+	deref := func(v *int64) int64 {
+		if v == nil {
+			return 0
+		}
+		return *v
+	}
+	stats := make(map[string]volumeStats)
+	for _, pod := range summary.Pods {
+		for _, v := range pod.Volumes {
+			if v.PVCRef == nil || v.CapacityBytes == nil {
+				continue
+			}
+			key := v.PVCRef.Namespace + "/" + v.PVCRef.Name
+			if _, ok := stats[key]; ok {
+				continue
+			}
+			stats[key] = volumeStats{
+				Capacity:   deref(v.CapacityBytes),
+				Used:       deref(v.UsedBytes),
+				Available:  deref(v.AvailableBytes),
+				Inodes:     deref(v.Inodes),
+				InodesUsed: deref(v.InodesUsed),
+				Pod:        pod.PodRef.Name,
+				Node:       node,
+			}
+		}
+	}
+	return stats, nil
+}
+
+// collectPVCUsage PVC namespace (пустой — всех) с заполнением из stats/summary всех узлов
+func collectPVCUsage(ctx context.Context, cluster *Cluster, ns string) ([]pvcUsage, error) {
+	pvcs, err := cluster.Typed.CoreV1().PersistentVolumeClaims(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	usages := make([]pvcUsage, 0, len(pvcs.Items))
+	bound := false
+	for _, pvc := range pvcs.Items {
+		u := pvcUsage{
+			Namespace: pvc.Namespace,
+			Name:      pvc.Name,
+			Phase:     pvc.Status.Phase,
+			Volume:    pvc.Spec.VolumeName,
+			Created:   pvc.CreationTimestamp.Time,
+		}
+		if pvc.Spec.StorageClassName != nil {
+			u.StorageClass = *pvc.Spec.StorageClassName
+		}
+		if q, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+			u.Requested = q.Value()
+		} else if q, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			u.Requested = q.Value()
+		}
+		bound = bound || u.Phase == corev1.ClaimBound
+		usages = append(usages, u)
+	}
+	if !bound {
+		return usages, nil
+	}
+
+	nodes, err := cluster.Typed.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	stats := make(map[string]volumeStats)
+	for _, n := range nodes.Items {
+		nodeStats, err := fetchVolumeStats(ctx, cluster, n.Name)
+		if err != nil {
+			// Недоступный kubelet не должен скрывать тома остальных узлов
+			log.Printf("⚠️ [%s] Не удалось получить stats/summary узла %s: %v", cluster.Name, n.Name, err)
+			continue
+		}
+		for k, v := range nodeStats {
+			stats[k] = v
+		}
+	}
+	for i := range usages {
+		if s, ok := stats[usages[i].key()]; ok {
+			usages[i].Stats = &s
+		}
+	}
+	return usages, nil
+}
+
+// pvProblem PV в Released или Failed
+type pvProblem struct {
+	Name     string
+	Phase    corev1.PersistentVolumePhase
+	Claim    string
+	Reclaim  string
+	Message  string
+	Capacity int64
+}
+
+// listPVProblems PV, которые не вернутся в работу без вмешательства
+func listPVProblems(ctx context.Context, cluster *Cluster) ([]pvProblem, error) {
+	pvs, err := cluster.Typed.CoreV1().PersistentVolumes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var problems []pvProblem
+	for _, pv := range pvs.Items {
+		if pv.Status.Phase != corev1.VolumeReleased && pv.Status.Phase != corev1.VolumeFailed {
+			continue
+		}
+		p := pvProblem{Name: pv.Name, Phase: pv.Status.Phase, Reclaim: string(pv.Spec.PersistentVolumeReclaimPolicy), Message: pv.Status.Message}
+		if ref := pv.Spec.ClaimRef; ref != nil {
+			p.Claim = ref.Namespace + "/" + ref.Name
+		}
+		if q, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok {
+			p.Capacity = q.Value()
+		}
+		problems = append(problems, p)
+	}
+	sort.Slice(problems, func(i, j int) bool { return problems[i].Name < problems[j].Name })
+	return problems, nil
+}
+
+// pvcPendingReason последнее событие PVC: обычно там причина, по которой том не выделен
+func pvcPendingReason(ctx context.Context, cluster *Cluster, ns, name string) string {
+	events, err := cluster.Typed.CoreV1().Events(ns).List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=PersistentVolumeClaim,involvedObject.name=" + name,
+	})
+	if err != nil || len(events.Items) == 0 {
+		return ""
+	}
+	last := events.Items[0]
+	for _, e := range events.Items[1:] {
+		if e.LastTimestamp.After(last.LastTimestamp.Time) {
+			last = e
+		}
+	}
+	return last.Reason + ": " + last.Message
+}
+
+// VolumeWatcher алерты о заполнении PVC, зависших PVC и PV в Released/Failed
+type VolumeWatcher struct {
+	cluster *Cluster
+	cfg     VolumeConfig
+	bot     *tgbotapi.BotAPI
+	adminID int64
+
+	levels  map[string]int    // namespace/имя → уровень, о котором уже сообщили
+	inodes  map[string]bool   // namespace/имя → сообщили о нехватке inode
+	pending map[string]bool   // namespace/имя → сообщили о Pending
+	pvs     map[string]string // имя PV → фаза, о которой сообщили
+}
+
+// NewVolumeWatcher создаёт наблюдателя за томами кластера
+func NewVolumeWatcher(cluster *Cluster, cfg VolumeConfig, bot *tgbotapi.BotAPI, adminID int64) *VolumeWatcher {
+	return &VolumeWatcher{
+		cluster: cluster, cfg: cfg, bot: bot, adminID: adminID,
+		levels:  make(map[string]int),
+		inodes:  make(map[string]bool),
+		pending: make(map[string]bool),
+		pvs:     make(map[string]string),
+	}
+}
+
+// Start проверяет тома сразу и затем каждые PollInterval
+func (w *VolumeWatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		w.check(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *VolumeWatcher) check(ctx context.Context, now time.Time) {
+	lang := langFor(w.adminID)
+	usages, err := collectPVCUsage(ctx, w.cluster, "")
+	if err != nil {
+		log.Printf("❌ [%s] Ошибка получения PVC: %v", w.cluster.Name, err)
+	} else {
+		w.checkClaims(ctx, lang, usages, now)
+	}
+
+	problems, err := listPVProblems(ctx, w.cluster)
+	if err != nil {
+		log.Printf("❌ [%s] Ошибка получения PV: %v", w.cluster.Name, err)
+		return
+	}
+	present := make(map[string]bool, len(problems))
+	for _, p := range problems {
+		present[p.Name] = true
+		if w.pvs[p.Name] != string(p.Phase) {
+			w.notify("pv_"+strings.ToLower(string(p.Phase)), renderPVAlert(lang, w.cluster.Name, p))
+			w.pvs[p.Name] = string(p.Phase)
+		}
+	}
+	for name := range w.pvs {
+		if !present[name] {
+			delete(w.pvs, name)
+		}
+	}
+}
+
+func (w *VolumeWatcher) checkClaims(ctx context.Context, lang Lang, usages []pvcUsage, now time.Time) {
+	present := make(map[string]bool, len(usages))
+	for _, u := range usages {
+		key := u.key()
+		present[key] = true
+
+		if u.Phase == corev1.ClaimPending {
+			if !w.pending[key] && now.Sub(u.Created) >= w.cfg.PendingGrace {
+				reason := pvcPendingReason(ctx, w.cluster, u.Namespace, u.Name)
+				w.notify("pvc_pending", renderPVCPendingAlert(lang, w.cluster.Name, u, now, reason))
+				w.pending[key] = true
+			}
+			continue
+		}
+		if w.pending[key] {
+			delete(w.pending, key)
+			w.notify("pvc_bound", NewRich().Text("✅ "+alertPrefix(w.cluster.Name)).Bold(T(lang, "pvc.bound.title")).Text(" ").Code(key))
+		}
+
+		// Без данных kubelet-а прежний уровень сохраняется
+		if u.Stats == nil {
+			continue
+		}
+		level, prev := u.level(w.cfg), w.levels[key]
+		switch {
+		case level > prev:
+			w.notify("pvc_usage", renderPVCUsageAlert(lang, w.cluster.Name, u, level))
+		case level == pvcLevelOK && prev > pvcLevelOK:
+			w.notify("pvc_usage_ok", NewRich().Text("✅ "+alertPrefix(w.cluster.Name)).Bold(T(lang, "pvc.ok.title")).Text(" ").Code(key).Textf(" %.0f%%", u.UsedPercent()))
+		}
+		w.levels[key] = level
+
+		high := u.InodesPercent() >= w.cfg.InodesWarnPercent
+		if high && !w.inodes[key] {
+			w.notify("pvc_inodes", renderPVCInodesAlert(lang, w.cluster.Name, u))
+		}
+		w.inodes[key] = high
+	}
+	for key := range w.levels {
+		if !present[key] {
+			delete(w.levels, key)
+		}
+	}
+	for _, m := range []map[string]bool{w.inodes, w.pending} {
+		for key := range m {
+			if !present[key] {
+				delete(m, key)
+			}
+		}
+	}
+}
+
+func (w *VolumeWatcher) notify(kind string, r *Rich) {
+	result := "sent"
+	if err := sendRich(w.bot, w.adminID, r); err != nil {
+		result = "failed"
+	}
+	telemetry.Notifications.Inc(kind, result)
+}
+
+// renderPVCUsageAlert PVC заполнен выше порога
+func renderPVCUsageAlert(lang Lang, cluster string, u pvcUsage, level int) *Rich {
+	title := T(lang, "pvc.warn.title")
+	if level == pvcLevelCrit {
+		title = T(lang, "pvc.crit.title")
+	}
+	return NewRich().
+		Text("🚨 "+alertPrefix(cluster)).Bold(title).Line().Line().
+		Text("💾 ").Bold("PVC:").Text(" ").Code(u.key()).Line().
+		Text("📊 ").Bold(T(lang, "pvc.used")).Textf(" %s / %s (%.1f%%)", formatMemory(u.Stats.Used), formatMemory(u.Stats.Capacity), u.UsedPercent()).Line().
+		Text("📦 ").Bold("Pod:").Text(" " + u.Stats.Pod + " @ " + u.Stats.Node)
+}
+
+// renderPVCInodesAlert на томе заканчиваются inode при свободном месте
+func renderPVCInodesAlert(lang Lang, cluster string, u pvcUsage) *Rich {
+	return NewRich().
+		Text("🚨 "+alertPrefix(cluster)).Bold(T(lang, "pvc.inodes.title")).Line().Line().
+		Text("💾 ").Bold("PVC:").Text(" ").Code(u.key()).Line().
+		Text("📊 ").Bold("Inodes:").Textf(" %d / %d (%.1f%%)", u.Stats.InodesUsed, u.Stats.Inodes, u.InodesPercent())
+}
+
+// renderPVCPendingAlert PVC дольше PendingGrace не получил том
+func renderPVCPendingAlert(lang Lang, cluster string, u pvcUsage, now time.Time, reason string) *Rich {
+	r := NewRich().
+		Text("🚨 " + alertPrefix(cluster)).Bold(T(lang, "pvc.pending.title")).Line().Line().
+		Text("💾 ").Bold("PVC:").Text(" ").Code(u.key()).Line().
+		Text("🏷 ").Bold("StorageClass:").Text(" " + orDash(u.StorageClass)).Line().
+		Text("⏱ ").Text(T(lang, "pvc.pending_for", formatDuration(lang, now.Sub(u.Created))))
+	if reason != "" {
+		r.Line().Text(T(lang, "alert.reason", reason))
+	}
+	return r
+}
+
+// renderPVAlert PV в Released (данные остались, том не переиспользуется) или Failed
+func renderPVAlert(lang Lang, cluster string, p pvProblem) *Rich {
+	title, text := T(lang, "pv.released.title"), T(lang, "pv.released.text", p.Name)
+	if p.Phase == corev1.VolumeFailed {
+		title, text = T(lang, "pv.failed.title"), T(lang, "pv.failed.text")
+	}
+	r := NewRich().
+		Text("🚨 "+alertPrefix(cluster)).Bold(title).Line().Line().
+		Text("💽 ").Bold("PV:").Text(" ").Code(p.Name).Textf(" (%s, %s)", formatMemory(p.Capacity), orDash(p.Reclaim)).Line().
+		Text("💾 ").Bold(T(lang, "pvc.claim")).Text(" " + orDash(p.Claim)).Line()
+	if p.Message != "" {
+		r.Text(T(lang, "alert.reason", p.Message)).Line()
+	}
+	return r.Text(text)
+}
+
+// handlePVC показывает заполнение PVC: /pvc [ns]
+func handlePVC(bot *tgbotapi.BotAPI, cluster *Cluster, cfg VolumeConfig, ctx context.Context, chatID int64, args string) {
+	lang := langFor(chatID)
+	fields := strings.Fields(args)
+	if len(fields) > 1 {
+		sendText(bot, chatID, T(lang, "usage.pvc"))
+		return
+	}
+	ns := ""
+	if len(fields) == 1 {
+		ns = fields[0]
+	}
+	usages, err := collectPVCUsage(ctx, cluster, ns)
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	// Проблемные PV кластерные, показываем их только в общем списке
+	var problems []pvProblem
+	if ns == "" {
+		if problems, err = listPVProblems(ctx, cluster); err != nil {
+			log.Printf("⚠️ [%s] Ошибка получения PV: %v", cluster.Name, err)
+		}
+	}
+	sendLongRich(bot, chatID, "pvc", renderPVC(lang, clusterLabel(cluster.Name), ns, usages, problems, cfg))
+}
+
+// renderPVC таблица PVC по убыванию заполнения; «!» — выше порога предупреждения
+func renderPVC(lang Lang, cluster, ns string, usages []pvcUsage, problems []pvProblem, cfg VolumeConfig) *Rich {
+	title := T(lang, "pvc.title", len(usages))
+	if ns != "" {
+		title = T(lang, "pvc.title_ns", ns, len(usages))
+	}
+	r := NewRich().Text("💾 " + cluster).Bold(title).Line()
+	if len(usages) == 0 {
+		r.Text(T(lang, "pvc.none")).Line()
+	} else {
+		sorted := append([]pvcUsage(nil), usages...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			if a, b := sorted[i].UsedPercent(), sorted[j].UsedPercent(); a != b {
+				return a > b
+			}
+			return sorted[i].key() < sorted[j].key()
+		})
+		var sb strings.Builder
+		w := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
+		fmt.Fprintln(w, "PVC\tUSED\tSIZE\tUSE%\tINODES")
+		noStats := 0
+		for _, u := range sorted {
+			name := u.key()
+			if ns != "" {
+				name = u.Name
+			}
+			size := "-"
+			if u.Requested > 0 {
+				size = formatMemory(u.Requested)
+			}
+			switch {
+			case u.Phase != corev1.ClaimBound:
+				fmt.Fprintf(w, "%s\t-\t%s\t%s\t-\n", name, size, u.Phase)
+			case u.Stats == nil:
+				noStats++
+				fmt.Fprintf(w, "%s\t-\t%s\t-\t-\n", name, size)
+			default:
+				mark := ""
+				if u.level(cfg) > pvcLevelOK {
+					mark = "!"
+				}
+				inodes := "-"
+				if u.Stats.Inodes > 0 {
+					inodes = fmt.Sprintf("%.0f%%", u.InodesPercent())
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%.0f%%%s\t%s\n", name, formatMemory(u.Stats.Used), formatMemory(u.Stats.Capacity), u.UsedPercent(), mark, inodes)
+			}
+		}
+		w.Flush()
+		r.Pre(strings.TrimRight(sb.String(), "\n"))
+		if noStats > 0 {
+			r.Line().Italic(T(lang, "pvc.no_stats", noStats))
+		}
+	}
+
+	if len(problems) > 0 {
+		r.Line().Bold(T(lang, "pvc.pv_problems", len(problems))).Line()
+		for _, p := range problems {
+			r.Text("• ").Code(p.Name).Textf(" %s, %s → %s", p.Phase, formatMemory(p.Capacity), orDash(p.Claim)).Line()
+		}
+	}
+	return r
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// Эталонные значения взяты из таблиц ISO/IEC 18004 (приложения C и D, таблица E.1)
+// и разобранного примера «HELLO WORLD» 1-M; сама матрица проверяется обратным
+// декодированием по тем же правилам размещения
+
+func TestQRReedSolomonKnownVector(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := qrRSRemainder(data, qrRSDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("коды коррекции %v, ожидалось %v", got, want)
+	}
+}
+
+func TestQRAlignmentPositions(t *testing.T) {
+	cases := map[int][]int{
+		1:  nil,
+		2:  {6, 18},
+		7:  {6, 22, 38},
+		14: {6, 26, 46, 66},
+		32: {6, 34, 60, 86, 112, 138},
+		36: {6, 24, 50, 76, 102, 128, 154},
+		40: {6, 30, 58, 86, 114, 142, 170},
+	}
+	for v, want := range cases {
+		if got := qrAlignmentPositions(v); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("версия %d: %v, ожидалось %v", v, got, want)
+		}
+	}
+}
+
+func TestQRCapacity(t *testing.T) {
+	// Ёмкость байтового режима уровня M по таблице 7 стандарта
+	for _, tc := range []struct{ version, bytes int }{{1, 14}, {2, 26}, {5, 84}, {7, 122}, {10, 213}, {30, 1370}, {40, 2331}} {
+		q, err := qrEncode(make([]byte, tc.bytes))
+		if err != nil {
+			t.Fatalf("%d байт: %v", tc.bytes, err)
+		}
+		if got := (q.size - 17) / 4; got != tc.version {
+			t.Errorf("%d байт: версия %d, ожидалась %d", tc.bytes, got, tc.version)
+		}
+		if tc.version < 40 {
+			q, err := qrEncode(make([]byte, tc.bytes+1))
+			if err != nil || (q.size-17)/4 != tc.version+1 {
+				t.Errorf("%d байт должны перейти на версию %d", tc.bytes+1, tc.version+1)
+			}
+		}
+	}
+	if _, err := qrEncode(make([]byte, 2332)); !errors.Is(err, errQRTooLong) {
+		t.Errorf("2332 байта: %v", err)
+	}
+}
+
+// qrFormatM биты формата уровня M для масок 0–7 после наложения 101010000010010
+var qrFormatM = []string{
+	"101010000010010",
+	"101000100100101",
+	"101111001111100",
+	"101101101001011",
+	"100010111111001",
+	"100000011001110",
+	"100111110010111",
+	"100101010100000",
+}
+
+// qrVersionInfo биты версии из приложения D
+var qrVersionInfo = map[int]string{
+	7:  "000111110010010100",
+	8:  "001000010110111100",
+	9:  "001001101010011001",
+	10: "001010010011010011",
+	40: "101000110001101001",
+}
+
+func bitString(get func(i int) bool, n int) string {
+	var sb strings.Builder
+	for i := n - 1; i >= 0; i-- {
+		if get(i) {
+			sb.WriteByte('1')
+		} else {
+			sb.WriteByte('0')
+		}
+	}
+	return sb.String()
+}
+
+// readFormat читает обе копии битов формата: старший бит первой копии в (0, 8),
+// второй — внизу столбца 8
+func readFormat(q *qrCode) (string, string) {
+	var first, second [15]bool
+	for i := 0; i <= 5; i++ {
+		first[i] = q.modules[i][8]
+	}
+	first[6] = q.modules[7][8]
+	first[7] = q.modules[8][8]
+	first[8] = q.modules[8][7]
+	for i := 9; i < 15; i++ {
+		first[i] = q.modules[8][14-i]
+	}
+	for i := 0; i < 8; i++ {
+		second[i] = q.modules[8][q.size-1-i]
+	}
+	for i := 8; i < 15; i++ {
+		second[i] = q.modules[q.size-15+i][8]
+	}
+	return bitString(func(i int) bool { return first[i] }, 15), bitString(func(i int) bool { return second[i] }, 15)
+}
+
+func TestQRFormatBits(t *testing.T) {
+	for mask, want := range qrFormatM {
+		q := newQRCode(1)
+		q.drawFormatBits(mask)
+		first, second := readFormat(q)
+		if first != want || second != want {
+			t.Errorf("маска %d: %s / %s, ожидалось %s", mask, first, second, want)
+		}
+	}
+}
+
+func TestQRVersionBits(t *testing.T) {
+	for v, want := range qrVersionInfo {
+		q := newQRCode(v)
+		// Блок 6×3 над нижним левым поисковым узором и его отражение справа вверху
+		bottomLeft := bitString(func(i int) bool { return q.modules[q.size-11+i%3][i/3] }, 18)
+		topRight := bitString(func(i int) bool { return q.modules[i/3][q.size-11+i%3] }, 18)
+		if bottomLeft != want || topRight != want {
+			t.Errorf("версия %d: %s / %s, ожидалось %s", v, bottomLeft, topRight, want)
+		}
+	}
+}
+
+// qrDecode читает байтовые данные из матрицы, как это делает сканер:
+// формат → снятие маски → зигзаг → разбор блоков → проверка кодов коррекции
+func qrDecode(t *testing.T, q *qrCode) []byte {
+	t.Helper()
+	version := (q.size - 17) / 4
+
+	// Поисковые узоры и синхронизирующие линии
+	for _, c := range [][2]int{{0, 0}, {q.size - 7, 0}, {0, q.size - 7}} {
+		for dy := 0; dy < 7; dy++ {
+			for dx := 0; dx < 7; dx++ {
+				ring := max(abs(dx-3), abs(dy-3))
+				if q.modules[c[1]+dy][c[0]+dx] != (ring != 2) {
+					t.Fatalf("поисковый узор в (%d,%d) повреждён", c[0], c[1])
+				}
+			}
+		}
+	}
+	for i := 8; i < q.size-8; i++ {
+		if q.modules[6][i] != (i%2 == 0) || q.modules[i][6] != (i%2 == 0) {
+			t.Fatalf("синхронизирующая линия повреждена в %d", i)
+		}
+	}
+	if !q.modules[q.size-8][8] {
+		t.Fatal("нет тёмного модуля")
+	}
+
+	first, second := readFormat(q)
+	if first != second {
+		t.Fatalf("копии формата различаются: %s / %s", first, second)
+	}
+	mask := -1
+	for m, f := range qrFormatM {
+		if f == first {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("неизвестные биты формата %s", first)
+	}
+	if want, ok := qrVersionInfo[version]; ok {
+		if got := bitString(func(i int) bool { return q.modules[q.size-11+i%3][i/3] }, 18); got != want {
+			t.Fatalf("биты версии %s, ожидалось %s", got, want)
+		}
+	}
+
+	// Снимаем маску с копии и читаем биты зигзагом
+	plain := &qrCode{size: q.size, modules: make([][]bool, q.size), function: newQRCode(version).function}
+	for y := range q.modules {
+		plain.modules[y] = append([]bool(nil), q.modules[y]...)
+	}
+	plain.applyMask(mask)
+	var raw []byte
+	var cur byte
+	n := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < q.size; vert++ {
+			y := vert
+			if upward {
+				y = q.size - 1 - vert
+			}
+			for x := right; x >= right-1; x-- {
+				if plain.function[y][x] {
+					continue
+				}
+				cur = cur<<1 | boolBit(plain.modules[y][x])
+				if n++; n%8 == 0 {
+					raw = append(raw, cur)
+					cur = 0
+				}
+			}
+		}
+	}
+	total := qrRawModules(version) / 8
+	if len(raw) != total {
+		t.Fatalf("прочитано %d кодовых слов, ожидалось %d", len(raw), total)
+	}
+
+	// Обратное перемежение: сначала данные всех блоков по столбцам, затем коды коррекции
+	numBlocks, eccLen := qrBlocksM[version], qrEccPerBlockM[version]
+	numShort := numBlocks - total%numBlocks
+	shortData := total/numBlocks - eccLen
+	data := make([][]byte, numBlocks)
+	ecc := make([][]byte, numBlocks)
+	k := 0
+	for i := 0; i <= shortData; i++ {
+		for j := 0; j < numBlocks; j++ {
+			if i == shortData && j < numShort {
+				continue
+			}
+			data[j] = append(data[j], raw[k])
+			k++
+		}
+	}
+	for i := 0; i < eccLen; i++ {
+		for j := 0; j < numBlocks; j++ {
+			ecc[j] = append(ecc[j], raw[k])
+			k++
+		}
+	}
+	var stream []byte
+	for j := range data {
+		if want := qrRSRemainder(data[j], qrRSDivisor(eccLen)); !bytes.Equal(ecc[j], want) {
+			t.Fatalf("блок %d: коды коррекции не сходятся", j)
+		}
+		stream = append(stream, data[j]...)
+	}
+
+	// Байтовый режим: 0100, длина, данные
+	bit := func(i int) int { return int(stream[i/8]>>(7-i%8)) & 1 }
+	read := func(pos, n int) int {
+		v := 0
+		for i := 0; i < n; i++ {
+			v = v<<1 | bit(pos+i)
+		}
+		return v
+	}
+	if mode := read(0, 4); mode != 0x4 {
+		t.Fatalf("режим %04b вместо байтового", mode)
+	}
+	count := read(4, qrCountBits(version))
+	pos := 4 + qrCountBits(version)
+	out := make([]byte, count)
+	for i := range out {
+		out[i] = byte(read(pos+i*8, 8))
+	}
+	return out
+}
+
+func boolBit(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestQRRoundTrip(t *testing.T) {
+	conf := "[Interface]\nPrivateKey = yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk=\nAddress = 10.5.0.2/32\n\n[Peer]\nPublicKey = xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg=\nAllowedIPs = 10.4.0.0/16, 10.5.0.0/24\nEndpoint = 203.0.113.10:51820\nPersistentKeepalive = 25\n"
+	for _, data := range []string{
+		"",
+		"HELLO WORLD",
+		"https://example.com/путь?q=1",
+		strings.Repeat("0123456789", 12), // версия 7 — первая с битами версии
+		conf,
+		strings.Repeat("wg", 700),
+	} {
+		q, err := qrEncode([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := qrDecode(t, q); string(got) != data {
+			t.Errorf("версия %d: прочитано %q, ожидалось %q", (q.size-17)/4, got, data)
+		}
+	}
+}
+
+func TestQRPNG(t *testing.T) {
+	q, err := qrEncode([]byte("HELLO WORLD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := q.PNG(8, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	side := (q.size + 8) * 8
+	if b := img.Bounds(); b.Dx() != side || b.Dy() != side {
+		t.Errorf("размер %v, ожидалось %d", b, side)
+	}
+	// Тихая зона светлая, угол поискового узора тёмный
+	if r, _, _, _ := img.At(4, 4).RGBA(); r == 0 {
+		t.Error("тихая зона тёмная")
+	}
+	if r, _, _, _ := img.At(4*8+1, 4*8+1).RGBA(); r != 0 {
+		t.Error("угол поискового узора светлый")
+	}
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// renderMode режим разметки сообщений (HTML или MarkdownV2), задаётся в main
+var renderMode = tgbotapi.ModeHTML
+
+// markdownV2Special символы, которые в MarkdownV2 экранируются вне сущностей
+const markdownV2Special = "_*[]()~`>#+-=|{}.!\\"
+
+// parseRenderMode приводит значение TELEGRAM_PARSE_MODE к режиму Telegram
+func parseRenderMode(v string) string {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "markdownv2", "markdown":
+		return tgbotapi.ModeMarkdownV2
+	default:
+		return tgbotapi.ModeHTML
+	}
+}
+
+// richLine строка сообщения в размеченном и простом виде
+type richLine struct {
+	text  string
+	plain string
+	pre   bool // строка — один блок Pre
+}
+
+// Rich построитель сообщения с разметкой: все значения экранируются под выбранный режим,
+// параллельно собирается простой текст для отправки без разметки
+type Rich struct {
+	mode   string
+	lines  []richLine
+	text   strings.Builder
+	plain  strings.Builder
+	preLen int // длина текущей строки после Pre, с которого она началась
+}
+
+// NewRich создаёт построитель в текущем режиме разметки
+func NewRich() *Rich {
+	return &Rich{mode: renderMode}
+}
+
+// Text добавляет обычный текст
+func (r *Rich) Text(s string) *Rich {
+	for i, part := range strings.Split(s, "\n") {
+		if i > 0 {
+			r.Line()
+		}
+		r.text.WriteString(r.escape(part))
+		r.plain.WriteString(part)
+	}
+	return r
+}
+
+// Textf добавляет форматированный обычный текст
+func (r *Rich) Textf(format string, args ...any) *Rich {
+	return r.Text(fmt.Sprintf(format, args...))
+}
+
+// Bold добавляет жирный текст
+func (r *Rich) Bold(s string) *Rich {
+	s = inline(s)
+	if r.mode == tgbotapi.ModeHTML {
+		r.text.WriteString("<b>" + r.escape(s) + "</b>")
+	} else {
+		r.text.WriteString("*" + r.escape(s) + "*")
+	}
+	r.plain.WriteString(s)
+	return r
+}
+
+// Boldf добавляет форматированный жирный текст
+func (r *Rich) Boldf(format string, args ...any) *Rich {
+	return r.Bold(fmt.Sprintf(format, args...))
+}
+
+// Italic добавляет курсив
+func (r *Rich) Italic(s string) *Rich {
+	s = inline(s)
+	if r.mode == tgbotapi.ModeHTML {
+		r.text.WriteString("<i>" + r.escape(s) + "</i>")
+	} else {
+		r.text.WriteString("_" + r.escape(s) + "_")
+	}
+	r.plain.WriteString(s)
+	return r
+}
+
+// Code добавляет моноширинный фрагмент
+func (r *Rich) Code(s string) *Rich {
+	s = inline(s)
+	if r.mode == tgbotapi.ModeHTML {
+		r.text.WriteString("<code>" + escapeHTML(s) + "</code>")
+	} else {
+		r.text.WriteString("`" + escapeMarkdownV2Code(s) + "`")
+	}
+	r.plain.WriteString(s)
+	return r
+}
+
+// Link добавляет ссылку
+func (r *Rich) Link(label, url string) *Rich {
+	label = inline(label)
+	if r.mode == tgbotapi.ModeHTML {
+		r.text.WriteString(`<a href="` + escapeHTML(url) + `">` + escapeHTML(label) + "</a>")
+	} else {
+		target := strings.NewReplacer(`\`, `\\`, ")", `\)`).Replace(url)
+		r.text.WriteString("[" + escapeMarkdownV2(label) + "](" + target + ")")
+	}
+	r.plain.WriteString(label + " (" + url + ")")
+	return r
+}
+
+// Pre добавляет многострочный моноширинный блок; блок занимает одну «строку» построителя
+func (r *Rich) Pre(s string) *Rich {
+	start := r.text.Len() == 0
+	r.text.WriteString(r.preBlock(s))
+	r.plain.WriteString(s)
+	if start {
+		r.preLen = r.text.Len()
+	}
+	return r
+}
+
+func (r *Rich) preBlock(s string) string {
+	if r.mode == tgbotapi.ModeHTML {
+		return "<pre>" + escapeHTML(s) + "</pre>"
+	}
+	return "```\n" + escapeMarkdownV2Code(s) + "\n```"
+}
+
+// Line завершает текущую строку
+func (r *Rich) Line() *Rich {
+	r.lines = append(r.lines, r.current())
+	r.text.Reset()
+	r.plain.Reset()
+	r.preLen = 0
+	return r
+}
+
+// current незавершённая строка
+func (r *Rich) current() richLine {
+	return richLine{text: r.text.String(), plain: r.plain.String(), pre: r.preLen > 0 && r.text.Len() == r.preLen}
+}
+
+// Mode возвращает режим разметки построителя
+func (r *Rich) Mode() string {
+	return r.mode
+}
+
+// String возвращает размеченный текст
+func (r *Rich) String() string {
+	return r.join(func(l richLine) string { return l.text }, r.text.String())
+}
+
+// Plain возвращает текст без разметки
+func (r *Rich) Plain() string {
+	return r.join(func(l richLine) string { return l.plain }, r.plain.String())
+}
+
+func (r *Rich) join(field func(richLine) string, tail string) string {
+	parts := make([]string, 0, len(r.lines)+1)
+	for _, l := range r.lines {
+		parts = append(parts, field(l))
+	}
+	if tail != "" {
+		parts = append(parts, tail)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Chunks делит сообщение по границам строк на части не длиннее limit байт разметки;
+// строка длиннее limit режется на несколько
+func (r *Rich) Chunks(limit int) []*Rich {
+	lines := r.lines
+	if r.text.Len() > 0 {
+		lines = append(lines, r.current())
+	}
+
+	var chunks []*Rich
+	cur := &Rich{mode: r.mode}
+	size := 0
+	add := func(l richLine) {
+		if size > 0 && size+len(l.text)+1 > limit {
+			chunks = append(chunks, cur)
+			cur = &Rich{mode: r.mode}
+			size = 0
+		}
+		cur.lines = append(cur.lines, l)
+		size += len(l.text) + 1
+	}
+	for _, l := range lines {
+		if len(l.text) <= limit {
+			add(l)
+			continue
+		}
+		for _, part := range r.splitLine(l, limit) {
+			add(part)
+		}
+	}
+	if len(cur.lines) > 0 {
+		chunks = append(chunks, cur)
+	}
+	return chunks
+}
+
+// splitLine режет строку длиннее limit: блок Pre — на несколько блоков по границам его строк,
+// остальное — на куски простого текста, разметка такой строки теряется
+func (r *Rich) splitLine(l richLine, limit int) []richLine {
+	render := r.escape
+	if l.pre {
+		render = r.preBlock
+	}
+	// Экранирование посимвольное, поэтому длину куска можно считать по символам
+	overhead := len(render(""))
+	cost := func(c rune) int { return len(render(string(c))) - overhead }
+	var parts []richLine
+	for _, piece := range splitByCost(l.plain, limit-overhead, cost) {
+		parts = append(parts, richLine{text: render(piece), plain: piece, pre: l.pre})
+	}
+	return parts
+}
+
+// splitByCost делит текст по границам строк на части, у которых сумма cost символов не больше
+// budget; строка дороже budget режется по символам
+func splitByCost(s string, budget int, cost func(rune) int) []string {
+	var parts []string
+	var cur strings.Builder
+	size := 0
+	flush := func() {
+		if cur.Len() > 0 {
+			parts = append(parts, strings.TrimSuffix(cur.String(), "\n"))
+			cur.Reset()
+			size = 0
+		}
+	}
+	for _, line := range strings.SplitAfter(s, "\n") {
+		lineCost := 0
+		for _, c := range line {
+			lineCost += cost(c)
+		}
+		if size+lineCost > budget {
+			flush()
+		}
+		if lineCost <= budget {
+			cur.WriteString(line)
+			size += lineCost
+			continue
+		}
+		for _, c := range line {
+			if n := cost(c); size+n > budget && cur.Len() > 0 {
+				flush()
+			}
+			cur.WriteRune(c)
+			size += cost(c)
+		}
+	}
+	flush()
+	return parts
+}
+
+func (r *Rich) escape(s string) string {
+	if r.mode == tgbotapi.ModeHTML {
+		return escapeHTML(s)
+	}
+	return escapeMarkdownV2(s)
+}
+
+// inline заменяет переводы строк, чтобы сущность не выходила за пределы строки
+func inline(s string) string {
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+func escapeHTML(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
+
+func escapeMarkdownV2(s string) string {
+	var sb strings.Builder
+	for _, c := range s {
+		if strings.ContainsRune(markdownV2Special, c) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
+
+func escapeMarkdownV2Code(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "`", "\\`").Replace(s)
+}
+
+// --- Отправка сообщений ---
+
+// sendText отправляет простой текст без разметки
+func sendText(bot *tgbotapi.BotAPI, chatID int64, txt string) {
+	if _, err := bot.Send(tgbotapi.NewMessage(chatID, txt)); err != nil {
+		log.Printf("❌ Ошибка отправки сообщения в %d: %v", chatID, err)
+	}
+}
+
+// sendError показывает пользователю ошибку выполнения команды
+func sendError(bot *tgbotapi.BotAPI, chatID int64, err error) {
+	telemetry.userErrors.Add(1)
+	sendText(bot, chatID, T(langFor(chatID), "error", err))
+}
+
+// sendRich отправляет сообщение с разметкой
+func sendRich(bot *tgbotapi.BotAPI, chatID int64, r *Rich) error {
+	return sendRichMarkup(bot, chatID, r, nil)
+}
+
+// sendRichMarkup отправляет сообщение с разметкой и клавиатурой;
+// если Telegram не смог разобрать разметку, сообщение повторяется простым текстом
+func sendRichMarkup(bot *tgbotapi.BotAPI, chatID int64, r *Rich, markup interface{}) error {
+	_, err := sendRichMessage(bot, chatID, r, markup)
+	return err
+}
+
+// sendRichMessage как sendRichMarkup, но возвращает отправленное сообщение,
+// чтобы его можно было потом править
+func sendRichMessage(bot *tgbotapi.BotAPI, chatID int64, r *Rich, markup interface{}) (tgbotapi.Message, error) {
+	msg := tgbotapi.NewMessage(chatID, r.String())
+	msg.ParseMode = r.Mode()
+	msg.ReplyMarkup = markup
+	sent, err := bot.Send(msg)
+	if err == nil {
+		return sent, nil
+	}
+	if !isParseError(err) {
+		// При 429 или сетевой ошибке повтор без разметки только отправит второе сообщение
+		log.Printf("❌ Ошибка отправки сообщения в %d: %v", chatID, err)
+		return sent, err
+	}
+	log.Printf("⚠️ Telegram отклонил сообщение (%s): %v, отправляем без разметки", r.Mode(), err)
+
+	msg = tgbotapi.NewMessage(chatID, r.Plain())
+	msg.ReplyMarkup = markup
+	if sent, err = bot.Send(msg); err != nil {
+		log.Printf("❌ Ошибка отправки сообщения в %d: %v", chatID, err)
+	}
+	return sent, err
+}
+
+// isParseError сообщает, что Telegram отклонил именно разметку сообщения
+func isParseError(err error) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && strings.Contains(apiErr.Message, "can't parse entities")
+}
+
+// editRich заменяет текст и клавиатуру ранее отправленного сообщения,
+// если Telegram не смог разобрать разметку, повторяет правку простым текстом
+func editRich(bot *tgbotapi.BotAPI, chatID int64, messageID int, r *Rich, markup *tgbotapi.InlineKeyboardMarkup) {
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, r.String())
+	edit.ParseMode = r.Mode()
+	edit.ReplyMarkup = markup
+	_, err := bot.Send(edit)
+	if err == nil {
+		return
+	}
+	if !isParseError(err) {
+		log.Printf("❌ Ошибка правки сообщения %d в %d: %v", messageID, chatID, err)
+		return
+	}
+	log.Printf("⚠️ Telegram отклонил правку сообщения (%s): %v, отправляем без разметки", r.Mode(), err)
+
+	edit = tgbotapi.NewEditMessageText(chatID, messageID, r.Plain())
+	edit.ReplyMarkup = markup
+	if _, err = bot.Send(edit); err != nil {
+		log.Printf("❌ Ошибка правки сообщения %d в %d: %v", messageID, chatID, err)
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var update = flag.Bool("update", false, "перезаписать testdata/*.golden")
+
+// renderModes режимы разметки, в которых проверяются отчёты
+var renderModes = map[string]string{
+	"html": tgbotapi.ModeHTML,
+	"md":   tgbotapi.ModeMarkdownV2,
+}
+
+// withRenderMode переключает режим разметки на время теста
+func withRenderMode(t *testing.T, mode string) {
+	t.Helper()
+	prev := renderMode
+	renderMode = mode
+	t.Cleanup(func() { renderMode = prev })
+}
+
+// assertGolden сравнивает вывод с testdata/<name>.golden; go test -update перезаписывает файл
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (запустите go test -update)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s не совпадает с эталоном\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+// goldenNow момент, от которого считаются возрасты в эталонах
+var goldenNow = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+func goldenNodes() []corev1.Node {
+	node := func(name string, ready corev1.ConditionStatus, ip string, age time.Duration) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(goldenNow.Add(-age))},
+			Status: corev1.NodeStatus{
+				Capacity: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4"),
+					corev1.ResourceMemory: resource.MustParse("8Gi"),
+				},
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+				Addresses:  []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: ip}},
+				NodeInfo:   corev1.NodeSystemInfo{OperatingSystem: "linux", Architecture: "arm64"},
+			},
+		}
+	}
+	return []corev1.Node{
+		node("master-1", corev1.ConditionTrue, "10.0.0.1", 40*24*time.Hour),
+		node("worker_<2>", corev1.ConditionFalse, "10.0.0.2", 5*time.Hour),
+	}
+}
+
+func goldenPods() []corev1.Pod {
+	pod := func(name, node string, phase corev1.PodPhase, cpu, mem string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: corev1.PodSpec{
+				NodeName: node,
+				Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse(cpu),
+						corev1.ResourceMemory: resource.MustParse(mem),
+					},
+				}}},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	return []corev1.Pod{
+		pod("web-1", "master-1", corev1.PodRunning, "500m", "512Mi"),
+		pod("web-2", "master-1", corev1.PodRunning, "250m", "256Mi"),
+		pod("batch", "worker_<2>", corev1.PodFailed, "1", "1Gi"),
+	}
+}
+
+func TestRenderStatusGolden(t *testing.T) {
+	metrics := map[string]struct{ CPU, Memory int64 }{
+		"master-1":   {CPU: 1200, Memory: 3 << 30},
+		"worker_<2>": {CPU: 100, Memory: 200 << 20},
+	}
+	for suffix, mode := range renderModes {
+		withRenderMode(t, mode)
+		for _, lang := range []Lang{LangRU, LangEN} {
+			r := renderStatus(lang, alertPrefix("prod"), goldenNodes(), metrics, goldenPods(), goldenNow)
+			assertGolden(t, "status_"+string(lang)+"."+suffix, r.String())
+		}
+		// Без metrics-server показывается сумма requests и пометка об этом
+		assertGolden(t, "status_requests."+suffix, renderStatus(LangRU, "", goldenNodes(), nil, goldenPods(), goldenNow).String())
+	}
+}
+
+func goldenStatuses() map[string]*NodeStatus {
+	return map[string]*NodeStatus{
+		"master-1":   {Name: "master-1", Status: "Ready", LastSeen: goldenNow.Add(-30 * time.Second)},
+		"worker_<2>": {Name: "worker_<2>", Status: "Not Ready", LastSeen: goldenNow.Add(-75 * time.Minute), Notified: true},
+		"worker-3":   {Name: "worker-3", Status: "Maintenance", LastSeen: goldenNow.Add(-12 * time.Minute)},
+	}
+}
+
+func TestRenderMonitorStatusGolden(t *testing.T) {
+	for suffix, mode := range renderModes {
+		withRenderMode(t, mode)
+		for _, lang := range []Lang{LangRU, LangEN} {
+			assertGolden(t, "monitor_"+string(lang)+"."+suffix, renderMonitorStatus(lang, "", goldenStatuses(), goldenNow).String())
+		}
+		assertGolden(t, "monitor_empty."+suffix, renderMonitorStatus(LangRU, "", nil, goldenNow).String())
+		assertGolden(t, "alerts."+suffix, renderAlertsStatus(LangRU, "", goldenStatuses(), goldenNow).String())
+	}
+}
+
+func TestRenderNodeAlertsGolden(t *testing.T) {
+	for suffix, mode := range renderModes {
+		withRenderMode(t, mode)
+		for _, lang := range []Lang{LangRU, LangEN} {
+			alerts := []*Rich{
+				renderNodeDownAlert(lang, "prod", "worker_<2>", 65*time.Minute),
+				renderNodeRecovery(lang, "prod", "worker_<2>"),
+				renderNodeMissingAlert(lang, "prod", "worker_<2>", 12*time.Minute),
+			}
+			var out []string
+			for _, r := range alerts {
+				out = append(out, r.String())
+			}
+			assertGolden(t, "node_alerts_"+string(lang)+"."+suffix, strings.Join(out, "\n\n----\n\n"))
+		}
+	}
+}
+
+func TestRichPlainHasNoMarkup(t *testing.T) {
+	for _, mode := range renderModes {
+		withRenderMode(t, mode)
+		r := renderNodeDownAlert(LangEN, "prod", "a_b*c", time.Hour)
+		if strings.ContainsAny(r.Plain(), "<\\") || !strings.Contains(r.Plain(), "a_b*c") {
+			t.Errorf("%s: простой текст содержит разметку: %q", mode, r.Plain())
+		}
+	}
+}
+
+func TestRichChunks(t *testing.T) {
+	for suffix, mode := range renderModes {
+		withRenderMode(t, mode)
+		pre := strings.Repeat("pod-<1>_a & b\n", 40)
+		long := strings.Repeat("x_<y>", 60)
+		r := NewRich().Bold("title").Line().Pre(pre).Line().Text(long).Line().Code("tail")
+		chunks := r.Chunks(200)
+
+		var plain []string
+		for i, c := range chunks {
+			if len(c.String()) > 200 {
+				t.Errorf("%s: часть %d длиной %d больше лимита", suffix, i, len(c.String()))
+			}
+			plain = append(plain, c.Plain())
+		}
+		// Склеенные части дают исходный текст: Pre режется по его строкам, остальное по символам
+		got := strings.ReplaceAll(strings.Join(plain, "\n"), "\n", "")
+		want := strings.ReplaceAll(r.Plain(), "\n", "")
+		if got != want {
+			t.Errorf("%s: части не складываются в сообщение:\n%q\n%q", suffix, got, want)
+		}
+		for _, c := range chunks {
+			text := c.String()
+			if strings.Contains(c.Plain(), "pod-") && !strings.Contains(text, "<pre>") && !strings.Contains(text, "```") {
+				t.Errorf("%s: кусок блока Pre потерял моноширинный шрифт: %q", suffix, text)
+			}
+		}
+	}
+}
+
+// fakeTelegram поддельный Bot API: respond отвечает на sendMessage/editMessageText,
+// calls — сколько раз их вызвали
+type fakeTelegram struct {
+	mu      sync.Mutex
+	calls   int
+	texts   []string
+	respond func(call int) (int, string)
+}
+
+func newFakeTelegram(t *testing.T, respond func(call int) (int, string)) (*tgbotapi.BotAPI, *fakeTelegram) {
+	t.Helper()
+	ft := &fakeTelegram{respond: respond}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(req.URL.Path, "/getMe") {
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"username":"test_bot"}}`))
+			return
+		}
+		_ = req.ParseForm()
+		ft.mu.Lock()
+		ft.calls++
+		call := ft.calls
+		ft.texts = append(ft.texts, req.Form.Get("text"))
+		ft.mu.Unlock()
+		code, desc := 200, ""
+		if ft.respond != nil {
+			code, desc = ft.respond(call)
+		}
+		if code == 200 {
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":7,"chat":{"id":42}}}`))
+			return
+		}
+		body, _ := json.Marshal(map[string]any{"ok": false, "error_code": code, "description": desc})
+		w.WriteHeader(code)
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint("token", srv.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bot, ft
+}
+
+func TestSendRichFallback(t *testing.T) {
+	cases := []struct {
+		name      string
+		code      int
+		desc      string
+		wantCalls int
+		wantErr   bool
+	}{
+		{"ok", 200, "", 1, false},
+		{"parse error", 400, "Bad Request: can't parse entities: unexpected end tag at byte offset 3", 2, false},
+		{"rate limit", 429, "Too Many Requests: retry after 5", 1, true},
+		{"other bad request", 400, "Bad Request: chat not found", 1, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			bot, ft := newFakeTelegram(t, func(call int) (int, string) {
+				if call == 1 {
+					return tc.code, tc.desc
+				}
+				return 200, ""
+			})
+			r := NewRich().Bold("a<b").Text(" ok")
+			err := sendRich(bot, 42, r)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if ft.calls != tc.wantCalls {
+				t.Fatalf("запросов %d, ожидалось %d", ft.calls, tc.wantCalls)
+			}
+			if tc.wantCalls == 2 && ft.texts[1] != r.Plain() {
+				t.Errorf("повтор %q, ожидался простой текст %q", ft.texts[1], r.Plain())
+			}
+
+			// Правка ведёт себя так же
+			ft.calls = 0
+			editRich(bot, 42, 7, r, nil)
+			if ft.calls != tc.wantCalls {
+				t.Errorf("правка: запросов %d, ожидалось %d", ft.calls, tc.wantCalls)
+			}
+		})
+	}
+}
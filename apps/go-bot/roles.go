@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// adminCommands команды, которые меняют состояние кластера и доступны только администраторам
+var adminCommands = map[string]bool{
+	"cordon":   true,
+	"uncordon": true,
+	"drain":    true,
+	"vpn":      true, // выдаёт доступ в сеть кластера
+	"backup":   true,
+	"restore":  true,
+	"cronjob":  true,
+	"cleanup":  true,
+}
+
+// adminRole пользователи с правом на опасные команды: по id или @username
+type adminRole struct {
+	ids       map[int64]bool
+	usernames map[string]bool
+	chatID    int64 // TELEGRAM_CHAT_ID, если список пуст
+}
+
+// admins роль администратора, задаётся в main
+var admins = newAdminRole("", 0)
+
+// newAdminRole разбирает список ADMIN_USERS вида "12345, @alice"
+func newAdminRole(list string, chatID int64) *adminRole {
+	r := &adminRole{ids: make(map[int64]bool), usernames: make(map[string]bool), chatID: chatID}
+	for _, item := range strings.FieldsFunc(list, func(c rune) bool { return c == ',' || c == ' ' }) {
+		if id, err := strconv.ParseInt(item, 10, 64); err == nil {
+			r.ids[id] = true
+			continue
+		}
+		r.usernames[strings.ToLower(strings.TrimPrefix(item, "@"))] = true
+	}
+	return r
+}
+
+// Allows сообщает, является ли пользователь администратором.
+// Без списка администратором считается владелец TELEGRAM_CHAT_ID,
+// а если не задан и он, ограничений нет, как и для остальных команд
+func (r *adminRole) Allows(u *tgbotapi.User) bool {
+	if u == nil {
+		return false
+	}
+	if len(r.ids) == 0 && len(r.usernames) == 0 {
+		return r.chatID == 0 || u.ID == r.chatID
+	}
+	return r.ids[u.ID] || (u.UserName != "" && r.usernames[strings.ToLower(u.UserName)])
+}
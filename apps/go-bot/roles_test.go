@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestAdminCommands(t *testing.T) {
+	for _, cmd := range []string{"cordon", "uncordon", "drain", "vpn", "backup", "restore", "cronjob", "cleanup"} {
+		if !adminCommands[cmd] {
+			t.Errorf("/%s меняет кластер, но доступна не только администраторам", cmd)
+		}
+	}
+	role := newAdminRole("12345, @Alice", 42)
+	for _, tc := range []struct {
+		user *tgbotapi.User
+		want bool
+	}{
+		{&tgbotapi.User{ID: 12345}, true},
+		{&tgbotapi.User{ID: 7, UserName: "alice"}, true},
+		{&tgbotapi.User{ID: 42}, false},
+		{nil, false},
+	} {
+		if got := role.Allows(tc.user); got != tc.want {
+			t.Errorf("Allows(%+v) = %v", tc.user, got)
+		}
+	}
+	if !newAdminRole("", 42).Allows(&tgbotapi.User{ID: 42}) || newAdminRole("", 42).Allows(&tgbotapi.User{ID: 7}) {
+		t.Error("без ADMIN_USERS администратор — владелец TELEGRAM_CHAT_ID")
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// readyCheckTimeout таймаут проверки доступности API в /readyz
+	readyCheckTimeout = 3 * time.Second
+	// pollStaleAfter после этого времени без успешного getUpdates Telegram проверяется напрямую
+	pollStaleAfter = 3 * time.Minute
+)
+
+// StartHTTPServer поднимает /healthz, /readyz и /metrics и останавливает сервер вместе с ctx
+func StartHTTPServer(ctx context.Context, addr string, bot *tgbotapi.BotAPI) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		status, body := readiness(r.Context(), bot)
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		telemetry.WriteMetrics(w)
+	})
+
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("🌐 HTTP-сервер health/metrics на %s", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("❌ HTTP-сервер остановлен: %v", err)
+	}
+}
+
+// readiness проверяет Telegram и API-серверы кластеров; готовность зависит от Telegram
+// и основного кластера, недоступность остальных только отражается в ответе
+func readiness(ctx context.Context, bot *tgbotapi.BotAPI) (int, string) {
+	ctx, cancel := context.WithTimeout(ctx, readyCheckTimeout)
+	defer cancel()
+
+	ready := true
+	var sb strings.Builder
+
+	if time.Since(telemetry.LastPoll()) < pollStaleAfter {
+		sb.WriteString("telegram: ok\n")
+	} else if _, err := bot.GetMe(); err != nil {
+		ready = false
+		fmt.Fprintf(&sb, "telegram: %v\n", err)
+	} else {
+		sb.WriteString("telegram: ok\n")
+	}
+
+	for i, c := range clusters.All() {
+		err := c.Typed.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Error()
+		if err != nil {
+			fmt.Fprintf(&sb, "cluster %s: %v\n", c.Name, err)
+			if i == 0 {
+				ready = false
+			}
+			continue
+		}
+		fmt.Fprintf(&sb, "cluster %s: ok\n", c.Name)
+	}
+
+	if !ready {
+		return http.StatusServiceUnavailable, sb.String()
+	}
+	return http.StatusOK, sb.String()
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultBuckets границы гистограмм длительности в секундах
+var defaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Telemetry собственные метрики бота в формате Prometheus
+type Telemetry struct {
+	Commands           *counterVec   // команды по имени и результату
+	CommandDuration    *histogramVec // время обработки команды
+	APIRequests        *histogramVec // запросы к Kubernetes, Telegram, Prometheus и Loki
+	Notifications      *counterVec   // уведомления монитора
+	ActiveAlerts       *gaugeVec     // узлы, по которым отправлен алерт и нет восстановления
+	MonitorLastSuccess *gaugeVec     // время последней успешной проверки монитора
+
+	// userErrors ошибки, показанные пользователю; по ним main отличает неудачные команды
+	userErrors atomic.Int64
+	// lastPoll время последнего успешного getUpdates, unix-секунды
+	lastPoll atomic.Int64
+}
+
+// telemetry метрики процесса
+var telemetry = &Telemetry{
+	Commands:           newCounterVec("gobot_commands_total", "Обработанные команды по имени и результату.", "command", "result"),
+	CommandDuration:    newHistogramVec("gobot_command_duration_seconds", "Время обработки команды.", defaultBuckets, "command"),
+	APIRequests:        newHistogramVec("gobot_api_request_duration_seconds", "Длительность запросов к внешним API.", defaultBuckets, "api", "cluster", "code"),
+	Notifications:      newCounterVec("gobot_notifications_total", "Уведомления монитора по виду и результату отправки.", "kind", "result"),
+	ActiveAlerts:       newGaugeVec("gobot_active_alerts", "Узлы с активным алертом.", "cluster"),
+	MonitorLastSuccess: newGaugeVec("gobot_monitor_last_success_timestamp_seconds", "Время последней успешной проверки узлов.", "cluster"),
+}
+
+// WriteMetrics выводит все метрики в текстовом формате Prometheus
+func (t *Telemetry) WriteMetrics(w io.Writer) {
+	t.Commands.write(w)
+	t.CommandDuration.write(w)
+	t.APIRequests.write(w)
+	t.Notifications.write(w)
+	t.ActiveAlerts.write(w)
+	t.MonitorLastSuccess.write(w)
+	fmt.Fprintln(w, "# HELP gobot_telegram_last_poll_timestamp_seconds Время последнего успешного получения обновлений Telegram.")
+	fmt.Fprintln(w, "# TYPE gobot_telegram_last_poll_timestamp_seconds gauge")
+	fmt.Fprintf(w, "gobot_telegram_last_poll_timestamp_seconds %d\n", t.lastPoll.Load())
+}
+
+// LastPoll время последнего успешного getUpdates
+func (t *Telemetry) LastPoll() time.Time {
+	if v := t.lastPoll.Load(); v != 0 {
+		return time.Unix(v, 0)
+	}
+	return time.Time{}
+}
+
+// metricVec общая часть метрик с метками
+type metricVec struct {
+	mu     sync.Mutex
+	name   string
+	help   string
+	labels []string
+}
+
+// key склеивает значения меток в ключ и готовую запись {a="x",b="y"}
+func (m *metricVec) key(values []string) string {
+	if len(values) != len(m.labels) {
+		panic(fmt.Sprintf("%s: ожидается %d меток, передано %d", m.name, len(m.labels), len(values)))
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = m.labels[i] + "=" + strconv.Quote(v)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func (m *metricVec) header(w io.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, kind)
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// counterVec счётчик с метками
+type counterVec struct {
+	metricVec
+	values map[string]float64
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{metricVec: metricVec{name: name, help: help, labels: labels}, values: make(map[string]float64)}
+}
+
+// Inc увеличивает счётчик для значений меток
+func (c *counterVec) Inc(labels ...string) {
+	key := c.key(labels)
+	c.mu.Lock()
+	c.values[key]++
+	c.mu.Unlock()
+}
+
+func (c *counterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.header(w, "counter")
+	for _, k := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %g\n", c.name, k, c.values[k])
+	}
+}
+
+// gaugeVec текущее значение с метками
+type gaugeVec struct {
+	metricVec
+	values map[string]float64
+}
+
+func newGaugeVec(name, help string, labels ...string) *gaugeVec {
+	return &gaugeVec{metricVec: metricVec{name: name, help: help, labels: labels}, values: make(map[string]float64)}
+}
+
+// Set задаёт значение для значений меток
+func (g *gaugeVec) Set(v float64, labels ...string) {
+	key := g.key(labels)
+	g.mu.Lock()
+	g.values[key] = v
+	g.mu.Unlock()
+}
+
+func (g *gaugeVec) write(w io.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.header(w, "gauge")
+	for _, k := range sortedKeys(g.values) {
+		fmt.Fprintf(w, "%s%s %g\n", g.name, k, g.values[k])
+	}
+}
+
+// histogramVec гистограмма с метками
+type histogramVec struct {
+	metricVec
+	buckets []float64
+	series  map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64 // по бакетам, не накопительно
+	sum    float64
+	count  uint64
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{
+		metricVec: metricVec{name: name, help: help, labels: labels},
+		buckets:   buckets,
+		series:    make(map[string]*histogram),
+	}
+}
+
+// Observe добавляет значение в гистограмму
+func (h *histogramVec) Observe(v float64, labels ...string) {
+	key := h.key(labels)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, b := range h.buckets {
+		if v <= b {
+			s.counts[i]++
+			break
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+func (h *histogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.header(w, "histogram")
+	for _, k := range sortedKeys(h.series) {
+		s := h.series[k]
+		// le добавляется к остальным меткам
+		prefix := "{"
+		if k != "" {
+			prefix = strings.TrimSuffix(k, "}") + ","
+		}
+		var cumulative uint64
+		for i, b := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%sle=\"%g\"} %d\n", h.name, prefix, b, cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%sle=\"+Inf\"} %d\n", h.name, prefix, s.count)
+		fmt.Fprintf(w, "%s_sum%s %g\n", h.name, k, s.sum)
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, k, s.count)
+	}
+}
+
+// instrumentedTransport замеряет длительность и коды ответов внешних API
+type instrumentedTransport struct {
+	next    http.RoundTripper
+	api     string
+	cluster string
+}
+
+// instrumentTransport оборачивает транспорт; nil означает http.DefaultTransport
+func instrumentTransport(next http.RoundTripper, api, cluster string) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &instrumentedTransport{next: next, api: api, cluster: cluster}
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	// getUpdates — long polling, его длительность ничего не говорит о задержках API
+	if t.api == "telegram" && strings.HasSuffix(req.URL.Path, "/getUpdates") {
+		if err == nil && resp.StatusCode == http.StatusOK {
+			telemetry.lastPoll.Store(time.Now().Unix())
+		}
+		return resp, err
+	}
+	telemetry.APIRequests.Observe(time.Since(start).Seconds(), t.api, t.cluster, code)
+	return resp, err
+}
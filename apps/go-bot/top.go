@@ -0,0 +1,327 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+const (
+	defaultTopLimit = 15
+	// memLimitWarnPercent доля лимита памяти, после которой pod помечается как близкий к OOM
+	memLimitWarnPercent = 90
+)
+
+// topRequest разобранные аргументы /top
+type topRequest struct {
+	Mode      string // pods или ns
+	Namespace string
+	Sort      string // cpu или mem
+	Limit     int
+}
+
+// podUsage фактическое потребление pod-а вместе с суммой requests/limits контейнеров
+type podUsage struct {
+	Namespace string
+	Name      string
+	HasUsage  bool
+	CPU       int64 // milliCPU
+	Memory    int64 // байты
+	CPUReq    int64
+	CPULim    int64
+	MemReq    int64
+	MemLim    int64
+}
+
+// parseTopArgs разбирает /top pods [ns] [--sort cpu|mem] [--limit N] и /top ns
+func parseTopArgs(args string) (topRequest, error) {
+	req := topRequest{Mode: "pods", Sort: "cpu", Limit: defaultTopLimit}
+	parts := strings.Fields(args)
+	if len(parts) > 0 {
+		switch parts[0] {
+		case "pods", "pod", "po":
+			req.Mode = "pods"
+		case "ns", "namespaces", "namespace":
+			req.Mode = "ns"
+		default:
+			return req, fmt.Errorf("неизвестный режим: %s", parts[0])
+		}
+		parts = parts[1:]
+	}
+
+	for i := 0; i < len(parts); i++ {
+		p := parts[i]
+		switch {
+		case p == "--sort" && i+1 < len(parts):
+			i++
+			req.Sort = parts[i]
+		case strings.HasPrefix(p, "--sort="):
+			req.Sort = strings.TrimPrefix(p, "--sort=")
+		case p == "--limit" && i+1 < len(parts):
+			i++
+			n, err := strconv.Atoi(parts[i])
+			if err != nil || n <= 0 {
+				return req, fmt.Errorf("некорректный --limit: %s", parts[i])
+			}
+			req.Limit = n
+		case strings.HasPrefix(p, "--limit="):
+			n, err := strconv.Atoi(strings.TrimPrefix(p, "--limit="))
+			if err != nil || n <= 0 {
+				return req, fmt.Errorf("некорректный %s", p)
+			}
+			req.Limit = n
+		case strings.HasPrefix(p, "-"):
+			return req, fmt.Errorf("неизвестный флаг: %s", p)
+		case req.Namespace == "" && req.Mode == "pods":
+			if p != "all" {
+				req.Namespace = p
+			}
+		default:
+			return req, fmt.Errorf("лишний аргумент: %s", p)
+		}
+	}
+
+	switch req.Sort {
+	case "cpu":
+	case "mem", "memory":
+		req.Sort = "mem"
+	default:
+		return req, fmt.Errorf("сортировка только по cpu или mem")
+	}
+	return req, nil
+}
+
+// handleTop показывает потребление pod-ов или namespace относительно requests/limits
+func handleTop(bot *tgbotapi.BotAPI, cluster *Cluster, ctx context.Context, chatID int64, req topRequest) {
+	lang := langFor(chatID)
+	if !cluster.HasMetrics() {
+		sendText(bot, chatID, T(lang, "top.no_metrics", fmt.Sprintf("API %s не зарегистрирован", metricsAPI)))
+		return
+	}
+
+	usage, err := collectPodUsage(ctx, cluster.Typed, cluster.Metrics, req.Namespace)
+	if err != nil {
+		if isMetricsUnavailable(err) {
+			sendText(bot, chatID, T(lang, "top.no_metrics", err))
+			return
+		}
+		sendError(bot, chatID, err)
+		return
+	}
+
+	if req.Mode == "ns" {
+		sendLongRich(bot, chatID, "top-ns", renderTopNamespaces(lang, usage, req))
+		return
+	}
+	sendLongRich(bot, chatID, "top-pods", renderTopPods(lang, usage, req))
+}
+
+// collectPodUsage объединяет PodMetrics с requests/limits из спецификаций запущенных pod-ов
+func collectPodUsage(ctx context.Context, clientset kubernetes.Interface, metricsClient metricsv.Interface, ns string) ([]podUsage, error) {
+	podMetrics, err := metricsClient.MetricsV1beta1().PodMetricses(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods, err := clientset.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	byPod := make(map[string]*podUsage)
+	var result []*podUsage
+	for _, p := range pods.Items {
+		if p.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		u := &podUsage{Namespace: p.Namespace, Name: p.Name}
+		for _, c := range p.Spec.Containers {
+			u.CPUReq += c.Resources.Requests.Cpu().MilliValue()
+			u.CPULim += c.Resources.Limits.Cpu().MilliValue()
+			u.MemReq += c.Resources.Requests.Memory().Value()
+			u.MemLim += c.Resources.Limits.Memory().Value()
+		}
+		byPod[p.Namespace+"/"+p.Name] = u
+		result = append(result, u)
+	}
+	for _, m := range podMetrics.Items {
+		u, ok := byPod[m.Namespace+"/"+m.Name]
+		if !ok {
+			continue
+		}
+		u.HasUsage = true
+		for _, c := range m.Containers {
+			u.CPU += c.Usage.Cpu().MilliValue()
+			u.Memory += c.Usage.Memory().Value()
+		}
+	}
+
+	out := make([]podUsage, 0, len(result))
+	for _, u := range result {
+		out = append(out, *u)
+	}
+	return out, nil
+}
+
+// isMetricsUnavailable определяет, что metrics.k8s.io не зарегистрирован или не отвечает
+func isMetricsUnavailable(err error) bool {
+	return apierrors.IsNotFound(err) || apierrors.IsServiceUnavailable(err) ||
+		strings.Contains(err.Error(), "the server could not find the requested resource")
+}
+
+// usageFlags отметки проблемных pod-ов: превышение requests и приближение к лимиту памяти
+func usageFlags(u podUsage) string {
+	var flags []string
+	if u.CPUReq > 0 && u.CPU > u.CPUReq {
+		flags = append(flags, "CPU>REQ")
+	}
+	if u.MemReq > 0 && u.Memory > u.MemReq {
+		flags = append(flags, "MEM>REQ")
+	}
+	if u.MemLim > 0 && u.Memory*100 >= u.MemLim*memLimitWarnPercent {
+		flags = append(flags, "MEM~LIM")
+	}
+	return strings.Join(flags, ",")
+}
+
+// renderTopPods формирует таблицу /top pods
+func renderTopPods(lang Lang, usage []podUsage, req topRequest) *Rich {
+	scope := req.Namespace
+	if scope == "" {
+		scope = "all"
+	}
+	var rows []podUsage
+	for _, u := range usage {
+		if u.HasUsage {
+			rows = append(rows, u)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if req.Sort == "mem" {
+			return rows[i].Memory > rows[j].Memory
+		}
+		return rows[i].CPU > rows[j].CPU
+	})
+	total := len(rows)
+	if len(rows) > req.Limit {
+		rows = rows[:req.Limit]
+	}
+
+	r := NewRich()
+	r.Text("📈 ").Bold(T(lang, "top.pods_title", scope, req.Sort)).Line()
+	if len(rows) == 0 {
+		return r.Text(T(lang, "top.none"))
+	}
+	r.Text(T(lang, "top.shown", len(rows), total)).Line()
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
+	withNamespace := req.Namespace == ""
+	if withNamespace {
+		fmt.Fprint(w, "NAMESPACE\t")
+	}
+	fmt.Fprintln(w, "POD\tCPU use/req/lim\tMEM use/req/lim\tFLAGS")
+	for _, u := range rows {
+		if withNamespace {
+			fmt.Fprintf(w, "%s\t", u.Namespace)
+		}
+		fmt.Fprintf(w, "%s\t%s/%s/%s\t%s/%s/%s\t%s\n", u.Name,
+			shortCPU(u.CPU), shortCPU(u.CPUReq), shortCPU(u.CPULim),
+			shortMem(u.Memory), shortMem(u.MemReq), shortMem(u.MemLim),
+			orDash(usageFlags(u)))
+	}
+	w.Flush()
+	r.Pre(strings.TrimRight(sb.String(), "\n")).Line()
+	r.Italic(T(lang, "top.legend", memLimitWarnPercent))
+	return r
+}
+
+// renderTopNamespaces формирует таблицу /top ns
+func renderTopNamespaces(lang Lang, usage []podUsage, req topRequest) *Rich {
+	type nsUsage struct {
+		podUsage
+		Pods    int
+		Flagged int
+	}
+	byNS := make(map[string]*nsUsage)
+	for _, u := range usage {
+		n, ok := byNS[u.Namespace]
+		if !ok {
+			n = &nsUsage{podUsage: podUsage{Namespace: u.Namespace}}
+			byNS[u.Namespace] = n
+		}
+		n.Pods++
+		n.CPU += u.CPU
+		n.Memory += u.Memory
+		n.CPUReq += u.CPUReq
+		n.CPULim += u.CPULim
+		n.MemReq += u.MemReq
+		n.MemLim += u.MemLim
+		if usageFlags(u) != "" {
+			n.Flagged++
+		}
+	}
+	rows := make([]*nsUsage, 0, len(byNS))
+	for _, n := range byNS {
+		rows = append(rows, n)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if req.Sort == "mem" {
+			return rows[i].Memory > rows[j].Memory
+		}
+		return rows[i].CPU > rows[j].CPU
+	})
+
+	r := NewRich()
+	r.Text("📈 ").Bold(T(lang, "top.ns_title", req.Sort)).Line()
+	if len(rows) == 0 {
+		return r.Text(T(lang, "top.none"))
+	}
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tPODS\tCPU use/req/lim\tMEM use/req/lim\tFLAGGED")
+	for _, n := range rows {
+		fmt.Fprintf(w, "%s\t%d\t%s/%s/%s\t%s/%s/%s\t%d\n", n.Namespace, n.Pods,
+			shortCPU(n.CPU), shortCPU(n.CPUReq), shortCPU(n.CPULim),
+			shortMem(n.Memory), shortMem(n.MemReq), shortMem(n.MemLim),
+			n.Flagged)
+	}
+	w.Flush()
+	r.Pre(strings.TrimRight(sb.String(), "\n"))
+	return r
+}
+
+// shortCPU компактный вывод milliCPU для таблиц: 250m, 1.5
+func shortCPU(milli int64) string {
+	if milli == 0 {
+		return "-"
+	}
+	if milli >= 1000 {
+		return strconv.FormatFloat(float64(milli)/1000, 'f', 1, 64)
+	}
+	return fmt.Sprintf("%dm", milli)
+}
+
+// shortMem компактный вывод памяти для таблиц: 64Mi, 1.2Gi
+func shortMem(bytes int64) string {
+	const Mi = 1024 * 1024
+	const Gi = 1024 * Mi
+	switch {
+	case bytes == 0:
+		return "-"
+	case bytes >= Gi:
+		return strconv.FormatFloat(float64(bytes)/Gi, 'f', 1, 64) + "Gi"
+	default:
+		return fmt.Sprintf("%dMi", bytes/Mi)
+	}
+}
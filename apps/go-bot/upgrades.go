@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	upgradeAPI = "upgrade.cattle.io/v1"
+	// Метки, которые system-upgrade-controller ставит на Job-ы
+	upgradeLabelPlan    = "upgrade.cattle.io/plan"
+	upgradeLabelNode    = "upgrade.cattle.io/node"
+	upgradeLabelVersion = "upgrade.cattle.io/version"
+	// upgradePlanLabelPrefix метка узла с хешем применённого плана
+	upgradePlanLabelPrefix = "plan.upgrade.cattle.io/"
+)
+
+var upgradePlanGVR = schema.GroupVersionResource{Group: "upgrade.cattle.io", Version: "v1", Resource: "plans"}
+
+// upgradingNodes узлы всех кластеров, на которых идёт обновление
+var upgradingNodes = newNodeSilences()
+
+// upgradeJob обновление одного узла по плану
+type upgradeJob struct {
+	Plan       string
+	Node       string
+	Target     string
+	OldKubelet string // пусто, если Job начался до запуска бота
+	Finished   bool
+}
+
+// upgradePlan план system-upgrade-controller
+type upgradePlan struct {
+	Name          string
+	LatestVersion string
+	LatestHash    string
+	Selector      labels.Selector
+}
+
+// UpgradeTracker сообщает о ходе обновления k3s по Plan-ам system-upgrade-controller
+type UpgradeTracker struct {
+	cluster *Cluster
+	cfg     UpgradeConfig
+	bot     *tgbotapi.BotAPI
+	adminID int64
+
+	primed bool
+	jobs   map[string]*upgradeJob // по имени Job
+}
+
+// NewUpgradeTracker создаёт наблюдателя за обновлениями кластера
+func NewUpgradeTracker(cluster *Cluster, cfg UpgradeConfig, bot *tgbotapi.BotAPI, adminID int64) *UpgradeTracker {
+	return &UpgradeTracker{cluster: cluster, cfg: cfg, bot: bot, adminID: adminID, jobs: make(map[string]*upgradeJob)}
+}
+
+// Start проверяет Job-ы обновления сразу и затем каждые PollInterval
+func (t *UpgradeTracker) Start(ctx context.Context) {
+	ticker := time.NewTicker(t.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		t.check(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check сопоставляет Job-ы обновления с прошлым проходом; без system-upgrade-controller ничего не делает
+func (t *UpgradeTracker) check(ctx context.Context, now time.Time) {
+	if !t.cluster.HasAPI(upgradeAPI) {
+		return
+	}
+	plans, err := t.listPlans(ctx)
+	if err != nil {
+		log.Printf("❌ [%s] Ошибка получения планов обновления: %v", t.cluster.Name, err)
+		return
+	}
+	jobs, err := t.cluster.Typed.BatchV1().Jobs(t.cfg.Namespace).List(ctx, metav1.ListOptions{LabelSelector: upgradeLabelPlan})
+	if err != nil {
+		log.Printf("❌ [%s] Ошибка получения Job-ов обновления: %v", t.cluster.Name, err)
+		return
+	}
+	lang := langFor(t.adminID)
+	present := make(map[string]bool, len(jobs.Items))
+
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		present[job.Name] = true
+		node := job.Labels[upgradeLabelNode]
+		finished, failed := jobFinished(job)
+
+		state, known := t.jobs[job.Name]
+		if !known {
+			state = &upgradeJob{Plan: job.Labels[upgradeLabelPlan], Node: node, Target: job.Labels[upgradeLabelVersion]}
+			if p, ok := plans[state.Plan]; ok && p.LatestVersion != "" {
+				state.Target = p.LatestVersion
+			}
+			// Версия до обновления известна, только если Job появился при работающем боте:
+			// у запущенного раньше Job-а kubelet мог уже обновиться
+			if t.primed {
+				state.OldKubelet = t.kubeletVersion(ctx, node)
+			}
+			t.jobs[job.Name] = state
+			// Завершённые до запуска бота Job-ы только запоминаем
+			if finished && !t.primed {
+				state.Finished = true
+				continue
+			}
+			if !finished {
+				t.notify("upgrade_started", renderUpgradeStarted(lang, t.cluster.Name, *state))
+				log.Printf("🔔 [%s] Обновление узла %s по плану %s началось", t.cluster.Name, node, state.Plan)
+			}
+		}
+
+		if !finished {
+			upgradingNodes.Hold(t.cluster.Name, node, now.Add(t.cfg.SuppressGrace))
+			continue
+		}
+		if state.Finished {
+			continue
+		}
+		state.Finished = true
+		// Узел ещё может перезапускаться после завершения Job
+		upgradingNodes.Hold(t.cluster.Name, node, now.Add(t.cfg.SuppressGrace))
+
+		if failed {
+			t.notify("upgrade_failed", renderUpgradeFailed(lang, t.cluster.Name, *state, job.Name, jobFailureMessage(job)))
+			log.Printf("🔔 [%s] Обновление узла %s по плану %s не удалось", t.cluster.Name, node, state.Plan)
+			if logs := t.jobLogs(ctx, job.Name); logs != "" {
+				sendLong(t.bot, t.adminID, "upgrade-"+node, logs)
+			}
+			continue
+		}
+		done, total := t.planProgress(ctx, plans[state.Plan])
+		t.notify("upgrade_completed", renderUpgradeCompleted(lang, t.cluster.Name, *state, t.kubeletVersion(ctx, node), done, total))
+		log.Printf("🔔 [%s] Узел %s обновлён по плану %s (%d/%d)", t.cluster.Name, node, state.Plan, done, total)
+	}
+	t.primed = true
+
+	// Удалённые Job-ы больше не нужно помнить
+	for name := range t.jobs {
+		if !present[name] {
+			delete(t.jobs, name)
+		}
+	}
+}
+
+func (t *UpgradeTracker) notify(kind string, r *Rich) {
+	result := "sent"
+	if err := sendRich(t.bot, t.adminID, r); err != nil {
+		result = "failed"
+	}
+	telemetry.Notifications.Inc(kind, result)
+}
+
+func (t *UpgradeTracker) listPlans(ctx context.Context) (map[string]upgradePlan, error) {
+	list, err := t.cluster.Dynamic.Resource(upgradePlanGVR).Namespace(t.cfg.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	plans := make(map[string]upgradePlan, len(list.Items))
+	for _, item := range list.Items {
+		p := upgradePlan{Name: item.GetName(), Selector: labels.Everything()}
+		p.LatestVersion, _, _ = unstructured.NestedString(item.Object, "status", "latestVersion")
+		p.LatestHash, _, _ = unstructured.NestedString(item.Object, "status", "latestHash")
+		if raw, ok, _ := unstructured.NestedMap(item.Object, "spec", "nodeSelector"); ok {
+			var sel metav1.LabelSelector
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &sel); err == nil {
+				if s, err := metav1.LabelSelectorAsSelector(&sel); err == nil {
+					p.Selector = s
+				}
+			}
+		}
+		plans[p.Name] = p
+	}
+	return plans, nil
+}
+
+// planProgress сколько узлов плана уже с последним хешем; узел получает метку после успешного Job
+func (t *UpgradeTracker) planProgress(ctx context.Context, p upgradePlan) (done, total int) {
+	if p.Name == "" {
+		return 0, 0
+	}
+	nodes, err := t.cluster.Typed.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: p.Selector.String()})
+	if err != nil {
+		log.Printf("⚠️ [%s] Не удалось посчитать прогресс плана %s: %v", t.cluster.Name, p.Name, err)
+		return 0, 0
+	}
+	for _, n := range nodes.Items {
+		if p.LatestHash != "" && n.Labels[upgradePlanLabelPrefix+p.Name] == p.LatestHash {
+			done++
+		}
+	}
+	return done, len(nodes.Items)
+}
+
+func (t *UpgradeTracker) kubeletVersion(ctx context.Context, node string) string {
+	if node == "" {
+		return ""
+	}
+	n, err := t.cluster.Typed.CoreV1().Nodes().Get(ctx, node, metav1.GetOptions{})
+	if err != nil {
+		return ""
+	}
+	return n.Status.NodeInfo.KubeletVersion
+}
+
+// jobLogs хвост логов упавшего контейнера последнего pod-а Job
+func (t *UpgradeTracker) jobLogs(ctx context.Context, job string) string {
+	logs, err := jobPodLogs(ctx, t.cluster.Typed, t.cfg.Namespace, job, t.cfg.LogTail)
+	if err != nil {
+		log.Printf("⚠️ [%s] Не удалось получить логи Job %s: %v", t.cluster.Name, job, err)
+	}
+	return logs
+}
+
+// failedContainer контейнер, на котором остановился pod: init-контейнеры prepare/drain или upgrade
+func failedContainer(pod corev1.Pod) string {
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if term := cs.State.Terminated; term != nil && term.ExitCode != 0 {
+			return cs.Name
+		}
+		if term := cs.LastTerminationState.Terminated; term != nil && term.ExitCode != 0 {
+			return cs.Name
+		}
+	}
+	if len(pod.Spec.Containers) > 0 {
+		return pod.Spec.Containers[0].Name
+	}
+	return ""
+}
+
+// jobFinished завершён ли Job и с ошибкой ли
+func jobFinished(job *batchv1.Job) (finished, failed bool) {
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return true, false
+		case batchv1.JobFailed:
+			return true, true
+		}
+	}
+	return false, false
+}
+
+func jobFailureMessage(job *batchv1.Job) string {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			if c.Message != "" {
+				return c.Reason + ": " + c.Message
+			}
+			return c.Reason
+		}
+	}
+	return ""
+}
+
+// formatKubeletChange старая → новая версия; одинаковые или неизвестные версии показываются одной
+func formatKubeletChange(from, to string) string {
+	switch {
+	case from == "" || from == to:
+		return orDash(to)
+	case to == "":
+		return from
+	default:
+		return from + " → " + to
+	}
+}
+
+// renderUpgradeStarted уведомление о начале обновления узла
+func renderUpgradeStarted(lang Lang, cluster string, j upgradeJob) *Rich {
+	return NewRich().
+		Text("⬆️ " + alertPrefix(cluster)).Bold(T(lang, "upgrade.started.title")).Line().Line().
+		Text("🔧 ").Bold(T(lang, "alert.node")).Text(" ").Code(j.Node).Line().
+		Text("📋 ").Bold(T(lang, "upgrade.plan")).Text(" " + j.Plan).Line().
+		Text("📦 ").Bold(T(lang, "upgrade.kubelet")).Text(" " + formatKubeletChange(j.OldKubelet, j.Target)).Line().Line().
+		Text(T(lang, "upgrade.suppressed"))
+}
+
+// renderUpgradeCompleted уведомление об обновлённом узле с прогрессом плана
+func renderUpgradeCompleted(lang Lang, cluster string, j upgradeJob, newKubelet string, done, total int) *Rich {
+	r := NewRich().
+		Text("✅ " + alertPrefix(cluster)).Bold(T(lang, "upgrade.completed.title")).Line().Line().
+		Text("🔧 ").Bold(T(lang, "alert.node")).Text(" ").Code(j.Node).Line().
+		Text("📋 ").Bold(T(lang, "upgrade.plan")).Text(" " + j.Plan).Line().
+		Text("📦 ").Bold(T(lang, "upgrade.kubelet")).Text(" " + formatKubeletChange(j.OldKubelet, newKubelet)).Line()
+	if total > 0 {
+		r.Text("📊 ").Bold(T(lang, "upgrade.progress")).Textf(" %d/%d", done, total).Line()
+		if done == total {
+			r.Line().Text(T(lang, "upgrade.plan_done", j.Plan))
+		}
+	}
+	return r
+}
+
+// renderUpgradeFailed уведомление о неудачном Job обновления; логи отправляются отдельно
+func renderUpgradeFailed(lang Lang, cluster string, j upgradeJob, job, reason string) *Rich {
+	r := NewRich().
+		Text("❌ " + alertPrefix(cluster)).Bold(T(lang, "upgrade.failed.title")).Line().Line().
+		Text("🔧 ").Bold(T(lang, "alert.node")).Text(" ").Code(j.Node).Line().
+		Text("📋 ").Bold(T(lang, "upgrade.plan")).Text(" " + j.Plan).Line().
+		Text("📦 ").Bold(T(lang, "upgrade.kubelet")).Text(" " + formatKubeletChange(j.OldKubelet, j.Target)).Line().
+		Text("⚙️ Job: ").Code(job).Line()
+	if reason != "" {
+		r.Text(T(lang, "alert.reason", reason)).Line()
+	}
+	return r
+}
@@ -0,0 +1,456 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	veleroAPI = "velero.io/v1"
+	// backupsDefault и backupsMax число строк /backups
+	backupsDefault = 10
+	backupsMax     = 50
+)
+
+var (
+	veleroBackupGVR   = schema.GroupVersionResource{Group: "velero.io", Version: "v1", Resource: "backups"}
+	veleroScheduleGVR = schema.GroupVersionResource{Group: "velero.io", Version: "v1", Resource: "schedules"}
+	veleroRestoreGVR  = schema.GroupVersionResource{Group: "velero.io", Version: "v1", Resource: "restores"}
+)
+
+// veleroBackup поля Backup, нужные боту
+type veleroBackup struct {
+	Name          string
+	Phase         string
+	Schedule      string // расписание, создавшее бэкап
+	Namespaces    []string
+	Started       time.Time // время создания объекта
+	Completed     time.Time
+	Errors        int64
+	Warnings      int64
+	FailureReason string
+}
+
+// backupFailed фазы, о которых нужно сообщить администратору
+func backupFailed(phase string) bool {
+	return phase == "Failed" || phase == "PartiallyFailed" || phase == "FailedValidation"
+}
+
+// backupFinished бэкап больше не изменится
+func backupFinished(phase string) bool {
+	return phase == "Completed" || backupFailed(phase)
+}
+
+func parseVeleroBackup(obj unstructured.Unstructured) veleroBackup {
+	b := veleroBackup{
+		Name:     obj.GetName(),
+		Schedule: obj.GetLabels()["velero.io/schedule-name"],
+		Started:  obj.GetCreationTimestamp().Time,
+	}
+	b.Phase, _, _ = unstructured.NestedString(obj.Object, "status", "phase")
+	b.Namespaces, _, _ = unstructured.NestedStringSlice(obj.Object, "spec", "includedNamespaces")
+	if v, _, _ := unstructured.NestedString(obj.Object, "status", "completionTimestamp"); v != "" {
+		b.Completed, _ = time.Parse(time.RFC3339, v)
+	}
+	b.Errors, _, _ = unstructured.NestedInt64(obj.Object, "status", "errors")
+	b.Warnings, _, _ = unstructured.NestedInt64(obj.Object, "status", "warnings")
+	b.FailureReason, _, _ = unstructured.NestedString(obj.Object, "status", "failureReason")
+	return b
+}
+
+// listBackups бэкапы всех namespace, от новых к старым
+func listBackups(ctx context.Context, cluster *Cluster) ([]veleroBackup, error) {
+	list, err := cluster.Dynamic.Resource(veleroBackupGVR).Namespace("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	backups := make([]veleroBackup, 0, len(list.Items))
+	for _, item := range list.Items {
+		backups = append(backups, parseVeleroBackup(item))
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].Started.Equal(backups[j].Started) {
+			return backups[i].Started.After(backups[j].Started)
+		}
+		return backups[i].Name < backups[j].Name
+	})
+	return backups, nil
+}
+
+// latestBackup последний по времени создания бэкап; nil, если бэкапов нет
+func latestBackup(ctx context.Context, cluster *Cluster) (*veleroBackup, error) {
+	backups, err := listBackups(ctx, cluster)
+	if err != nil || len(backups) == 0 {
+		return nil, err
+	}
+	return &backups[0], nil
+}
+
+// veleroSchedule расписание Velero
+type veleroSchedule struct {
+	Name       string
+	Spec       string
+	Paused     bool
+	LastBackup time.Time
+	Created    time.Time
+}
+
+func listSchedules(ctx context.Context, cluster *Cluster) ([]veleroSchedule, error) {
+	list, err := cluster.Dynamic.Resource(veleroScheduleGVR).Namespace("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var schedules []veleroSchedule
+	for _, item := range list.Items {
+		s := veleroSchedule{Name: item.GetName(), Created: item.GetCreationTimestamp().Time}
+		s.Spec, _, _ = unstructured.NestedString(item.Object, "spec", "schedule")
+		s.Paused, _, _ = unstructured.NestedBool(item.Object, "spec", "paused")
+		if v, _, _ := unstructured.NestedString(item.Object, "status", "lastBackup"); v != "" {
+			s.LastBackup, _ = time.Parse(time.RFC3339, v)
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, nil
+}
+
+// nextRun срок следующего бэкапа после последнего. Velero считает cron в UTC
+// и дополнительно понимает "@every <длительность>"
+func (s veleroSchedule) nextRun() (time.Time, error) {
+	base := s.LastBackup
+	if base.IsZero() {
+		base = s.Created
+	}
+	if every, ok := strings.CutPrefix(s.Spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(every))
+		if err != nil {
+			return time.Time{}, err
+		}
+		return base.Add(d), nil
+	}
+	c, err := parseCron(s.Spec)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return c.Next(base.UTC()), nil
+}
+
+// BackupWatcher сообщает о неудачных бэкапах и пропущенных расписаниях Velero
+type BackupWatcher struct {
+	cluster *Cluster
+	cfg     VeleroConfig
+	bot     *tgbotapi.BotAPI
+	adminID int64
+
+	since  time.Time            // о неудачах до запуска бота не сообщаем
+	primed bool                 // первый проход выполнен, дальше любой завершившийся бэкап — новый
+	seen   map[string]bool      // завершённые бэкапы, которые уже рассмотрены
+	missed map[string]time.Time // расписание → срок, о пропуске которого уже сообщено
+	broken map[string]bool      // расписания с непонятным выражением, ошибка уже в логе
+}
+
+// NewBackupWatcher создаёт наблюдателя за бэкапами кластера
+func NewBackupWatcher(cluster *Cluster, cfg VeleroConfig, bot *tgbotapi.BotAPI, adminID int64) *BackupWatcher {
+	return &BackupWatcher{
+		cluster: cluster,
+		cfg:     cfg,
+		bot:     bot,
+		adminID: adminID,
+		since:   time.Now().Add(-cfg.PollInterval),
+		seen:    make(map[string]bool),
+		missed:  make(map[string]time.Time),
+		broken:  make(map[string]bool),
+	}
+}
+
+// Start проверяет бэкапы сразу и затем каждые PollInterval
+func (w *BackupWatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		w.check(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check проверяет бэкапы и расписания; без Velero в кластере ничего не делает
+func (w *BackupWatcher) check(ctx context.Context, now time.Time) {
+	if !w.cluster.HasAPI(veleroAPI) {
+		return
+	}
+	lang := langFor(w.adminID)
+
+	backups, err := listBackups(ctx, w.cluster)
+	if err != nil {
+		log.Printf("❌ [%s] Ошибка получения бэкапов Velero: %v", w.cluster.Name, err)
+		return
+	}
+	present := make(map[string]bool, len(backups))
+	for _, b := range backups {
+		present[b.Name] = true
+		if !backupFinished(b.Phase) || w.seen[b.Name] {
+			continue
+		}
+		w.seen[b.Name] = true
+		finished := b.Completed
+		if finished.IsZero() {
+			finished = b.Started
+		}
+		if backupFailed(b.Phase) && (w.primed || finished.After(w.since)) {
+			w.notify("backup_failed", renderBackupFailedAlert(lang, w.cluster.Name, b))
+			log.Printf("🔔 [%s] Бэкап %s завершился с фазой %s", w.cluster.Name, b.Name, b.Phase)
+		}
+	}
+	w.primed = true
+	// Удалённые бэкапы больше не нужно помнить
+	for name := range w.seen {
+		if !present[name] {
+			delete(w.seen, name)
+		}
+	}
+
+	schedules, err := listSchedules(ctx, w.cluster)
+	if err != nil {
+		log.Printf("❌ [%s] Ошибка получения расписаний Velero: %v", w.cluster.Name, err)
+		return
+	}
+	for _, s := range schedules {
+		if s.Paused {
+			continue
+		}
+		due, err := s.nextRun()
+		if err != nil {
+			if !w.broken[s.Name] {
+				w.broken[s.Name] = true
+				log.Printf("⚠️ [%s] Расписание Velero %s (%q) не разобрано: %v", w.cluster.Name, s.Name, s.Spec, err)
+			}
+			continue
+		}
+		if due.IsZero() || now.Before(due.Add(w.cfg.MissedGrace)) || w.missed[s.Name].Equal(due) {
+			continue
+		}
+		w.missed[s.Name] = due
+		w.notify("backup_missed", renderScheduleMissedAlert(lang, w.cluster.Name, s, due, now))
+		log.Printf("🔔 [%s] Расписание Velero %s пропустило бэкап, ожидался %s", w.cluster.Name, s.Name, due.Format(time.RFC3339))
+	}
+}
+
+func (w *BackupWatcher) notify(kind string, r *Rich) {
+	result := "sent"
+	if err := sendRich(w.bot, w.adminID, r); err != nil {
+		result = "failed"
+	}
+	telemetry.Notifications.Inc(kind, result)
+}
+
+// renderBackupFailedAlert уведомление о неудачном бэкапе
+func renderBackupFailedAlert(lang Lang, cluster string, b veleroBackup) *Rich {
+	r := NewRich().
+		Text("🚨 " + alertPrefix(cluster)).Bold(T(lang, "velero.failed.title")).Line().Line().
+		Text("💾 ").Bold(T(lang, "velero.backup")).Text(" ").Code(b.Name).Line().
+		Text("📊 ").Bold(T(lang, "alert.status")).Text(" " + b.Phase).Line()
+	if b.Schedule != "" {
+		r.Text("🗓 ").Bold(T(lang, "velero.schedule")).Text(" " + b.Schedule).Line()
+	}
+	if b.Errors > 0 || b.Warnings > 0 {
+		r.Text(T(lang, "velero.errors", b.Errors, b.Warnings)).Line()
+	}
+	if b.FailureReason != "" {
+		r.Text(T(lang, "velero.reason", b.FailureReason)).Line()
+	}
+	return r.Line().Text(T(lang, "velero.failed.text", b.Name))
+}
+
+// renderScheduleMissedAlert уведомление о пропущенном бэкапе по расписанию
+func renderScheduleMissedAlert(lang Lang, cluster string, s veleroSchedule, due, now time.Time) *Rich {
+	last := T(lang, "velero.never")
+	if !s.LastBackup.IsZero() {
+		last = T(lang, "velero.ago", formatDurationForAlert(lang, now.Sub(s.LastBackup)))
+	}
+	return NewRich().
+		Text("⚠️ " + alertPrefix(cluster)).Bold(T(lang, "velero.missed.title")).Line().Line().
+		Text("🗓 ").Bold(T(lang, "velero.schedule")).Text(" ").Code(s.Name).Text(" (" + s.Spec + ")").Line().
+		Text("⏰ ").Bold(T(lang, "velero.expected")).Text(" " + due.Format("2006-01-02 15:04 MST")).Line().
+		Text("💾 ").Bold(T(lang, "velero.last_backup")).Text(" " + last)
+}
+
+// handleBackups показывает последние бэкапы: /backups [n]
+func handleBackups(bot *tgbotapi.BotAPI, cluster *Cluster, ctx context.Context, chatID int64, args string) {
+	lang := langFor(chatID)
+	n := backupsDefault
+	if args = strings.TrimSpace(args); args != "" {
+		v, err := strconv.Atoi(args)
+		if err != nil || v <= 0 {
+			sendText(bot, chatID, T(lang, "usage.backups"))
+			return
+		}
+		n = min(v, backupsMax)
+	}
+	if !cluster.HasAPI(veleroAPI) {
+		sendText(bot, chatID, T(lang, "velero.not_installed"))
+		return
+	}
+	backups, err := listBackups(ctx, cluster)
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	sendLongRich(bot, chatID, "backups", renderBackups(lang, clusterLabel(cluster.Name), backups, n, time.Now()))
+}
+
+// renderBackups таблица последних n бэкапов
+func renderBackups(lang Lang, cluster string, backups []veleroBackup, n int, now time.Time) *Rich {
+	r := NewRich().Text("💾 " + cluster).Bold(T(lang, "velero.backups_title", min(n, len(backups)), len(backups))).Line()
+	if len(backups) == 0 {
+		return r.Text(T(lang, "velero.no_backups"))
+	}
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSTATUS\tERR/WARN\tAGE\tTOOK")
+	for i, b := range backups {
+		if i == n {
+			break
+		}
+		took := "-"
+		if !b.Completed.IsZero() {
+			took = b.Completed.Sub(b.Started).Round(time.Second).String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d/%d\t%s\t%s\n", b.Name, orDash(b.Phase), b.Errors, b.Warnings, formatDuration(lang, now.Sub(b.Started)), took)
+	}
+	w.Flush()
+	return r.Pre(strings.TrimRight(sb.String(), "\n"))
+}
+
+// handleBackupNow создаёт бэкап всего кластера или одного namespace: /backup now [ns]
+func handleBackupNow(bot *tgbotapi.BotAPI, cluster *Cluster, cfg VeleroConfig, ctx context.Context, chatID int64, ns string) {
+	lang := langFor(chatID)
+	if !cluster.HasAPI(veleroAPI) {
+		sendText(bot, chatID, T(lang, "velero.not_installed"))
+		return
+	}
+	if ns != "" {
+		if _, err := cluster.Typed.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{}); err != nil {
+			sendError(bot, chatID, err)
+			return
+		}
+	}
+	obj := newBackupObject(cfg, ns, time.Now())
+	created, err := cluster.Dynamic.Resource(veleroBackupGVR).Namespace(cfg.Namespace).Create(ctx, obj, metav1.CreateOptions{})
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	scope := ns
+	if scope == "" {
+		scope = T(lang, "velero.all_namespaces")
+	}
+	sendRich(bot, chatID, NewRich().Text("✅ "+T(lang, "velero.backup_created")+" ").Code(created.GetName()).Text(" ("+scope+")").Line().Text(T(lang, "velero.follow")))
+}
+
+// newBackupObject Backup разового бэкапа; пустой ns — все namespace
+func newBackupObject(cfg VeleroConfig, ns string, now time.Time) *unstructured.Unstructured {
+	scope := ns
+	included := []interface{}{ns}
+	if ns == "" {
+		scope = "all"
+		included = []interface{}{"*"}
+	}
+	spec := map[string]interface{}{"includedNamespaces": included}
+	if cfg.BackupTTL != "" {
+		spec["ttl"] = cfg.BackupTTL
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": veleroAPI,
+		"kind":       "Backup",
+		"metadata": map[string]interface{}{
+			"name":      veleroObjectName("gobot-"+scope, now),
+			"namespace": cfg.Namespace,
+			"labels":    map[string]interface{}{"app.kubernetes.io/created-by": "go-bot"},
+		},
+		"spec": spec,
+	}}
+}
+
+// handleRestore запрашивает подтверждение у автора команды и создаёт Restore: /restore <backup> [ns]
+func handleRestore(bot *tgbotapi.BotAPI, cluster *Cluster, cfg VeleroConfig, ctx context.Context, chatID int64, user *tgbotapi.User, backupName, ns string) {
+	lang := langFor(chatID)
+	if !cluster.HasAPI(veleroAPI) {
+		sendText(bot, chatID, T(lang, "velero.not_installed"))
+		return
+	}
+	obj, err := cluster.Dynamic.Resource(veleroBackupGVR).Namespace(cfg.Namespace).Get(ctx, backupName, metav1.GetOptions{})
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	b := parseVeleroBackup(*obj)
+	if b.Phase != "Completed" && b.Phase != "PartiallyFailed" {
+		sendText(bot, chatID, T(lang, "velero.restore_not_ready", b.Name, orDash(b.Phase)))
+		return
+	}
+	if ns != "" && len(b.Namespaces) > 0 && b.Namespaces[0] != "*" && !slices.Contains(b.Namespaces, ns) {
+		sendText(bot, chatID, T(lang, "velero.restore_ns_missing", b.Name, ns, strings.Join(b.Namespaces, ", ")))
+		return
+	}
+
+	scope := ns
+	if scope == "" {
+		scope = T(lang, "velero.all_from_backup")
+	}
+	prompt := NewRich().
+		Text("♻️ ").Bold(T(lang, "velero.restore_confirm")).Line().Line().
+		Text("💾 ").Bold(T(lang, "velero.backup")).Text(" ").Code(b.Name).Text(" (" + T(lang, "velero.ago", formatDuration(lang, time.Since(b.Started))) + ")").Line().
+		Text("📦 ").Bold(T(lang, "velero.namespaces")).Text(" " + scope).Line().
+		Text(T(lang, "velero.restore_note"))
+	label := clusterLabel(cluster.Name)
+	askUserConfirmation(bot, chatID, user.ID, prompt, func(ctx context.Context) (*Rich, error) {
+		restore := newRestoreObject(cfg, b.Name, ns, time.Now())
+		created, err := cluster.Dynamic.Resource(veleroRestoreGVR).Namespace(cfg.Namespace).Create(ctx, restore, metav1.CreateOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return NewRich().Text("✅ " + label + T(lang, "velero.restore_created") + " ").Code(created.GetName()).Line().Text(T(lang, "velero.restore_follow", created.GetName(), cfg.Namespace)), nil
+	})
+}
+
+// newRestoreObject Restore из бэкапа; пустой ns — все namespace бэкапа
+func newRestoreObject(cfg VeleroConfig, backupName, ns string, now time.Time) *unstructured.Unstructured {
+	spec := map[string]interface{}{"backupName": backupName}
+	if ns != "" {
+		spec["includedNamespaces"] = []interface{}{ns}
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": veleroAPI,
+		"kind":       "Restore",
+		"metadata": map[string]interface{}{
+			"name":      veleroObjectName(backupName, now),
+			"namespace": cfg.Namespace,
+			"labels":    map[string]interface{}{"app.kubernetes.io/created-by": "go-bot"},
+		},
+		"spec": spec,
+	}}
+}
+
+// veleroObjectName имя с меткой времени, укороченное до 63 символов (лимит меток Velero)
+func veleroObjectName(prefix string, now time.Time) string {
+	suffix := "-" + now.UTC().Format("20060102-150405")
+	if len(prefix)+len(suffix) > 63 {
+		prefix = strings.TrimRight(prefix[:63-len(suffix)], "-.")
+	}
+	return prefix + suffix
+}
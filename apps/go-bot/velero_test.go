@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func backupObject(name, phase string, created, completed time.Time) *unstructured.Unstructured {
+	status := map[string]interface{}{"phase": phase}
+	if !completed.IsZero() {
+		status["completionTimestamp"] = completed.UTC().Format(time.RFC3339)
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": veleroAPI,
+		"kind":       "Backup",
+		"metadata": map[string]interface{}{
+			"name":              name,
+			"namespace":         "velero",
+			"creationTimestamp": created.UTC().Format(time.RFC3339),
+		},
+		"spec":   map[string]interface{}{"includedNamespaces": []interface{}{"*"}},
+		"status": status,
+	}}
+}
+
+func scheduleObject(name, spec string, paused bool, created, lastBackup time.Time) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": veleroAPI,
+		"kind":       "Schedule",
+		"metadata": map[string]interface{}{
+			"name":              name,
+			"namespace":         "velero",
+			"creationTimestamp": created.UTC().Format(time.RFC3339),
+		},
+		"spec": map[string]interface{}{"schedule": spec, "paused": paused},
+	}}
+	if !lastBackup.IsZero() {
+		obj.Object["status"] = map[string]interface{}{"lastBackup": lastBackup.UTC().Format(time.RFC3339)}
+	}
+	return obj
+}
+
+// sentSince тексты, отправленные поддельному Telegram после from-го
+func sentSince(ft *fakeTelegram, from int) []string {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	return append([]string(nil), ft.texts[from:]...)
+}
+
+func TestBackupWatcherFailedBackups(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	cluster := newFakeCluster(t, []string{veleroAPI}, nil,
+		backupObject("old-failed", "Failed", now.Add(-25*time.Hour), now.Add(-24*time.Hour)),
+		backupObject("fresh-failed", "PartiallyFailed", now.Add(-3*time.Minute), now.Add(-time.Minute)),
+		backupObject("running", "InProgress", now.Add(-2*time.Hour), time.Time{}),
+		backupObject("ok", "Completed", now.Add(-time.Hour), now.Add(-50*time.Minute)),
+	)
+	bot, ft := newFakeTelegram(t, nil)
+	w := NewBackupWatcher(cluster, DefaultVeleroConfig(), bot, 42)
+	w.since = now.Add(-5 * time.Minute)
+	ctx := context.Background()
+	backups := cluster.Dynamic.Resource(veleroBackupGVR).Namespace("velero")
+
+	// Первый проход: неудачи до запуска бота пропускаются, свежая — сообщается
+	w.check(ctx, now)
+	sent := sentSince(ft, 0)
+	if len(sent) != 1 || !strings.Contains(sent[0], "fresh-failed") {
+		t.Fatalf("первый проход: %q", sent)
+	}
+	if !w.primed || !w.seen["old-failed"] || !w.seen["ok"] || w.seen["running"] {
+		t.Errorf("состояние после первого прохода: primed=%v seen=%v", w.primed, w.seen)
+	}
+
+	// Повторный проход о тех же бэкапах не сообщает
+	w.check(ctx, now.Add(5*time.Minute))
+	if sent := sentSince(ft, 1); len(sent) != 0 {
+		t.Fatalf("повторный проход: %q", sent)
+	}
+
+	// После первого прохода сообщается любой завершившийся с ошибкой бэкап,
+	// даже если его completionTimestamp раньше запуска бота
+	if _, err := backups.Update(ctx, backupObject("running", "Failed", now.Add(-2*time.Hour), now.Add(-90*time.Minute)), metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	w.check(ctx, now.Add(10*time.Minute))
+	if sent := sentSince(ft, 1); len(sent) != 1 || !strings.Contains(sent[0], "running") {
+		t.Fatalf("бэкап, упавший после запуска: %q", sent)
+	}
+
+	// Удалённые бэкапы забываются
+	if err := backups.Delete(ctx, "old-failed", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	w.check(ctx, now.Add(15*time.Minute))
+	if w.seen["old-failed"] {
+		t.Error("удалённый бэкап остался в seen")
+	}
+	if sent := sentSince(ft, 2); len(sent) != 0 {
+		t.Errorf("лишние уведомления: %q", sent)
+	}
+}
+
+func TestBackupWatcherMissedSchedules(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-30 * 24 * time.Hour)
+	cluster := newFakeCluster(t, []string{veleroAPI}, nil,
+		scheduleObject("daily-missed", "0 3 * * *", false, created, now.Add(-57*time.Hour)),
+		scheduleObject("daily-ok", "0 3 * * *", false, created, now.Add(-9*time.Hour)),
+		scheduleObject("paused", "0 3 * * *", true, created, now.Add(-100*time.Hour)),
+		scheduleObject("hourly", "@every 1h", false, created, now.Add(-30*time.Minute)),
+		scheduleObject("never", "@every 6h", false, now.Add(-8*time.Hour), time.Time{}),
+		scheduleObject("broken", "not a cron", false, created, time.Time{}),
+	)
+	bot, ft := newFakeTelegram(t, nil)
+	w := NewBackupWatcher(cluster, DefaultVeleroConfig(), bot, 42)
+	ctx := context.Background()
+
+	w.check(ctx, now)
+	sent := sentSince(ft, 0)
+	if len(sent) != 2 || !strings.Contains(sent[0]+sent[1], "daily-missed") || !strings.Contains(sent[0]+sent[1], "never") {
+		t.Fatalf("пропущенные расписания: %q", sent)
+	}
+	if !w.broken["broken"] {
+		t.Error("непонятное расписание не отмечено")
+	}
+	if due := w.missed["daily-missed"]; !due.Equal(time.Date(2026, 3, 9, 3, 0, 0, 0, time.UTC)) {
+		t.Errorf("срок daily-missed %v", due)
+	}
+
+	// О том же сроке второй раз не сообщается
+	w.check(ctx, now.Add(time.Hour))
+	if sent := sentSince(ft, 2); len(sent) != 0 {
+		t.Fatalf("повтор: %q", sent)
+	}
+
+	// hourly пропускает срок только после MissedGrace
+	w.check(ctx, now.Add(90*time.Minute))
+	if sent := sentSince(ft, 2); len(sent) != 1 || !strings.Contains(sent[0], "hourly") {
+		t.Fatalf("hourly после grace: %q", sent)
+	}
+}
+
+func TestBackupWatcherWithoutVelero(t *testing.T) {
+	cluster := newFakeCluster(t, nil, nil, backupObject("x", "Failed", time.Now(), time.Now()))
+	bot, ft := newFakeTelegram(t, nil)
+	w := NewBackupWatcher(cluster, DefaultVeleroConfig(), bot, 42)
+	w.check(context.Background(), time.Now())
+	if ft.calls != 0 || w.primed {
+		t.Errorf("без Velero наблюдатель не должен ничего делать: calls=%d primed=%v", ft.calls, w.primed)
+	}
+}
+
+func TestVeleroScheduleNextRun(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*3600)
+	created := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name    string
+		s       veleroSchedule
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name: "cron after last backup",
+			s:    veleroSchedule{Spec: "0 3 * * *", LastBackup: time.Date(2026, 3, 10, 3, 0, 5, 0, time.UTC), Created: created},
+			want: time.Date(2026, 3, 11, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			// Velero считает cron в UTC, зона последнего бэкапа не важна
+			name: "cron in UTC",
+			s:    veleroSchedule{Spec: "0 3 * * *", LastBackup: time.Date(2026, 3, 10, 6, 0, 5, 0, msk), Created: created},
+			want: time.Date(2026, 3, 11, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "never ran counts from creation",
+			s:    veleroSchedule{Spec: "30 */6 * * *", Created: created},
+			want: time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "every",
+			s:    veleroSchedule{Spec: "@every 90m", LastBackup: created},
+			want: created.Add(90 * time.Minute),
+		},
+		{name: "bad every", s: veleroSchedule{Spec: "@every often", Created: created}, wantErr: true},
+		{name: "bad cron", s: veleroSchedule{Spec: "0 3 * *", Created: created}, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.s.nextRun()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ошибка %v", err)
+			}
+			if !tc.wantErr && !got.Equal(tc.want) {
+				t.Errorf("nextRun = %v, ожидалось %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRestoreConfirmationOnlyByAuthor(t *testing.T) {
+	now := time.Now()
+	cluster := newFakeCluster(t, []string{veleroAPI}, nil, backupObject("nightly", "Completed", now.Add(-time.Hour), now.Add(-50*time.Minute)))
+	bot, ft := newFakeTelegram(t, nil)
+	ctx := context.Background()
+	author := &tgbotapi.User{ID: 100}
+
+	handleRestore(bot, cluster, DefaultVeleroConfig(), ctx, 42, author, "nightly", "")
+	id := pendingActionFor(t, 42)
+
+	handleConfirm(bot, ctx, 42, 200, 7, id, true)
+	if sent := sentSince(ft, 1); len(sent) != 1 || sent[0] != T(langFor(42), "confirm.not_yours") {
+		t.Fatalf("чужое подтверждение: %q", sent)
+	}
+	restores := cluster.Dynamic.Resource(veleroRestoreGVR).Namespace("velero")
+	if list, _ := restores.List(ctx, metav1.ListOptions{}); len(list.Items) != 0 {
+		t.Fatal("Restore создан по чужому подтверждению")
+	}
+
+	handleConfirm(bot, ctx, 42, author.ID, 7, id, true)
+	list, err := restores.List(ctx, metav1.ListOptions{})
+	if err != nil || len(list.Items) != 1 {
+		t.Fatalf("Restore после подтверждения автором: %v %v", list, err)
+	}
+	if backup, _, _ := unstructured.NestedString(list.Items[0].Object, "spec", "backupName"); backup != "nightly" {
+		t.Errorf("backupName = %q", backup)
+	}
+}
+
+// pendingActionFor идентификатор последнего ожидающего подтверждения действия чата
+func pendingActionFor(t *testing.T, chatID int64) string {
+	t.Helper()
+	pendingActions.Lock()
+	defer pendingActions.Unlock()
+	var id string
+	var created time.Time
+	for k, v := range pendingActions.items {
+		if v.ChatID == chatID && !v.Created.Before(created) {
+			id, created = k, v.Created
+		}
+	}
+	if id == "" {
+		t.Fatal("нет действия, ожидающего подтверждения")
+	}
+	return id
+}
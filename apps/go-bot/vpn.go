@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	kiloAPI = "kilo.squat.ai/v1alpha1"
+	// Аннотации, которые Kilo ставит на узлы mesh-сети
+	kiloKeyAnnotation           = "kilo.squat.ai/key"
+	kiloEndpointAnnotation      = "kilo.squat.ai/endpoint"
+	kiloForceEndpointAnnotation = "kilo.squat.ai/force-endpoint"
+	kiloWireGuardIPAnnotation   = "kilo.squat.ai/wireguard-ip"
+	kiloInternalIPAnnotation    = "kilo.squat.ai/internal-ip"
+	// vpnOwnerAnnotation кто выдал доступ через бота
+	vpnOwnerAnnotation = "go-bot/created-by"
+	// wireGuardPort порт по умолчанию, если Kilo не указал его в endpoint
+	wireGuardPort = "51820"
+)
+
+var kiloPeerGVR = schema.GroupVersionResource{Group: "kilo.squat.ai", Version: "v1alpha1", Resource: "peers"}
+
+// errVPNRangeFull в диапазоне клиентов не осталось свободных адресов
+var errVPNRangeFull = errors.New("нет свободных адресов")
+
+// vpnPeer Peer Kilo — клиент WireGuard
+type vpnPeer struct {
+	Name       string
+	PublicKey  string
+	AllowedIPs []string
+	Keepalive  int64
+	Owner      string
+	Created    time.Time
+}
+
+// wgServer узел Kilo, к которому подключается клиент
+type wgServer struct {
+	Node       string
+	PublicKey  string
+	Endpoint   string
+	AllowedIPs []string
+}
+
+// listPeers Peer-ы Kilo по имени
+func listPeers(ctx context.Context, cluster *Cluster) ([]vpnPeer, error) {
+	list, err := cluster.Dynamic.Resource(kiloPeerGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	peers := make([]vpnPeer, 0, len(list.Items))
+	for _, item := range list.Items {
+		p := vpnPeer{Name: item.GetName(), Owner: item.GetAnnotations()[vpnOwnerAnnotation], Created: item.GetCreationTimestamp().Time}
+		p.PublicKey, _, _ = unstructured.NestedString(item.Object, "spec", "publicKey")
+		p.AllowedIPs, _, _ = unstructured.NestedStringSlice(item.Object, "spec", "allowedIPs")
+		p.Keepalive, _, _ = unstructured.NestedInt64(item.Object, "spec", "persistentKeepalive")
+		peers = append(peers, p)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].Name < peers[j].Name })
+	return peers, nil
+}
+
+// nextPeerAddress первый свободный адрес диапазона без адреса сети и широковещательного
+func nextPeerAddress(rng netip.Prefix, peers []vpnPeer) (netip.Addr, error) {
+	used := make(map[netip.Addr]bool)
+	for _, p := range peers {
+		for _, ip := range p.AllowedIPs {
+			if prefix, err := netip.ParsePrefix(ip); err == nil && rng.Contains(prefix.Addr()) {
+				used[prefix.Addr()] = true
+			}
+		}
+	}
+	for a := rng.Addr().Next(); rng.Contains(a.Next()); a = a.Next() {
+		if !used[a] {
+			return a, nil
+		}
+	}
+	return netip.Addr{}, errVPNRangeFull
+}
+
+// generateWireGuardKey пара ключей X25519 в формате wg genkey / wg pubkey
+func generateWireGuardKey() (private, public string, err error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(key.Bytes()), base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+}
+
+// kiloServers узлы с ключом Kilo; сети каждого узла — его WireGuard-адрес, внутренний IP и подсеть pod-ов
+func kiloServers(ctx context.Context, cluster *Cluster) ([]wgServer, error) {
+	nodes, err := cluster.Typed.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var servers []wgServer
+	for _, n := range nodes.Items {
+		a := n.Annotations
+		if a[kiloKeyAnnotation] == "" {
+			continue
+		}
+		s := wgServer{Node: n.Name, PublicKey: a[kiloKeyAnnotation], Endpoint: a[kiloForceEndpointAnnotation]}
+		if s.Endpoint == "" {
+			s.Endpoint = a[kiloEndpointAnnotation]
+		}
+		if s.Endpoint != "" && !strings.Contains(strings.TrimPrefix(s.Endpoint, "["), ":") {
+			s.Endpoint += ":" + wireGuardPort
+		}
+		for _, ip := range []string{a[kiloWireGuardIPAnnotation], a[kiloInternalIPAnnotation]} {
+			if prefix, err := netip.ParsePrefix(ip); err == nil {
+				s.AllowedIPs = append(s.AllowedIPs, netip.PrefixFrom(prefix.Addr(), prefix.Addr().BitLen()).String())
+			}
+		}
+		if n.Spec.PodCIDR != "" {
+			s.AllowedIPs = append(s.AllowedIPs, n.Spec.PodCIDR)
+		}
+		// Узел без адресов ещё не вошёл в mesh
+		if len(s.AllowedIPs) == 0 {
+			continue
+		}
+		servers = append(servers, s)
+	}
+	sort.Slice(servers, func(i, j int) bool { return servers[i].Node < servers[j].Node })
+	return servers, nil
+}
+
+// renderWireGuardConfig конфиг клиента; дополнительные сети из настроек маршрутизируются через первый узел
+func renderWireGuardConfig(cfg VPNConfig, privateKey string, addr netip.Addr, servers []wgServer) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[Interface]\nPrivateKey = %s\nAddress = %s\n", privateKey, netip.PrefixFrom(addr, addr.BitLen()))
+	if cfg.DNS != "" {
+		fmt.Fprintf(&sb, "DNS = %s\n", cfg.DNS)
+	}
+	for i, s := range servers {
+		allowed := s.AllowedIPs
+		if i == 0 {
+			allowed = append(append([]string{}, allowed...), cfg.AllowedIPs...)
+		}
+		fmt.Fprintf(&sb, "\n[Peer]\n# %s\nPublicKey = %s\n", s.Node, s.PublicKey)
+		if s.Endpoint != "" {
+			fmt.Fprintf(&sb, "Endpoint = %s\n", s.Endpoint)
+		}
+		fmt.Fprintf(&sb, "AllowedIPs = %s\n", strings.Join(allowed, ", "))
+		if cfg.Keepalive > 0 {
+			fmt.Fprintf(&sb, "PersistentKeepalive = %d\n", cfg.Keepalive)
+		}
+	}
+	return sb.String()
+}
+
+// newPeerObject Peer Kilo в формате kuber/kilo/kilo-peer-client.yaml
+func newPeerObject(cfg VPNConfig, name, publicKey string, addr netip.Addr, owner string) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"publicKey":  publicKey,
+		"allowedIPs": []interface{}{netip.PrefixFrom(addr, addr.BitLen()).String()},
+	}
+	if cfg.Keepalive > 0 {
+		spec["persistentKeepalive"] = int64(cfg.Keepalive)
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": kiloAPI,
+		"kind":       "Peer",
+		"metadata": map[string]interface{}{
+			"name":        name,
+			"labels":      map[string]interface{}{"app.kubernetes.io/managed-by": "go-bot"},
+			"annotations": map[string]interface{}{vpnOwnerAnnotation: owner},
+		},
+		"spec": spec,
+	}}
+}
+
+// handleVPN разбирает /vpn peers | add <имя> | remove <имя>
+func handleVPN(bot *tgbotapi.BotAPI, cluster *Cluster, cfg VPNConfig, ctx context.Context, chatID int64, user *tgbotapi.User, args string) {
+	lang := langFor(chatID)
+	parts := strings.Fields(args)
+	if len(parts) == 0 {
+		parts = []string{"peers"}
+	}
+	switch {
+	case parts[0] == "peers" && len(parts) == 1:
+	case (parts[0] == "add" || parts[0] == "remove") && len(parts) == 2:
+	default:
+		sendText(bot, chatID, T(lang, "usage.vpn"))
+		return
+	}
+	if !cluster.HasAPI(kiloAPI) {
+		sendText(bot, chatID, T(lang, "vpn.not_installed"))
+		return
+	}
+	switch parts[0] {
+	case "peers":
+		peers, err := listPeers(ctx, cluster)
+		if err != nil {
+			sendError(bot, chatID, err)
+			return
+		}
+		sendLongRich(bot, chatID, "vpn-peers", renderVPNPeers(lang, clusterLabel(cluster.Name), peers, time.Now()))
+	case "add":
+		handleVPNAdd(bot, cluster, cfg, ctx, chatID, user, parts[1])
+	case "remove":
+		handleVPNRemove(bot, cluster, ctx, chatID, user, parts[1])
+	}
+}
+
+// renderVPNPeers таблица Peer-ов с адресами и тем, кто их выдал
+func renderVPNPeers(lang Lang, cluster string, peers []vpnPeer, now time.Time) *Rich {
+	r := NewRich().Text("🔐 " + cluster).Bold(T(lang, "vpn.peers_title", len(peers))).Line()
+	if len(peers) == 0 {
+		return r.Text(T(lang, "vpn.no_peers"))
+	}
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(w, "NAME\tADDRESS\tKEY\tOWNER\tAGE")
+	for _, p := range peers {
+		key := p.PublicKey
+		if len(key) > 8 {
+			key = key[:8] + "…"
+		}
+		age := "-"
+		if !p.Created.IsZero() {
+			age = formatDuration(lang, now.Sub(p.Created))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.Name, orDash(strings.Join(p.AllowedIPs, ",")), orDash(key), orDash(p.Owner), age)
+	}
+	w.Flush()
+	return r.Pre(strings.TrimRight(sb.String(), "\n"))
+}
+
+// handleVPNAdd создаёт Peer со свежими ключами и присылает конфиг с QR-кодом в личный чат.
+// Приватный ключ нигде не сохраняется: если отправить конфиг не удалось, Peer удаляется
+func handleVPNAdd(bot *tgbotapi.BotAPI, cluster *Cluster, cfg VPNConfig, ctx context.Context, chatID int64, user *tgbotapi.User, name string) {
+	lang := langFor(chatID)
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		sendText(bot, chatID, T(lang, "vpn.invalid_name", name, strings.Join(errs, "; ")))
+		return
+	}
+	peers, err := listPeers(ctx, cluster)
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	for _, p := range peers {
+		if p.Name == name {
+			sendText(bot, chatID, T(lang, "vpn.exists", name))
+			return
+		}
+	}
+	addr, err := nextPeerAddress(cfg.Range, peers)
+	if err != nil {
+		sendText(bot, chatID, T(lang, "vpn.range_full", cfg.Range))
+		return
+	}
+	servers, err := kiloServers(ctx, cluster)
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	if len(servers) == 0 {
+		sendText(bot, chatID, T(lang, "vpn.no_nodes", kiloKeyAnnotation))
+		return
+	}
+	privateKey, publicKey, err := generateWireGuardKey()
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	conf := renderWireGuardConfig(cfg, privateKey, addr, servers)
+	code, err := qrEncode([]byte(conf))
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	img, err := code.PNG(8, 4)
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+
+	owner := userLabel(user)
+	_, err = cluster.Dynamic.Resource(kiloPeerGVR).Create(ctx, newPeerObject(cfg, name, publicKey, addr, owner), metav1.CreateOptions{})
+	auditLog.Record(owner, cluster.Name, "vpn.add", name+" "+addr.String(), err)
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+
+	// Личный чат с пользователем совпадает с его id
+	private := chatID
+	if user != nil {
+		private = user.ID
+	}
+	privateLang := langFor(private)
+	doc := tgbotapi.NewDocument(private, tgbotapi.FileBytes{Name: name + ".conf", Bytes: []byte(conf)})
+	doc.Caption = T(privateLang, "vpn.config_caption", name, addr)
+	if _, err := bot.Send(doc); err != nil {
+		delErr := cluster.Dynamic.Resource(kiloPeerGVR).Delete(ctx, name, metav1.DeleteOptions{})
+		auditLog.Record(owner, cluster.Name, "vpn.rollback", name, delErr)
+		sendText(bot, chatID, T(lang, "vpn.private_failed", err))
+		telemetry.userErrors.Add(1)
+		return
+	}
+	photo := tgbotapi.NewPhoto(private, tgbotapi.FileBytes{Name: name + ".png", Bytes: img})
+	photo.Caption = T(privateLang, "vpn.qr_caption")
+	if _, err := bot.Send(photo); err != nil {
+		sendError(bot, private, err)
+	}
+
+	r := NewRich().Text("✅ " + clusterLabel(cluster.Name) + T(lang, "vpn.added") + " ").Code(name).Text(", " + addr.String())
+	if private != chatID {
+		r.Line().Text(T(lang, "vpn.sent_private"))
+	}
+	sendRich(bot, chatID, r)
+}
+
+// handleVPNRemove удаляет Peer после подтверждения автором команды
+func handleVPNRemove(bot *tgbotapi.BotAPI, cluster *Cluster, ctx context.Context, chatID int64, user *tgbotapi.User, name string) {
+	lang := langFor(chatID)
+	obj, err := cluster.Dynamic.Resource(kiloPeerGVR).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		sendError(bot, chatID, err)
+		return
+	}
+	allowed, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "allowedIPs")
+	prompt := NewRich().
+		Text("🔐 ").Bold(T(lang, "vpn.remove_confirm")).Line().Line().
+		Text("👤 ").Bold(T(lang, "vpn.peer")).Text(" ").Code(name).Line().
+		Text("🌐 ").Bold(T(lang, "vpn.address")).Text(" " + orDash(strings.Join(allowed, ", "))).Line().
+		Text("✍️ ").Bold(T(lang, "vpn.owner")).Text(" " + orDash(obj.GetAnnotations()[vpnOwnerAnnotation]))
+	owner, label := userLabel(user), clusterLabel(cluster.Name)
+	askUserConfirmation(bot, chatID, user.ID, prompt, func(ctx context.Context) (*Rich, error) {
+		err := cluster.Dynamic.Resource(kiloPeerGVR).Delete(ctx, name, metav1.DeleteOptions{})
+		auditLog.Record(owner, cluster.Name, "vpn.remove", name, err)
+		if err != nil {
+			return nil, err
+		}
+		return NewRich().Text("✅ " + label + T(lang, "vpn.removed") + " ").Code(name), nil
+	})
+}